@@ -2,18 +2,33 @@ package app
 
 import (
 	"auth/internal/config"
+	admincontroller "auth/internal/controllers/admin-controller"
 	authcontroller "auth/internal/controllers/auth-controller"
+	httputils "auth/internal/controllers/http-utils"
+	magiclinkcontroller "auth/internal/controllers/magic-link-controller"
+	mfacontroller "auth/internal/controllers/mfa-controller"
+	notificationcontroller "auth/internal/controllers/notification-controller"
+	revocationcontroller "auth/internal/controllers/revocation-controller"
+	webauthncontroller "auth/internal/controllers/webauthn-controller"
 	"auth/internal/db/postgres"
 	authservice "auth/internal/domain/services/auth-service"
 	emailservice "auth/internal/domain/services/email-service"
+	ipaccessservice "auth/internal/domain/services/ip-access-service"
+	magiclinkservice "auth/internal/domain/services/magic-link-service"
+	mfaservice "auth/internal/domain/services/mfa-service"
+	notificationservice "auth/internal/domain/services/notification-service"
+	templateservice "auth/internal/domain/services/template-service"
+	webauthnservice "auth/internal/domain/services/webauthn-service"
 	"auth/internal/repositories"
 	slogutils "auth/internal/utils/slog-utils"
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -57,17 +72,135 @@ func Run(cfg *config.Config) error {
 		return errors.Wrap(err, "failed to init repository")
 	}
 
+	pubSub := postgres.NewPubSub(cfg.DBConfig, db)
+
 	refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+	auditEventRepository := repositories.NewAuditEventRepository(db)
+	ipAccessRuleRepository := repositories.NewIPAccessRuleRepository(db)
+	tokenWatermarkCache, err := repositories.NewTokenWatermarkCache(
+		repositories.NewTokenWatermarkRepository(db), pubSub)
+	if err != nil {
+		return errors.Wrap(err, "create token watermarks cache")
+	}
+	revokedTokenCache, err := repositories.NewRevokedTokenCache(
+		repositories.NewRevokedTokenRepository(db), pubSub)
+	if err != nil {
+		return errors.Wrap(err, "create revoked tokens cache")
+	}
 	userRepository := repositories.NewUserRepositoryMock()
 
 	emailService := emailservice.NewEmailService(
 		cfg.Emails, cfg.SMTPServer, userRepository)
+	ipAccessService, err := ipaccessservice.NewIPAccessService(
+		cfg.IPAccess, ipAccessRuleRepository)
+	if err != nil {
+		return errors.Wrap(err, "create ip access service")
+	}
+	err = subscribeToConfigReloads(pubSub, ipAccessService)
+	if err != nil {
+		return errors.Wrap(err, "subscribe to config reloads")
+	}
+	var ipLocator templateservice.IPLocator
+	if cfg.GeoIP.DatabasePath != "" {
+		ipLocator, err = repositories.NewGeoIPRepository(cfg.GeoIP.DatabasePath)
+		if err != nil {
+			return errors.Wrap(err, "create geoip repository")
+		}
+	}
+	templateService, err := templateservice.NewTemplateService(
+		cfg.NotificationTemplates, userRepository, ipLocator)
+	if err != nil {
+		return errors.Wrap(err, "create notification template service")
+	}
+	notificationOutboxRepository := repositories.NewNotificationOutboxRepository(db)
+	inAppNotificationRepository := repositories.NewInAppNotificationRepository(db)
+	emailChannel := notificationservice.NewEmailChannel(templateService, emailService)
+	notificationChannels := []notificationservice.Channel{
+		emailChannel,
+		notificationservice.NewInAppChannel(templateService, inAppNotificationRepository),
+	}
+	if cfg.NotificationWebhook.URL != "" {
+		notificationChannels = append(notificationChannels,
+			notificationservice.NewWebhookChannel(cfg.NotificationWebhook))
+	}
+	if cfg.SMSProvider.URL != "" {
+		notificationChannels = append(notificationChannels,
+			notificationservice.NewSMSChannel(cfg.SMSProvider, templateService, userRepository))
+	}
+	notificationService, err := notificationservice.NewNotificationService(
+		cfg.Notifications, notificationOutboxRepository, inAppNotificationRepository,
+		authservice.NewRevokeLinkSigner(cfg.Auth.RevokeLinkSecret), notificationChannels...)
+	if err != nil {
+		return errors.Wrap(err, "create notification service")
+	}
 	authService := authservice.NewAuthService(
-		refreshTokenRepository, emailService,
-		[]byte(cfg.Auth.JWTPrivateKey),
-		cfg.Auth.AccessTokenDuration, cfg.Auth.RefreshTokenDuration)
+		cfg.Auth,
+		refreshTokenRepository, auditEventRepository,
+		tokenWatermarkCache, revokedTokenCache,
+		notificationOutboxRepository, notificationService,
+		repositories.NewUsedRevokeLinkRepository(db),
+		repositories.NewRefreshChallengeRepository(db), emailChannel,
+		repositories.NewPendingStepUpRepository(db),
+		ipAccessService)
 
-	authController := authcontroller.NewAuthController(authService)
+	trustedProxies, err := httputils.ParseTrustedProxies(
+		cfg.HTTPServer.TrustedProxies, cfg.HTTPServer.TrustedProxyHeader)
+	if err != nil {
+		return errors.Wrap(err, "parse trusted proxies")
+	}
+	authController := authcontroller.NewAuthController(authService, trustedProxies)
+	var adminController *admincontroller.AdminController
+	if cfg.Admin.APIToken != "" {
+		adminController = admincontroller.NewAdminController(
+			cfg.Admin.APIToken, ipAccessService, pubSub, notificationService)
+	}
+	revocationController := revocationcontroller.NewRevocationController(authService)
+	err = subscribeToRevocations(pubSub, revocationController)
+	if err != nil {
+		return errors.Wrap(err, "subscribe to revocations")
+	}
+	notificationController := notificationcontroller.NewNotificationController(
+		authService, notificationService)
+	var mfaController *mfacontroller.MFAController
+	if cfg.MFA.TOTPSecretKey != "" {
+		mfaService, err := mfaservice.NewMFAService(
+			cfg.MFA,
+			repositories.NewTOTPCredentialRepository(db),
+			repositories.NewRecoveryCodeRepository(db),
+			userRepository, authService)
+		if err != nil {
+			return errors.Wrap(err, "create mfa service")
+		}
+		mfaController = mfacontroller.NewMFAController(authService, mfaService)
+	}
+	var webAuthnController *webauthncontroller.WebAuthnController
+	if cfg.WebAuthn.RPID != "" {
+		webAuthnService, err := webauthnservice.NewWebAuthnService(
+			cfg.WebAuthn,
+			repositories.NewWebAuthnCredentialRepository(db),
+			repositories.NewWebAuthnCeremonyRepository(db),
+			userRepository, authService)
+		if err != nil {
+			return errors.Wrap(err, "create webauthn service")
+		}
+		webAuthnController = webauthncontroller.NewWebAuthnController(
+			authService, webAuthnService, trustedProxies)
+	}
+	var magicLinkController *magiclinkcontroller.MagicLinkController
+	if cfg.MagicLink.Enabled {
+		if cfg.NotificationTemplates.MagicLinkURL == "" {
+			return errors.New("magic link url is empty")
+		}
+		magicLinkService := magiclinkservice.NewMagicLinkService(
+			cfg.MagicLink,
+			repositories.NewMagicLinkRepository(db),
+			userRepository, emailChannel, authService)
+		magicLinkController, err = magiclinkcontroller.NewMagicLinkController(
+			cfg.MagicLink, magicLinkService, trustedProxies)
+		if err != nil {
+			return errors.Wrap(err, "create magic link controller")
+		}
+	}
 
 	switch cfg.Env {
 	case config.EnvLocal:
@@ -86,12 +219,45 @@ func Run(cfg *config.Config) error {
 		setLoggerMiddleware())
 	engine.GET(swaggerSpecURLPath+"/*any", ginswagger.WrapHandler(swaggerfiles.Handler))
 	authController.RegisterRoutes(engine)
+	if adminController != nil {
+		adminController.RegisterRoutes(engine)
+	}
+	revocationController.RegisterRoutes(engine)
+	notificationController.RegisterRoutes(engine)
+	if mfaController != nil {
+		mfaController.RegisterRoutes(engine)
+	}
+	if webAuthnController != nil {
+		webAuthnController.RegisterRoutes(engine)
+	}
+	if magicLinkController != nil {
+		magicLinkController.RegisterRoutes(engine)
+	}
 
+	listener, err := newListener(cfg.HTTPServer)
+	if err != nil {
+		return errors.Wrap(err, "create listener")
+	}
 	srv := &http.Server{
 		Addr:    cfg.HTTPServer.Host + ":" + cfg.HTTPServer.Port,
 		Handler: engine.Handler(),
 	}
-	err = runServer(srv)
+	var grpcServers []grpcServer
+	if cfg.GRPCServer.Enabled {
+		sessionServer, err := newSessionServer(cfg.GRPCServer, authService, trustedProxies)
+		if err != nil {
+			return errors.Wrap(err, "create session grpc server")
+		}
+		grpcServers = append(grpcServers, sessionServer)
+	}
+	if cfg.ExtAuthz.Enabled {
+		extAuthzServer, err := newExtAuthzServer(cfg.ExtAuthz, authService)
+		if err != nil {
+			return errors.Wrap(err, "create ext authz server")
+		}
+		grpcServers = append(grpcServers, extAuthzServer)
+	}
+	err = runServer(srv, listener, grpcServers...)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slogutils.Error("run server", err)
 	}
@@ -99,22 +265,40 @@ func Run(cfg *config.Config) error {
 	return err
 }
 
-func runServer(srv *http.Server) error {
+func runServer(srv *http.Server, listener net.Listener, grpcServers ...grpcServer) error {
 	slog.Info("server is starting", "address", srv.Addr)
 	defer slog.Info("server exited")
 
 	systemSignalQuit := make(chan os.Signal, 1)
 	signal.Notify(systemSignalQuit, syscall.SIGINT, syscall.SIGTERM)
 
-	serverExited := make(chan error, 1)
+	serverExited := make(chan error, 1+len(grpcServers))
 	go func() {
-		serverExited <- errors.Wrap(srv.ListenAndServe(), "server listen")
+		serverExited <- errors.Wrap(srv.Serve(listener), "server listen")
 	}()
+	for _, grpcServer := range grpcServers {
+		slog.Info(grpcServer.name+" server is starting",
+			"address", grpcServer.listener.Addr().String())
+		go func() {
+			serverExited <- errors.Wrap(
+				grpcServer.server.Serve(grpcServer.listener),
+				grpcServer.name+" server listen")
+		}()
+	}
 
 	select {
 	case <-systemSignalQuit:
 		ctx, cancel := context.WithTimeout(context.Background(), gracefulServerShutdownTimeout)
 		defer cancel()
+		var grpcServersStopped sync.WaitGroup
+		for _, grpcServer := range grpcServers {
+			grpcServersStopped.Add(1)
+			go func() {
+				defer grpcServersStopped.Done()
+				grpcServer.gracefulStop(ctx)
+			}()
+		}
+		defer grpcServersStopped.Wait()
 		if err := srv.Shutdown(ctx); err != nil {
 			return errors.Wrap(err, "graceful server shutdown")
 		}
@@ -124,6 +308,43 @@ func runServer(srv *http.Server) error {
 	}
 }
 
+// subscribeToRevocations wakes up revocation streams on revocations and after
+// pubsub reconnect, since revocation notifications could be missed.
+func subscribeToRevocations(
+	pubSub *postgres.PubSub,
+	revocationController *revocationcontroller.RevocationController,
+) error {
+
+	return pubSub.Subscribe(postgres.ChannelRevokedTokens, postgres.Subscription{
+		OnNotification: func(string) {
+			revocationController.NotifyRevocations()
+		},
+		OnResync: revocationController.NotifyRevocations,
+	})
+}
+
+// subscribeToConfigReloads reloads configs when any service instance asks to
+// and after pubsub reconnect, since reload notifications could be missed.
+func subscribeToConfigReloads(
+	pubSub *postgres.PubSub, ipAccessService *ipaccessservice.IPAccessService,
+) error {
+
+	reloadIPAccessRules := func() {
+		if err := ipAccessService.Reload(); err != nil {
+			slogutils.Error("reload ip access rules", err)
+		}
+	}
+
+	return pubSub.Subscribe(postgres.ChannelConfigReloads, postgres.Subscription{
+		OnNotification: func(configName string) {
+			if configName == postgres.ConfigIPAccessRules {
+				reloadIPAccessRules()
+			}
+		},
+		OnResync: reloadIPAccessRules,
+	})
+}
+
 func setLoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger := slog.Default()
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"auth/internal/config"
+	extauthzcontroller "auth/internal/controllers/ext-authz-controller"
+	grpcutils "auth/internal/controllers/grpc-utils"
+	httputils "auth/internal/controllers/http-utils"
+	sessioncontroller "auth/internal/controllers/session-controller"
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+type grpcServer struct {
+	name     string
+	server   *grpc.Server
+	listener net.Listener
+}
+
+func newSessionServer(
+	cfg config.GRPCServerConfig,
+	authService sessioncontroller.AuthService,
+	trustedProxies httputils.TrustedProxies,
+) (grpcServer, error) {
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	if err != nil {
+		return grpcServer{}, errors.Wrap(err, "listen")
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcutils.RequestIDInterceptor,
+		grpcutils.LoggingInterceptor,
+	))
+	sessioncontroller.NewSessionController(authService, trustedProxies).Register(server)
+
+	return grpcServer{
+		name:     "session grpc",
+		server:   server,
+		listener: listener,
+	}, nil
+}
+
+func newExtAuthzServer(
+	cfg config.ExtAuthzConfig, authService extauthzcontroller.AuthService,
+) (grpcServer, error) {
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, cfg.Port))
+	if err != nil {
+		return grpcServer{}, errors.Wrap(err, "listen")
+	}
+
+	server := grpc.NewServer()
+	extauthzcontroller.NewExtAuthzController(authService).Register(server)
+
+	return grpcServer{
+		name:     "ext authz",
+		server:   server,
+		listener: listener,
+	}, nil
+}
+
+// gracefulStop waits for pending RPCs until ctx is done and then cancels
+// them.
+func (s grpcServer) gracefulStop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
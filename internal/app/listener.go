@@ -0,0 +1,53 @@
+package app
+
+import (
+	"auth/internal/config"
+	iputils "auth/internal/utils/ip-utils"
+	"net"
+	"net/netip"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/pkg/errors"
+)
+
+// newListener creates HTTP server listener. If PROXY protocol is enabled, the
+// listener parses PROXY protocol v1/v2 headers sent by trusted upstreams and
+// reports client address from the header as connection remote address.
+// Connections from other upstreams are accepted only without the header.
+func newListener(cfg config.HTTPServerConfig) (net.Listener, error) {
+	listener, err := net.Listen("tcp", cfg.Host+":"+cfg.Port)
+	if err != nil {
+		return nil, errors.Wrap(err, "listen")
+	}
+	if !cfg.ProxyProtocol {
+		return listener, nil
+	}
+
+	trustedUpstreams, err := iputils.ParsePrefixes(cfg.ProxyProtocolTrustedCIDRs)
+	if err != nil {
+		listener.Close()
+		return nil, errors.Wrap(err, "parse PROXY protocol trusted CIDRs")
+	}
+
+	return &proxyproto.Listener{
+		Listener: listener,
+		Policy:   newProxyProtocolPolicy(trustedUpstreams),
+	}, nil
+}
+
+func newProxyProtocolPolicy(trustedUpstreams []netip.Prefix) proxyproto.PolicyFunc {
+	return func(upstream net.Addr) (proxyproto.Policy, error) {
+		tcpAddr, ok := upstream.(*net.TCPAddr)
+		if !ok {
+			return proxyproto.REJECT, errors.New("upstream address is not a TCP address")
+		}
+		addr, ok := netip.AddrFromSlice(tcpAddr.IP)
+		if !ok {
+			return proxyproto.REJECT, errors.New("invalid upstream IP")
+		}
+		if iputils.ContainsAddr(trustedUpstreams, addr.Unmap()) {
+			return proxyproto.USE, nil
+		}
+		return proxyproto.REJECT, nil
+	}
+}
@@ -5,16 +5,29 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
 )
 
 type Config struct {
-	Env        Env              `env:"ENV" env-required:"true"`
-	LogLevel   string           `env:"LOG_LEVEL" env-default:"warn"`
-	DBConfig   DatabaseConfig   `env-prefix:"DB_"`
-	HTTPServer HTTPServerConfig `env-prefix:"HTTP_SERVER_"`
-	SMTPServer SMTPServerConfig `env-prefix:"SMTP_"`
-	Auth       AuthConfig       `env-prefix:"AUTH_"`
-	Emails     Emails           `env-prefix:"EMAILS_"`
+	Env                   Env                         `env:"ENV" env-required:"true"`
+	LogLevel              string                      `env:"LOG_LEVEL" env-default:"warn"`
+	DBConfig              DatabaseConfig              `env-prefix:"DB_"`
+	HTTPServer            HTTPServerConfig            `env-prefix:"HTTP_SERVER_"`
+	GRPCServer            GRPCServerConfig            `env-prefix:"GRPC_SERVER_"`
+	ExtAuthz              ExtAuthzConfig              `env-prefix:"EXT_AUTHZ_"`
+	SMTPServer            SMTPServerConfig            `env-prefix:"SMTP_"`
+	Auth                  AuthConfig                  `env-prefix:"AUTH_"`
+	Emails                Emails                      `env-prefix:"EMAILS_"`
+	Notifications         NotificationsConfig         `env-prefix:"NOTIFICATIONS_"`
+	NotificationTemplates NotificationTemplatesConfig `env-prefix:"NOTIFICATION_TEMPLATES_"`
+	NotificationWebhook   NotificationWebhookConfig   `env-prefix:"NOTIFICATION_WEBHOOK_"`
+	SMSProvider           SMSProviderConfig           `env-prefix:"SMS_PROVIDER_"`
+	GeoIP                 GeoIPConfig                 `env-prefix:"GEOIP_"`
+	IPAccess              IPAccessConfig              `env-prefix:"IP_ACCESS_"`
+	MFA                   MFAConfig                   `env-prefix:"MFA_"`
+	WebAuthn              WebAuthnConfig              `env-prefix:"WEBAUTHN_"`
+	MagicLink             MagicLinkConfig             `env-prefix:"MAGIC_LINK_"`
+	Admin                 AdminConfig                 `env-prefix:"ADMIN_"`
 }
 
 type Env string
@@ -25,9 +38,52 @@ const (
 	EnvProd  Env = "prod"
 )
 
+// SessionLimitPolicy selects what happens to a new session of a user who
+// already has MaxSessionsPerUser sessions.
+type SessionLimitPolicy string
+
+const (
+	// SessionLimitReject rejects the new session.
+	SessionLimitReject SessionLimitPolicy = "reject"
+	// SessionLimitEvictOldest ends the sessions created first.
+	SessionLimitEvictOldest SessionLimitPolicy = "evict_oldest"
+	// SessionLimitEvictLeastRecentlyRefreshed ends the sessions refreshed
+	// last the longest ago.
+	SessionLimitEvictLeastRecentlyRefreshed SessionLimitPolicy = "evict_least_recently_refreshed"
+)
+
+// SetValue rejects unknown policies when the config is read.
+func (p *SessionLimitPolicy) SetValue(value string) error {
+	switch policy := SessionLimitPolicy(value); policy {
+	case SessionLimitReject, SessionLimitEvictOldest, SessionLimitEvictLeastRecentlyRefreshed:
+		*p = policy
+		return nil
+	default:
+		return errors.Errorf("unknown session limit policy: %s", value)
+	}
+}
+
 type HTTPServerConfig struct {
-	Host string `env:"HOST" env-required:"true"`
-	Port string `env:"PORT" env-required:"true"`
+	Host                      string   `env:"HOST" env-required:"true"`
+	Port                      string   `env:"PORT" env-required:"true"`
+	TrustedProxies            []string `env:"TRUSTED_PROXIES" env-separator:","`
+	TrustedProxyHeader        string   `env:"TRUSTED_PROXY_HEADER" env-default:"X-Forwarded-For"`
+	ProxyProtocol             bool     `env:"PROXY_PROTOCOL" env-default:"false"`
+	ProxyProtocolTrustedCIDRs []string `env:"PROXY_PROTOCOL_TRUSTED_CIDRS" env-separator:","`
+}
+
+// GRPCServerConfig configures SessionService gRPC server.
+type GRPCServerConfig struct {
+	Enabled bool   `env:"ENABLED" env-default:"false"`
+	Host    string `env:"HOST" env-default:"0.0.0.0"`
+	Port    string `env:"PORT" env-default:"9090"`
+}
+
+// ExtAuthzConfig configures Envoy external authorization gRPC server.
+type ExtAuthzConfig struct {
+	Enabled bool   `env:"ENABLED" env-default:"false"`
+	Host    string `env:"HOST" env-default:"0.0.0.0"`
+	Port    string `env:"PORT" env-default:"9191"`
 }
 
 type DatabaseConfig struct {
@@ -40,9 +96,111 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	AccessTokenDuration  time.Duration `env:"ACCESS_TOKEN_DURATION" env-required:"true"`
-	RefreshTokenDuration time.Duration `env:"REFRESH_TOKEN_DURATION" env-required:"true"`
-	JWTPrivateKey        string        `env:"JWT_PRIVATE_KEY" env-required:"true"`
+	AccessTokenDuration      time.Duration `env:"ACCESS_TOKEN_DURATION" env-required:"true"`
+	RefreshTokenDuration     time.Duration `env:"REFRESH_TOKEN_DURATION" env-required:"true"`
+	JWTPrivateKey            string        `env:"JWT_PRIVATE_KEY" env-required:"true"`
+	MaxFailedRefreshAttempts int           `env:"MAX_FAILED_REFRESH_ATTEMPTS" env-default:"5"`
+	// RevokeLinkSecret signs "This wasn't me" links in notifications. The
+	// links are not sent if it is empty.
+	RevokeLinkSecret   string        `env:"REVOKE_LINK_SECRET"`
+	RevokeLinkDuration time.Duration `env:"REVOKE_LINK_DURATION" env-default:"72h"`
+	// NewIPChallengeClients are clients whose refreshes from a new IP address
+	// must be confirmed with a one-time code sent by email.
+	NewIPChallengeClients []string      `env:"NEW_IP_CHALLENGE_CLIENTS" env-separator:","`
+	ChallengeCodeDuration time.Duration `env:"CHALLENGE_CODE_DURATION" env-default:"5m"`
+	MaxChallengeAttempts  int           `env:"MAX_CHALLENGE_ATTEMPTS" env-default:"5"`
+	// MaxSessionAge ends sessions this long after they were created however
+	// often they are refreshed, 0 means no limit.
+	MaxSessionAge time.Duration `env:"MAX_SESSION_AGE"`
+	// SessionIdleTimeout ends sessions not refreshed for this long, 0 means
+	// only RefreshTokenDuration applies.
+	SessionIdleTimeout time.Duration `env:"SESSION_IDLE_TIMEOUT"`
+	// ClientMaxSessionAges and ClientSessionIdleTimeouts override the limits
+	// for clients, like "web:720h,cli:0s".
+	ClientMaxSessionAges      map[string]time.Duration `env:"CLIENT_MAX_SESSION_AGES" env-separator:","`
+	ClientSessionIdleTimeouts map[string]time.Duration `env:"CLIENT_SESSION_IDLE_TIMEOUTS" env-separator:","`
+	// ProfileAccessTokenDurations and ProfileRefreshTokenDurations define
+	// lifetime profiles of sessions, like "mobile:15m,remember_me:1h" and
+	// "mobile:720h,remember_me:720h". A profile missing in one of them uses
+	// the global duration.
+	ProfileAccessTokenDurations  map[string]time.Duration `env:"PROFILE_ACCESS_TOKEN_DURATIONS" env-separator:","`
+	ProfileRefreshTokenDurations map[string]time.Duration `env:"PROFILE_REFRESH_TOKEN_DURATIONS" env-separator:","`
+	// ClientLifetimeProfiles selects default profiles of clients, like
+	// "ios:mobile,android:mobile". Requests may select another profile.
+	ClientLifetimeProfiles map[string]string `env:"CLIENT_LIFETIME_PROFILES" env-separator:","`
+	// RememberMeLifetimeProfile is selected by requests with remember me.
+	RememberMeLifetimeProfile string `env:"REMEMBER_ME_LIFETIME_PROFILE"`
+	// MaxAccessTokenDuration and MaxRefreshTokenDuration cap durations of
+	// all profiles, 0 means no cap.
+	MaxAccessTokenDuration  time.Duration `env:"MAX_ACCESS_TOKEN_DURATION"`
+	MaxRefreshTokenDuration time.Duration `env:"MAX_REFRESH_TOKEN_DURATION"`
+	// MaxSessionsPerUser caps active sessions of a user, 0 means no cap.
+	// SessionLimitPolicy applies once the cap is reached, users are notified
+	// about evicted sessions.
+	MaxSessionsPerUser int                `env:"MAX_SESSIONS_PER_USER"`
+	SessionLimitPolicy SessionLimitPolicy `env:"SESSION_LIMIT_POLICY" env-default:"reject"`
+}
+
+// MFAConfig configures TOTP second factor and recovery codes.
+type MFAConfig struct {
+	// TOTPSecretKey encrypts TOTP secrets in the database. MFA endpoints are
+	// disabled if it is empty.
+	TOTPSecretKey string `env:"TOTP_SECRET_KEY"`
+	// Issuer is shown by authenticator apps next to the user's email.
+	Issuer string `env:"ISSUER" env-default:"Auth"`
+	// TOTPSkew is the number of 30 second steps a code may be off by to
+	// tolerate clock drift of the user's device.
+	TOTPSkew           int           `env:"TOTP_SKEW" env-default:"1"`
+	MaxFailedAttempts  int           `env:"MAX_FAILED_ATTEMPTS" env-default:"5"`
+	LockoutDuration    time.Duration `env:"LOCKOUT_DURATION" env-default:"15m"`
+	RecoveryCodesCount int           `env:"RECOVERY_CODES_COUNT" env-default:"10"`
+	// PendingStepUpDuration is how long a step-up verified before the session
+	// is created waits for CreateSession.
+	PendingStepUpDuration time.Duration `env:"PENDING_STEP_UP_DURATION" env-default:"5m"`
+}
+
+// WebAuthnConfig configures passkey registration and login.
+type WebAuthnConfig struct {
+	// RPID is the relying party ID, the domain of the site or its
+	// registrable suffix. WebAuthn endpoints are disabled if it is empty.
+	RPID   string `env:"RP_ID"`
+	RPName string `env:"RP_NAME" env-default:"Auth"`
+	// Origins are allowed origins of the pages running ceremonies, like
+	// "https://app.company.com".
+	Origins []string `env:"ORIGINS" env-separator:","`
+	// UserVerification is "required", "preferred" or "discouraged". Logins
+	// without user verification are rejected if it is "required".
+	UserVerification string        `env:"USER_VERIFICATION" env-default:"required"`
+	CeremonyTimeout  time.Duration `env:"CEREMONY_TIMEOUT" env-default:"5m"`
+}
+
+// MagicLinkConfig configures passwordless login with links sent by email.
+// Links point to NotificationTemplatesConfig.MagicLinkURL.
+type MagicLinkConfig struct {
+	Enabled  bool          `env:"ENABLED" env-default:"false"`
+	Duration time.Duration `env:"DURATION" env-default:"15m"`
+	// MaxActiveLinks limits unexpired links of a user, so the endpoint can't
+	// be used to flood a mailbox.
+	MaxActiveLinks int `env:"MAX_ACTIVE_LINKS" env-default:"3"`
+	// CookieDomain and CookieSameSite set the nonce cookie binding a link to
+	// the browser which requested it. SameSite is "strict", "lax" or "none",
+	// the latter is needed if the login page is on another site.
+	CookieDomain   string `env:"COOKIE_DOMAIN"`
+	CookieSameSite string `env:"COOKIE_SAME_SITE" env-default:"lax"`
+}
+
+type IPAccessConfig struct {
+	GlobalAllowlist []string `env:"GLOBAL_ALLOWLIST" env-separator:","`
+	GlobalDenylist  []string `env:"GLOBAL_DENYLIST" env-separator:","`
+	// ReloadPeriod of database rules. 0 disables periodic reloads, rules are
+	// then reloaded only when the admin API asks to.
+	ReloadPeriod time.Duration `env:"RELOAD_PERIOD" env-default:"1m"`
+}
+
+// AdminConfig configures the admin API. It is enabled if APIToken is set,
+// requests then must present it as the bearer token.
+type AdminConfig struct {
+	APIToken string `env:"API_TOKEN"`
 }
 
 type SMTPServerConfig struct {
@@ -56,6 +214,64 @@ type Emails struct {
 	SupportEmail string `env:"SUPPORT_EMAIL" env-required:"true"`
 }
 
+// NotificationsConfig configures routing of notifications to channels and
+// delivery of them from the outbox.
+type NotificationsConfig struct {
+	// Routes maps events to channels separated by "|", like
+	// "refresh_from_new_ip:email|in_app,refresh_token_locked:email|sms".
+	// Channels are email, in_app, webhook and sms.
+	Routes      map[string]string `env:"ROUTES" env-default:"refresh_from_new_ip:email|in_app,refresh_token_locked:email|in_app,session_evicted:email|in_app"`
+	Workers     int               `env:"WORKERS" env-default:"4"`
+	PollPeriod  time.Duration     `env:"POLL_PERIOD" env-default:"5s"`
+	MaxAttempts int               `env:"MAX_ATTEMPTS" env-default:"10"`
+	MinBackoff  time.Duration     `env:"MIN_BACKOFF" env-default:"30s"`
+	MaxBackoff  time.Duration     `env:"MAX_BACKOFF" env-default:"6h"`
+}
+
+// NotificationTemplatesConfig configures rendering of notifications to users.
+type NotificationTemplatesConfig struct {
+	// Dir contains templates which override the embedded ones file by file,
+	// laid out as <language>/<event>.txt, <language>/<event>.html and
+	// <language>/sms/<event>.txt.
+	Dir             string `env:"DIR"`
+	DefaultLanguage string `env:"DEFAULT_LANGUAGE" env-default:"ru"`
+	DefaultTimeZone string `env:"DEFAULT_TIME_ZONE" env-default:"UTC"`
+	// RevokeSessionURL is the public URL of the "This wasn't me" page,
+	// GET /sessions/revoke-link. The signed link token is added as the token
+	// query parameter.
+	RevokeSessionURL string `env:"REVOKE_SESSION_URL"`
+	// MagicLinkURL is the public URL of the page finishing passwordless
+	// logins, it posts the token query parameter to POST /magic-link/callback.
+	MagicLinkURL string `env:"MAGIC_LINK_URL"`
+}
+
+// NotificationWebhookConfig configures the webhook channel, which posts
+// notifications as JSON. The channel is enabled if URL is set.
+type NotificationWebhookConfig struct {
+	URL string `env:"URL"`
+	// Secret signs request bodies with HMAC-SHA256 in the
+	// X-Webhook-Signature header if set.
+	Secret  string        `env:"SECRET"`
+	Timeout time.Duration `env:"TIMEOUT" env-default:"10s"`
+}
+
+// SMSProviderConfig configures the SMS channel, which posts messages as
+// {"from", "to", "text"} JSON to the provider. The channel is enabled if URL
+// is set.
+type SMSProviderConfig struct {
+	URL       string        `env:"URL"`
+	AuthToken string        `env:"AUTH_TOKEN"`
+	From      string        `env:"FROM"`
+	Timeout   time.Duration `env:"TIMEOUT" env-default:"10s"`
+}
+
+// GeoIPConfig configures IP address location shown in notifications.
+type GeoIPConfig struct {
+	// DatabasePath is a MaxMind GeoIP2 or GeoLite2 City database. Locations
+	// are not shown if it is empty.
+	DatabasePath string `env:"DATABASE_PATH"`
+}
+
 var (
 	once sync.Once
 	cfg  Config
@@ -0,0 +1,54 @@
+package admincontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminController serves the admin API to operators who present the API
+// token.
+type AdminController struct {
+	apiToken             string
+	ipAccessService      IPAccessService
+	configReloadNotifier ConfigReloadNotifier
+	notificationService  NotificationService
+}
+
+type IPAccessService interface {
+	Reload() error
+}
+
+// ConfigReloadNotifier asks other service instances to reload the config.
+type ConfigReloadNotifier interface {
+	NotifyConfigReload(configName string) error
+}
+
+type NotificationService interface {
+	GetDeadNotifications(limit, offset int) ([]domain.OutboxNotification, error)
+	ReplayDeadNotification(id uuid.UUID) error
+}
+
+func NewAdminController(
+	apiToken string,
+	ipAccessService IPAccessService,
+	configReloadNotifier ConfigReloadNotifier,
+	notificationService NotificationService,
+) *AdminController {
+
+	return &AdminController{
+		apiToken:             apiToken,
+		ipAccessService:      ipAccessService,
+		configReloadNotifier: configReloadNotifier,
+		notificationService:  notificationService,
+	}
+}
+
+func (c *AdminController) RegisterRoutes(engine *gin.Engine) {
+	adminGroup := engine.Group("admin", ginutils.RequireAPIToken(c.apiToken))
+	adminGroup.POST("/ip-access-rules/reload", c.reloadIPAccessRules)
+	adminGroup.GET("/notification-outbox/dead", c.getDeadNotifications)
+	adminGroup.POST("/notification-outbox/dead/:"+notificationIDParamName+"/replay", c.replayDeadNotification)
+}
@@ -0,0 +1,30 @@
+package admincontroller
+
+import (
+	"auth/internal/domain"
+	"time"
+)
+
+type OutboxNotificationDTO struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userID"`
+	Channel   string    `json:"channel"`
+	Subject   string    `json:"subject"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newOutboxNotificationDTO(notification domain.OutboxNotification) OutboxNotificationDTO {
+	return OutboxNotificationDTO{
+		ID:        notification.ID.String(),
+		UserID:    notification.UserID.String(),
+		Channel:   string(notification.Channel),
+		Subject:   notification.Content.Subject,
+		Attempts:  notification.Attempts,
+		LastError: notification.LastError,
+		FailedAt:  notification.NextAttemptTime,
+		CreatedAt: notification.CreationTime,
+	}
+}
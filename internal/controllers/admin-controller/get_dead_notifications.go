@@ -0,0 +1,58 @@
+package admincontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultDeadNotificationsLimit = 50
+
+type getDeadNotificationsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type getDeadNotificationsResponseBody struct {
+	Notifications []OutboxNotificationDTO `json:"notifications"`
+}
+
+// @Summary		List dead notifications
+// @Description	List outbox notifications which failed to be sent after all attempts, most recent first.
+// @Tags			admin
+// @Produce		json
+// @Param			Authorization	header		string								yes	"Bearer admin API token"
+// @Param			limit			query		int									no	"Max number of notifications, 50 by default"
+// @Param			offset			query		int									no	"Number of notifications to skip"
+// @Success		200				{object}	getDeadNotificationsResponseBody	"Success"
+// @Failure		400				{object}	httputils.HTTPError					"Bad request"
+// @Failure		401				{object}	httputils.HTTPError					"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError					"Internal server error"
+// @Router			/admin/notification-outbox/dead [get]
+func (controller *AdminController) getDeadNotifications(c *gin.Context) {
+	var query getDeadNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		ginutils.BindQueryError(c, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultDeadNotificationsLimit
+	}
+
+	notifications, err := controller.notificationService.
+		GetDeadNotifications(query.Limit, query.Offset)
+	if err != nil {
+		slogutils.Error("get dead notifications", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	dtos := make([]OutboxNotificationDTO, 0, len(notifications))
+	for _, notification := range notifications {
+		dtos = append(dtos, newOutboxNotificationDTO(notification))
+	}
+
+	c.JSON(http.StatusOK, getDeadNotificationsResponseBody{Notifications: dtos})
+}
@@ -0,0 +1,36 @@
+package admincontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/db/postgres"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary		Reload IP access rules
+// @Description	Reload IP allowlists and denylists from the database on all service instances.
+// @Tags			admin
+// @Param			Authorization	header	string	yes	"Bearer admin API token"
+// @Success		204				"Success"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/admin/ip-access-rules/reload [post]
+func (controller *AdminController) reloadIPAccessRules(c *gin.Context) {
+	err := controller.ipAccessService.Reload()
+	if err != nil {
+		slogutils.Error("reload ip access rules", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	err = controller.configReloadNotifier.NotifyConfigReload(postgres.ConfigIPAccessRules)
+	if err != nil {
+		slogutils.Error("notify ip access rules reload", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,48 @@
+package admincontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const notificationIDParamName = "notificationID"
+
+// @Summary		Replay dead notification
+// @Description	Schedule a dead outbox notification for sending again with attempts reset.
+// @Tags			admin
+// @Param			Authorization	header	string	yes	"Bearer admin API token"
+// @Param			notificationID	path	string	yes	"Notification ID"
+// @Success		204				"Success"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		404				{object}	httputils.HTTPError	"Dead notification not found"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/admin/notification-outbox/dead/{notificationID}/replay [post]
+func (controller *AdminController) replayDeadNotification(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param(notificationIDParamName))
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "parse notificationID"))
+		return
+	}
+
+	var notFoundError *domain.NotFoundError
+	err = controller.notificationService.ReplayDeadNotification(notificationID)
+	switch {
+	case err == nil:
+	case errors.As(err, &notFoundError):
+		ginutils.NotFoundError(c, err)
+		return
+	default:
+		slogutils.Error("replay dead notification", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
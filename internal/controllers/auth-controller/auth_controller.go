@@ -1,24 +1,46 @@
 package authcontroller
 
 import (
+	httputils "auth/internal/controllers/http-utils"
 	"auth/internal/domain"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type AuthController struct {
-	authService AuthService
+	authService    AuthService
+	trustedProxies httputils.TrustedProxies
 }
 
+//go:generate mockery --name AuthService --filename auth_service.go
 type AuthService interface {
-	CreateSession(userID uuid.UUID, requestIP string) (*domain.Session, error)
-	RefreshSession(session *domain.Session, requestIP string) (*domain.Session, error)
+	CreateSession(
+		userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
+	) (*domain.Session, error)
+	RefreshSession(session *domain.Session, requestIP, userAgent string) (*domain.Session, error)
+	CompleteRefreshChallenge(
+		session *domain.Session, challengeID uuid.UUID, code string,
+		requestIP, userAgent string,
+	) (*domain.Session, error)
+	RevokeSession(accessTokenSigned []byte, requestIP string) error
+	ValidateAccessToken(accessTokenSigned []byte) (*domain.AccessToken, error)
+	RequireRecentAuthentication(accessToken *domain.AccessToken, maxAge time.Duration) error
+	RevokeAccessToken(accessTokenSigned []byte) error
+	RaiseUserTokensNotBefore(userID uuid.UUID, notBefore time.Time) error
+	CheckRevokeLink(token string) (*domain.RevokeLink, error)
+	RevokeByLink(token string, scope domain.RevokeLinkScope, requestIP string) error
 }
 
-func NewAuthController(authService AuthService) *AuthController {
+func NewAuthController(
+	authService AuthService,
+	trustedProxies httputils.TrustedProxies,
+) *AuthController {
+
 	return &AuthController{
-		authService: authService,
+		authService:    authService,
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -26,4 +48,17 @@ func (c *AuthController) RegisterRoutes(engine *gin.Engine) {
 	sessionGroup := engine.Group("sessions")
 	sessionGroup.POST("", c.createSession)
 	sessionGroup.POST("/refresh", c.refreshSession)
+	sessionGroup.POST("/refresh/challenge", c.completeRefreshChallenge)
+	sessionGroup.POST("/revoke", c.revokeSession)
+	sessionGroup.GET("/revoke-link", c.showRevokeLink)
+	sessionGroup.POST("/revoke-link", c.revokeByLink)
+
+	tokenGroup := engine.Group("tokens")
+	tokenGroup.POST("/introspect", c.introspectToken)
+	tokenGroup.POST("/revoke", c.revokeToken)
+
+	engine.Any("/auth/verify", c.verifyRequest)
+
+	userGroup := engine.Group("users")
+	userGroup.PUT("/:"+userIDParamName+"/tokens-not-before", c.raiseUserTokensNotBefore)
 }
@@ -0,0 +1,80 @@
+package authcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type completeRefreshChallengeRequestBody struct {
+	AccessToken  string `json:"accessToken" binding:"required"`
+	RefreshToken string `json:"refreshToken" binding:"required"`
+	ChallengeID  string `json:"challengeId" binding:"required,uuid"`
+	Code         string `json:"code" binding:"required"`
+}
+
+type completeRefreshChallengeResponseBody SessionDTO
+
+// @Summary		Complete refresh challenge
+// @Description	Complete the refresh which returned the challenge_required error with the code emailed to the user.
+// @Description	The request must come from the same IP address with the same tokens as the refresh.
+// @Description	The code expires in minutes and the challenge is deleted after the attempts limit.
+// @Tags			session
+// @Accept			json
+// @Produce		json
+// @Param			challenge	body		completeRefreshChallengeRequestBody		yes	"Tokens, challenge ID and code"
+// @Success		201			{object}	completeRefreshChallengeResponseBody	"Success"
+// @Failure		400			{object}	httputils.HTTPError						"Bad request"
+// @Failure		401			{object}	httputils.HTTPError						"Unauthorized"
+// @Failure		403			{object}	httputils.HTTPError						"IP address is not allowed"
+// @Failure		500			{object}	httputils.HTTPError						"Internal server error"
+// @Router			/sessions/refresh/challenge [post]
+func (controller *AuthController) completeRefreshChallenge(c *gin.Context) {
+	var reqBody completeRefreshChallengeRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+	refreshTokenDecoded, err := base64.StdEncoding.DecodeString(reqBody.RefreshToken)
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "decode base64 refresh token value"))
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	var ipNotAllowedError *domain.IPNotAllowedError
+	session, err := controller.authService.CompleteRefreshChallenge(
+		&domain.Session{
+			AccessTokenSigned: []byte(reqBody.AccessToken),
+			RefreshTokenValue: refreshTokenDecoded,
+		},
+		uuid.MustParse(reqBody.ChallengeID), reqBody.Code,
+		httputils.GetRequestIP(c.Request, controller.trustedProxies),
+		c.Request.UserAgent())
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		ginutils.UnauthorizedError(c, err)
+		return
+	case errors.As(err, &ipNotAllowedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeIPNotAllowed, err)
+		return
+	default:
+		slogutils.Error("complete refresh challenge", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, completeRefreshChallengeResponseBody{
+		AccessToken: string(session.AccessTokenSigned),
+		RefreshToken: base64.StdEncoding.
+			EncodeToString(session.RefreshTokenValue),
+	})
+}
@@ -3,16 +3,26 @@ package authcontroller
 import (
 	httputils "auth/internal/controllers/http-utils"
 	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
 	slogutils "auth/internal/utils/slog-utils"
 	"encoding/base64"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
-const userIDParamName = "userID"
+const (
+	userIDParamName          = "userID"
+	clientIDParamName        = "clientID"
+	amrParamName             = "amr"
+	maxAgeParamName          = "maxAge"
+	rememberMeParamName      = "rememberMe"
+	lifetimeProfileParamName = "lifetimeProfile"
+	stepUpTicketParamName    = "stepUpTicket"
+)
 
 type createSessionResponseBody SessionDTO
 
@@ -20,10 +30,16 @@ type createSessionResponseBody SessionDTO
 //	@Description	Create new access and refresh tokens given user ID
 //	@Tags			session
 //	@Produce		json
-//	@Param			userID	query		string						yes	"User ID"
-//	@Success		201		{object}	createSessionResponseBody	"Success"
-//	@Failure		400		{object}	httputils.HTTPError			"Bad request"
-//	@Failure		500		{object}	httputils.HTTPError			"Internal server error"
+//	@Param			userID			query		string						yes	"User ID"
+//	@Param			clientID		query		string						no	"Client ID"
+//	@Param			amr				query		[]string					no	"Methods the user authenticated with, RFC 8176 values like pwd"	collectionFormat(multi)
+//	@Param			rememberMe		query		bool						no	"Use the remember me lifetime profile"
+//	@Param			lifetimeProfile	query		string						no	"Lifetime profile of the tokens, the client default if empty"
+//	@Param			stepUpTicket	query		string						no	"Ticket returned by the step-up of the user for this login"
+//	@Success		201				{object}	createSessionResponseBody	"Success"
+//	@Failure		400				{object}	httputils.HTTPError			"Bad request"
+//	@Failure		403				{object}	httputils.HTTPError			"IP address is not allowed or the user has the maximum number of sessions"
+//	@Failure		500				{object}	httputils.HTTPError			"Internal server error"
 //	@Router			/sessions [post]
 func (controller *AuthController) createSession(c *gin.Context) {
 	userID, err := uuid.Parse(c.Query(userIDParamName))
@@ -31,12 +47,49 @@ func (controller *AuthController) createSession(c *gin.Context) {
 		ginutils.BadRequest(c, errors.Wrap(err, "parse userID"))
 		return
 	}
+	rememberMe := false
+	if value := c.Query(rememberMeParamName); value != "" {
+		rememberMe, err = strconv.ParseBool(value)
+		if err != nil {
+			ginutils.BadRequest(c, errors.Wrap(err, "parse rememberMe"))
+			return
+		}
+	}
+	stepUpTicket := uuid.Nil
+	if value := c.Query(stepUpTicketParamName); value != "" {
+		stepUpTicket, err = uuid.Parse(value)
+		if err != nil {
+			ginutils.BadRequest(c, errors.Wrap(err, "parse stepUpTicket"))
+			return
+		}
+	}
 
+	var ipNotAllowedError *domain.IPNotAllowedError
+	var validationError *domain.ValidationError
+	var sessionLimitReachedError *domain.SessionLimitReachedError
 	session, err := controller.authService.
 		CreateSession(
 			userID,
-			httputils.GetRequestIP(c.Request))
-	if err != nil {
+			c.Query(clientIDParamName),
+			httputils.GetRequestIP(c.Request, controller.trustedProxies),
+			domain.SessionOptions{
+				AMR:             c.QueryArray(amrParamName),
+				LifetimeProfile: c.Query(lifetimeProfileParamName),
+				RememberMe:      rememberMe,
+				StepUpTicket:    stepUpTicket,
+			})
+	switch {
+	case err == nil:
+	case errors.As(err, &validationError):
+		ginutils.BadRequest(c, err)
+		return
+	case errors.As(err, &ipNotAllowedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeIPNotAllowed, err)
+		return
+	case errors.As(err, &sessionLimitReachedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeSessionLimitReached, err)
+		return
+	default:
 		slogutils.Error("create session", err)
 		ginutils.InternalError(c)
 		return
@@ -4,3 +4,11 @@ type SessionDTO struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 }
+
+// ChallengeRequiredErrorDTO is returned if the refresh from a new IP address
+// must be confirmed with the code emailed to the user.
+type ChallengeRequiredErrorDTO struct {
+	Message     string `json:"error"`
+	Code        string `json:"code"`
+	ChallengeID string `json:"challengeId,omitempty"`
+}
@@ -0,0 +1,70 @@
+package authcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type introspectTokenResponseBody struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	// AuthTime is set if the authentication time of the session is known.
+	AuthTime int64 `json:"auth_time,omitempty"`
+	// ACR is set for stepped up sessions, AMR if the methods are known.
+	ACR string   `json:"acr,omitempty"`
+	AMR []string `json:"amr,omitempty"`
+}
+
+// @Summary		Introspect access token
+// @Description	Check whether the access token is active as described in RFC 7662.
+// @Description	Inactive tokens are reported with "active": false only.
+// @Tags			token
+// @Accept			x-www-form-urlencoded
+// @Produce		json
+// @Param			token	formData	string						yes	"Access token"
+// @Success		200		{object}	introspectTokenResponseBody	"Success"
+// @Failure		400		{object}	httputils.HTTPError			"Bad request"
+// @Failure		500		{object}	httputils.HTTPError			"Internal server error"
+// @Router			/tokens/introspect [post]
+func (controller *AuthController) introspectToken(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		ginutils.BadRequest(c, errors.New("token is required"))
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	accessToken, err := controller.authService.ValidateAccessToken([]byte(token))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		c.JSON(http.StatusOK, introspectTokenResponseBody{Active: false})
+		return
+	default:
+		slogutils.Error("introspect token", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	resBody := introspectTokenResponseBody{
+		Active:    true,
+		Subject:   accessToken.UserID.String(),
+		SessionID: accessToken.SessionID.String(),
+		IssuedAt:  accessToken.IssuedAt.Unix(),
+		ExpiresAt: accessToken.ExpTime.Unix(),
+		ACR:       accessToken.ACR,
+		AMR:       accessToken.AMR,
+	}
+	if !accessToken.AuthTime.IsZero() {
+		resBody.AuthTime = accessToken.AuthTime.Unix()
+	}
+	c.JSON(http.StatusOK, resBody)
+}
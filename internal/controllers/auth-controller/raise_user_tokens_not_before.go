@@ -0,0 +1,62 @@
+package authcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type raiseUserTokensNotBeforeRequestBody struct {
+	NotBefore *time.Time `json:"notBefore"`
+}
+
+// @Summary		Invalidate user's tokens
+// @Description	Invalidate all access and refresh tokens of the user issued before given time, even not yet expired ones.
+// @Description	Current time is used if the time is not provided. Time can't be in the future.
+// @Description	The watermark is never moved back, so earlier time than the current watermark is ignored.
+// @Tags			user
+// @Accept			json
+// @Param			userID		path	string								yes	"User ID"
+// @Param			not_before	body	raiseUserTokensNotBeforeRequestBody	no	"Tokens watermark"
+// @Success		204			"Success"
+// @Failure		400			{object}	httputils.HTTPError	"Bad request"
+// @Failure		500			{object}	httputils.HTTPError	"Internal server error"
+// @Router			/users/{userID}/tokens-not-before [put]
+func (controller *AuthController) raiseUserTokensNotBefore(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param(userIDParamName))
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "parse userID"))
+		return
+	}
+	var reqBody raiseUserTokensNotBeforeRequestBody
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&reqBody); err != nil {
+			ginutils.BindJSONError(c, err)
+			return
+		}
+	}
+
+	now := time.Now()
+	notBefore := now
+	if reqBody.NotBefore != nil {
+		if reqBody.NotBefore.After(now) {
+			ginutils.BadRequest(c, errors.New("notBefore is in the future"))
+			return
+		}
+		notBefore = *reqBody.NotBefore
+	}
+
+	err = controller.authService.RaiseUserTokensNotBefore(userID, notBefore)
+	if err != nil {
+		slogutils.Error("raise user tokens watermark", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -20,6 +20,7 @@ type refreshSessionResponseBody SessionDTO
 // @Description	Provided refresh token is invalidated on success.
 // @Description	Provided refresh token should have been issued with provided access token.
 // @Description	Provided access token can be expired, but refresh token can't.
+// @Description	A refresh from a new IP address may require a code emailed to the user, then the refresh is completed by POST /sessions/refresh/challenge.
 // @Tags			session
 // @Accept			json
 // @Produce		json
@@ -27,6 +28,7 @@ type refreshSessionResponseBody SessionDTO
 // @Success		201							{object}	refreshSessionResponseBody	"Success"
 // @Failure		400							{object}	httputils.HTTPError			"Bad request"
 // @Failure		401							{object}	httputils.HTTPError			"Unauthorized"
+// @Failure		403							{object}	ChallengeRequiredErrorDTO	"IP address is not allowed or refresh must be confirmed with the emailed code (challengeId is set)"
 // @Failure		500							{object}	httputils.HTTPError			"Internal server error"
 // @Router			/sessions/refresh [post]
 func (controller *AuthController) refreshSession(c *gin.Context) {
@@ -42,17 +44,30 @@ func (controller *AuthController) refreshSession(c *gin.Context) {
 	}
 
 	var unauthorizedError *domain.UnauthorizedError
+	var ipNotAllowedError *domain.IPNotAllowedError
+	var challengeRequiredError *domain.ChallengeRequiredError
 	session, err := controller.authService.RefreshSession(
 		&domain.Session{
 			AccessTokenSigned: []byte(reqBody.AccessToken),
 			RefreshTokenValue: refreshTokenDecoded,
 		},
-		httputils.GetRequestIP(c.Request))
+		httputils.GetRequestIP(c.Request, controller.trustedProxies),
+		c.Request.UserAgent())
 	switch {
 	case err == nil:
 	case errors.As(err, &unauthorizedError):
 		ginutils.UnauthorizedError(c, err)
 		return
+	case errors.As(err, &ipNotAllowedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeIPNotAllowed, err)
+		return
+	case errors.As(err, &challengeRequiredError):
+		c.JSON(http.StatusForbidden, ChallengeRequiredErrorDTO{
+			Message:     err.Error(),
+			Code:        httputils.ErrorCodeChallengeRequired,
+			ChallengeID: challengeRequiredError.ChallengeID.String(),
+		})
+		return
 	default:
 		ginutils.InternalError(c)
 		return
@@ -0,0 +1,59 @@
+package authcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type revokeByLinkForm struct {
+	Token string                 `form:"token" binding:"required"`
+	Scope domain.RevokeLinkScope `form:"scope" binding:"required,oneof=session all"`
+}
+
+// @Summary		Revoke by "This wasn't me" link
+// @Description	Revoke the session the link was sent about, or all sessions of the user by raising the user's tokens watermark.
+// @Description	The link can be used once, every use and reuse attempt is audited.
+// @Tags			session
+// @Accept			x-www-form-urlencoded
+// @Produce		html
+// @Param			token	formData	string	yes	"Signed link token"
+// @Param			scope	formData	string	yes	"What to revoke"	Enums(session, all)
+// @Success		200		"Result page"
+// @Failure		400		"Error page"
+// @Failure		401		"Error page"
+// @Failure		500		"Error page"
+// @Router			/sessions/revoke-link [post]
+func (controller *AuthController) revokeByLink(c *gin.Context) {
+	var form revokeByLinkForm
+	if err := c.ShouldBind(&form); err != nil {
+		renderRevokeLinkPage(c, http.StatusBadRequest, revokeLinkPageData{
+			Error: "The link is incomplete. Open it from the email again."})
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := controller.authService.RevokeByLink(
+		form.Token, form.Scope,
+		httputils.GetRequestIP(c.Request, controller.trustedProxies))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		renderRevokeLinkPage(c, http.StatusUnauthorized, revokeLinkPageData{
+			Error: "The link is expired or already used. " +
+				"You can end sessions in your account settings."})
+		return
+	default:
+		slogutils.Error("revoke by link", err)
+		renderRevokeLinkPage(c, http.StatusInternalServerError, revokeLinkPageData{
+			Error: "Something went wrong. Try again later."})
+		return
+	}
+
+	renderRevokeLinkPage(c, http.StatusOK, revokeLinkPageData{
+		Scope: string(form.Scope), Done: true})
+}
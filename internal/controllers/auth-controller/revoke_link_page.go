@@ -0,0 +1,53 @@
+package authcontroller
+
+import (
+	"html/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// revokeLinkPageTemplate is the "This wasn't me" page opened from security
+// notifications. The page is for browsers, so errors are shown on it rather
+// than returned as JSON.
+var revokeLinkPageTemplate = template.Must(template.New("revoke-link").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Secure your account</title>
+</head>
+<body>
+{{- if .Error}}
+<p>{{.Error}}</p>
+{{- else if .Done}}
+<p>{{if eq .Scope "all"}}All sessions of your account were ended.{{else}}The session was ended.{{end}}
+Change your password if you think someone else knows it.</p>
+{{- else}}
+<p>If you don't recognize the sign-in, end the session. End all sessions if you don't
+recognize other sign-ins too, you will have to sign in again on your devices.</p>
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit" name="scope" value="session">End this session</button>
+<button type="submit" name="scope" value="all">End all sessions</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+type revokeLinkPageData struct {
+	Token string
+	Scope string
+	Done  bool
+	Error string
+}
+
+func renderRevokeLinkPage(c *gin.Context, status int, data revokeLinkPageData) {
+	// the token is in the URL, so it must not leak through caches and
+	// referrers, and the page must not be framed for clickjacking
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Frame-Options", "DENY")
+	c.Render(status, render.HTML{Template: revokeLinkPageTemplate, Data: data})
+}
@@ -0,0 +1,52 @@
+package authcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type revokeSessionRequestBody struct {
+	AccessToken string `json:"accessToken" binding:"required"`
+}
+
+// @Summary		Revoke session
+// @Description	Invalidate the refresh token the access token was issued with.
+// @Description	All access tokens issued with the refresh token are rejected from now on, even not yet expired ones.
+// @Tags			session
+// @Accept			json
+// @Param			access_token	body	revokeSessionRequestBody	yes	"Access token"
+// @Success		204				"Success"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/sessions/revoke [post]
+func (controller *AuthController) revokeSession(c *gin.Context) {
+	var reqBody revokeSessionRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := controller.authService.RevokeSession(
+		[]byte(reqBody.AccessToken),
+		httputils.GetRequestIP(c.Request, controller.trustedProxies))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		ginutils.UnauthorizedError(c, err)
+		return
+	default:
+		slogutils.Error("revoke session", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
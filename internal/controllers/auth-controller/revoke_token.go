@@ -0,0 +1,39 @@
+package authcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// @Summary		Revoke access token
+// @Description	Invalidate a single access token as described in RFC 7009. The session the token belongs to stays active.
+// @Description	Invalid and already expired tokens are ignored.
+// @Tags			token
+// @Accept			x-www-form-urlencoded
+// @Param			token	formData	string	yes	"Access token"
+// @Success		200		"Success"
+// @Failure		400		{object}	httputils.HTTPError	"Bad request"
+// @Failure		500		{object}	httputils.HTTPError	"Internal server error"
+// @Router			/tokens/revoke [post]
+func (controller *AuthController) revokeToken(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		ginutils.BadRequest(c, errors.New("token is required"))
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := controller.authService.RevokeAccessToken([]byte(token))
+	if err != nil && !errors.As(err, &unauthorizedError) {
+		slogutils.Error("revoke token", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
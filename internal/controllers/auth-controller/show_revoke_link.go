@@ -0,0 +1,52 @@
+package authcontroller
+
+import (
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type revokeLinkQuery struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// @Summary		"This wasn't me" page
+// @Description	Show the confirmation page of the "This wasn't me" link from security notifications.
+// @Description	Opening the page doesn't use the link up, so mail scanners can't revoke sessions.
+// @Tags			session
+// @Produce		html
+// @Param			token	query	string	yes	"Signed link token"
+// @Success		200		"Confirmation page"
+// @Failure		400		"Error page"
+// @Failure		401		"Error page"
+// @Failure		500		"Error page"
+// @Router			/sessions/revoke-link [get]
+func (controller *AuthController) showRevokeLink(c *gin.Context) {
+	var query revokeLinkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		renderRevokeLinkPage(c, http.StatusBadRequest, revokeLinkPageData{
+			Error: "The link is incomplete. Open it from the email again."})
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := controller.authService.CheckRevokeLink(query.Token)
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		renderRevokeLinkPage(c, http.StatusUnauthorized, revokeLinkPageData{
+			Error: "The link is expired or already used. " +
+				"You can end sessions in your account settings."})
+		return
+	default:
+		slogutils.Error("check revoke link", err)
+		renderRevokeLinkPage(c, http.StatusInternalServerError, revokeLinkPageData{
+			Error: "Something went wrong. Try again later."})
+		return
+	}
+
+	renderRevokeLinkPage(c, http.StatusOK, revokeLinkPageData{Token: query.Token})
+}
@@ -0,0 +1,94 @@
+package authcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// @Summary		Verify request
+// @Description	Forward authentication endpoint for nginx auth_request and Traefik ForwardAuth.
+// @Description	Validates the bearer access token including revocations and returns identity headers.
+// @Description	Accepts any HTTP method, so proxies may forward the original one.
+// @Description	With maxAge, tokens of users who authenticated longer ago are rejected with
+// @Description	the insufficient_user_authentication error of RFC 9470.
+// @Tags			token
+// @Param			Authorization	header	string	yes	"Bearer access token"
+// @Param			maxAge			query	int		no	"Maximum authentication age in seconds"
+// @Success		200				"Success, identity is returned in X-User-ID, X-Session-ID and X-Token-ID headers, authentication context in X-Auth-Time, X-ACR and X-AMR headers"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/auth/verify [get]
+func (controller *AuthController) verifyRequest(c *gin.Context) {
+	token, ok := httputils.BearerToken(c.GetHeader("Authorization"))
+	if !ok {
+		c.Header("WWW-Authenticate", "Bearer")
+		ginutils.UnauthorizedError(c, errors.New("bearer token is required"))
+		return
+	}
+	maxAgeStr, maxAgeSet := c.GetQuery(maxAgeParamName)
+	var maxAgeSeconds uint64
+	if maxAgeSet {
+		var err error
+		maxAgeSeconds, err = strconv.ParseUint(maxAgeStr, 10, 32)
+		if err != nil {
+			ginutils.BadRequest(c, errors.Wrap(err, "parse maxAge"))
+			return
+		}
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	accessToken, err := controller.authService.ValidateAccessToken([]byte(token))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		ginutils.UnauthorizedError(c, err)
+		return
+	default:
+		slogutils.Error("verify request", err)
+		ginutils.InternalError(c)
+		return
+	}
+	if maxAgeSet {
+		err := controller.authService.RequireRecentAuthentication(
+			accessToken, time.Duration(maxAgeSeconds)*time.Second)
+		if err != nil {
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="%s", max_age=%d`,
+				httputils.ErrorCodeReauthenticationRequired, maxAgeSeconds))
+			c.JSON(http.StatusUnauthorized, httputils.HTTPError{
+				Message: err.Error(),
+				Code:    httputils.ErrorCodeReauthenticationRequired,
+			})
+			return
+		}
+	}
+
+	c.Header(httputils.UserIDHeaderName, accessToken.UserID.String())
+	c.Header(httputils.SessionIDHeaderName, accessToken.SessionID.String())
+	if accessToken.ID != uuid.Nil {
+		c.Header(httputils.TokenIDHeaderName, accessToken.ID.String())
+	}
+	if !accessToken.AuthTime.IsZero() {
+		c.Header(httputils.AuthTimeHeaderName,
+			strconv.FormatInt(accessToken.AuthTime.Unix(), 10))
+	}
+	if accessToken.ACR != "" {
+		c.Header(httputils.ACRHeaderName, accessToken.ACR)
+	}
+	if len(accessToken.AMR) > 0 {
+		c.Header(httputils.AMRHeaderName, strings.Join(accessToken.AMR, ","))
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,130 @@
+package authcontroller
+
+import (
+	"auth/internal/controllers/auth-controller/mocks"
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	accessTokenSigned = "access-token"
+	accessToken       = &domain.AccessToken{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		RefreshTokenID: uuid.New(),
+		SessionID:      uuid.New(),
+		AuthTime:       time.Unix(1700000000, 0),
+		ACR:            domain.ACRStepUp,
+		AMR:            []string{domain.AMRPassword, domain.AMROTP, domain.AMRMFA},
+	}
+)
+
+func newEngineAndMock(t *testing.T) (*gin.Engine, *mocks.AuthService) {
+	gin.SetMode(gin.TestMode)
+	authService := mocks.NewAuthService(t)
+	engine := gin.New()
+	NewAuthController(authService, httputils.TrustedProxies{}).RegisterRoutes(engine)
+	return engine, authService
+}
+
+func serveVerifyRequest(engine *gin.Engine, query string, authorization string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/auth/verify"+query, nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	return w
+}
+
+func TestVerifyRequest_Success(t *testing.T) {
+	engine, authService := newEngineAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(accessToken, nil)
+
+	w := serveVerifyRequest(engine, "", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, accessToken.UserID.String(), w.Header().Get("X-User-ID"))
+	assert.Equal(t, accessToken.SessionID.String(), w.Header().Get("X-Session-ID"))
+	assert.Equal(t, accessToken.ID.String(), w.Header().Get("X-Token-ID"))
+	assert.Equal(t, "1700000000", w.Header().Get("X-Auth-Time"))
+	assert.Equal(t, domain.ACRStepUp, w.Header().Get("X-ACR"))
+	assert.Equal(t, "pwd,otp,mfa", w.Header().Get("X-AMR"))
+}
+
+func TestVerifyRequest_UnknownAuthContext(t *testing.T) {
+	engine, authService := newEngineAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(&domain.AccessToken{UserID: accessToken.UserID, SessionID: accessToken.SessionID}, nil)
+
+	w := serveVerifyRequest(engine, "", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, name := range []string{"X-Token-ID", "X-Auth-Time", "X-ACR", "X-AMR"} {
+		assert.NotContains(t, w.Header(), name)
+	}
+}
+
+func TestVerifyRequest_MissingToken(t *testing.T) {
+	engine, _ := newEngineAndMock(t)
+
+	w := serveVerifyRequest(engine, "", "Basic dXNlcjpwYXNz")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+}
+
+func TestVerifyRequest_InvalidToken(t *testing.T) {
+	engine, authService := newEngineAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(nil, &domain.UnauthorizedError{Message: "token is revoked"})
+
+	w := serveVerifyRequest(engine, "", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestVerifyRequest_ValidationFailed(t *testing.T) {
+	engine, authService := newEngineAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(nil, errors.New("db is down"))
+
+	w := serveVerifyRequest(engine, "", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestVerifyRequest_ReauthenticationRequired(t *testing.T) {
+	engine, authService := newEngineAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(accessToken, nil)
+	authService.On("RequireRecentAuthentication", accessToken, 5*time.Minute).
+		Return(&domain.ReauthenticationRequiredError{
+			AuthTime: accessToken.AuthTime, MaxAge: 5 * time.Minute})
+
+	w := serveVerifyRequest(engine, "?maxAge=300", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer error="insufficient_user_authentication", max_age=300`,
+		w.Header().Get("WWW-Authenticate"))
+	assert.Empty(t, w.Header().Get("X-User-ID"))
+}
+
+func TestVerifyRequest_InvalidMaxAge(t *testing.T) {
+	engine, _ := newEngineAndMock(t)
+
+	w := serveVerifyRequest(engine, "?maxAge=-1", "Bearer "+accessTokenSigned)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
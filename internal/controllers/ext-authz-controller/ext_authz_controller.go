@@ -0,0 +1,118 @@
+package extauthzcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExtAuthzController implements Envoy external authorization API. Allowed
+// requests get identity headers, overwriting ones sent by the client.
+type ExtAuthzController struct {
+	authv3.UnimplementedAuthorizationServer
+	authService AuthService
+}
+
+//go:generate mockery --name AuthService --filename auth_service.go
+type AuthService interface {
+	ValidateAccessToken(accessTokenSigned []byte) (*domain.AccessToken, error)
+}
+
+func NewExtAuthzController(authService AuthService) *ExtAuthzController {
+	return &ExtAuthzController{
+		authService: authService,
+	}
+}
+
+func (c *ExtAuthzController) Register(server *grpc.Server) {
+	authv3.RegisterAuthorizationServer(server, c)
+}
+
+func (c *ExtAuthzController) Check(
+	_ context.Context, req *authv3.CheckRequest,
+) (*authv3.CheckResponse, error) {
+
+	// Envoy passes header names in lower case
+	authorization := req.GetAttributes().GetRequest().GetHttp().GetHeaders()["authorization"]
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return newDeniedResponse("Bearer", "bearer token is required"), nil
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	accessToken, err := c.authService.ValidateAccessToken([]byte(strings.TrimSpace(token)))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		return newDeniedResponse(`Bearer error="invalid_token"`, err.Error()), nil
+	default:
+		slogutils.Error("check request", err)
+		// Envoy applies its failure_mode_allow setting to errors
+		return nil, status.Error(codes.Internal, "validate access token")
+	}
+
+	headers := []*corev3.HeaderValueOption{
+		newHeader(httputils.UserIDHeaderName, accessToken.UserID.String()),
+		newHeader(httputils.SessionIDHeaderName, accessToken.SessionID.String()),
+	}
+	// an empty value still overwrites the header sent by the client
+	tokenID := ""
+	if accessToken.ID != uuid.Nil {
+		tokenID = accessToken.ID.String()
+	}
+	headers = append(headers, newHeader(httputils.TokenIDHeaderName, tokenID))
+	authTime := ""
+	if !accessToken.AuthTime.IsZero() {
+		authTime = strconv.FormatInt(accessToken.AuthTime.Unix(), 10)
+	}
+	headers = append(headers,
+		newHeader(httputils.AuthTimeHeaderName, authTime),
+		newHeader(httputils.ACRHeaderName, accessToken.ACR),
+		newHeader(httputils.AMRHeaderName, strings.Join(accessToken.AMR, ",")))
+
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{Headers: headers},
+		},
+	}, nil
+}
+
+func newDeniedResponse(wwwAuthenticate, message string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{
+			Code:    int32(codes.Unauthenticated),
+			Message: message,
+		},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{Code: typev3.StatusCode_Unauthorized},
+				Headers: []*corev3.HeaderValueOption{
+					newHeader("WWW-Authenticate", wwwAuthenticate),
+				},
+				Body: http.StatusText(http.StatusUnauthorized),
+			},
+		},
+	}
+}
+
+func newHeader(key, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
@@ -0,0 +1,139 @@
+package extauthzcontroller
+
+import (
+	"auth/internal/controllers/ext-authz-controller/mocks"
+	"auth/internal/domain"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	accessTokenSigned = "access-token"
+	accessToken       = &domain.AccessToken{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		RefreshTokenID: uuid.New(),
+		SessionID:      uuid.New(),
+		AuthTime:       time.Unix(1700000000, 0),
+		ACR:            domain.ACRStepUp,
+		AMR:            []string{domain.AMRPassword, domain.AMROTP, domain.AMRMFA},
+	}
+)
+
+func newClientAndMock(t *testing.T) (authv3.AuthorizationClient, *mocks.AuthService) {
+	authService := mocks.NewAuthService(t)
+	server := grpc.NewServer()
+	NewExtAuthzController(authService).Register(server)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return authv3.NewAuthorizationClient(conn), authService
+}
+
+func newCheckRequest(headers map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{Headers: headers},
+			},
+		},
+	}
+}
+
+func headersMap(t *testing.T, headers []*corev3.HeaderValueOption) map[string]string {
+	m := make(map[string]string)
+	for _, header := range headers {
+		assert.Equal(t, corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD, header.AppendAction)
+		m[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+	}
+	return m
+}
+
+func TestCheckAllowed(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(accessToken, nil)
+
+	resp, err := client.Check(context.Background(), newCheckRequest(map[string]string{
+		"authorization": "Bearer " + accessTokenSigned,
+		"x-user-id":     uuid.NewString(),
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+	assert.Equal(t, map[string]string{
+		"X-User-ID":    accessToken.UserID.String(),
+		"X-Session-ID": accessToken.SessionID.String(),
+		"X-Token-ID":   accessToken.ID.String(),
+		"X-Auth-Time":  "1700000000",
+		"X-ACR":        domain.ACRStepUp,
+		"X-AMR":        "pwd,otp,mfa",
+	}, headersMap(t, resp.GetOkResponse().GetHeaders()))
+}
+
+func TestCheckMissingToken(t *testing.T) {
+	client, _ := newClientAndMock(t)
+
+	resp, err := client.Check(context.Background(), newCheckRequest(map[string]string{
+		"authorization": "Basic dXNlcjpwYXNz",
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.Unauthenticated), resp.GetStatus().GetCode())
+	assert.Equal(t, typev3.StatusCode_Unauthorized,
+		resp.GetDeniedResponse().GetStatus().GetCode())
+}
+
+func TestCheckInvalidToken(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(nil, &domain.UnauthorizedError{Message: "token is revoked"})
+
+	resp, err := client.Check(context.Background(), newCheckRequest(map[string]string{
+		"authorization": "Bearer " + accessTokenSigned,
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.Unauthenticated), resp.GetStatus().GetCode())
+	assert.Equal(t, typev3.StatusCode_Unauthorized,
+		resp.GetDeniedResponse().GetStatus().GetCode())
+	assert.Equal(t, `Bearer error="invalid_token"`,
+		headersMap(t, resp.GetDeniedResponse().GetHeaders())["WWW-Authenticate"])
+}
+
+func TestCheckValidationFailed(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("ValidateAccessToken", []byte(accessTokenSigned)).
+		Return(nil, errors.New("db is down"))
+
+	_, err := client.Check(context.Background(), newCheckRequest(map[string]string{
+		"authorization": "Bearer " + accessTokenSigned,
+	}))
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -0,0 +1,63 @@
+package grpcutils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const requestIDMetadataKey = "x-request-id"
+
+type loggerContextKey struct{}
+
+// Logger returns the request logger set by RequestIDInterceptor.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// RequestIDInterceptor takes request ID from "x-request-id" metadata or
+// generates one, returns it in response header and adds it to the request
+// logger.
+func RequestIDInterceptor(
+	ctx context.Context, req any,
+	_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = ksuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+
+	logger := slog.Default().With("requestID", requestID)
+	return handler(context.WithValue(ctx, loggerContextKey{}, logger), req)
+}
+
+// LoggingInterceptor logs every call with its status code and duration.
+func LoggingInterceptor(
+	ctx context.Context, req any,
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	Logger(ctx).Info("grpc call",
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start))
+
+	return resp, err
+}
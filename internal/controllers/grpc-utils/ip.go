@@ -0,0 +1,31 @@
+package grpcutils
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// GetRequestIP returns the client IP of the call. Like for HTTP requests,
+// metadata of the trusted proxies header is taken into account only when the
+// peer is a trusted proxy.
+func GetRequestIP(ctx context.Context, trustedProxies httputils.TrustedProxies) string {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+
+	header := make(http.Header)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+	}
+
+	return httputils.GetClientIP(remoteAddr, header, trustedProxies)
+}
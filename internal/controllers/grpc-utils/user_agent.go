@@ -0,0 +1,20 @@
+package grpcutils
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// GetUserAgent returns the "user-agent" metadata of the call.
+func GetUserAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("user-agent"); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,15 @@
+package httputils
+
+import "strings"
+
+// BearerToken returns the token of the "Authorization: Bearer <token>"
+// header value.
+func BearerToken(authorizationHeader string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorizationHeader, " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
@@ -1,5 +1,18 @@
 package httputils
 
+const (
+	ErrorCodeIPNotAllowed      = "ip_not_allowed"
+	ErrorCodeChallengeRequired = "challenge_required"
+	ErrorCodeMFALocked         = "mfa_locked"
+	// ErrorCodeSessionLimitReached is returned if the user has the maximum
+	// number of sessions.
+	ErrorCodeSessionLimitReached = "session_limit_reached"
+	// ErrorCodeReauthenticationRequired is the RFC 9470 error of tokens
+	// whose authentication is too old.
+	ErrorCodeReauthenticationRequired = "insufficient_user_authentication"
+)
+
 type HTTPError struct {
 	Message string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
 }
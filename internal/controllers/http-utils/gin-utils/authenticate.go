@@ -0,0 +1,78 @@
+package ginutils
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const accessTokenContextKey = "accessToken"
+
+type AccessTokenValidator interface {
+	ValidateAccessToken(accessTokenSigned []byte) (*domain.AccessToken, error)
+}
+
+// Authenticate returns a middleware which requires a valid bearer access
+// token. Handlers get the token with AccessToken.
+func Authenticate(validator AccessTokenValidator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := httputils.BearerToken(ctx.GetHeader("Authorization"))
+		if !ok {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			UnauthorizedError(ctx, errors.New("bearer token is required"))
+			ctx.Abort()
+			return
+		}
+
+		var unauthorizedError *domain.UnauthorizedError
+		accessToken, err := validator.ValidateAccessToken([]byte(token))
+		switch {
+		case err == nil:
+		case errors.As(err, &unauthorizedError):
+			ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			UnauthorizedError(ctx, err)
+			ctx.Abort()
+			return
+		default:
+			slogutils.Error("validate access token", err)
+			InternalError(ctx)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(accessTokenContextKey, accessToken)
+		ctx.Next()
+	}
+}
+
+// AccessToken returns the access token of the request authenticated by
+// Authenticate.
+func AccessToken(ctx *gin.Context) *domain.AccessToken {
+	return ctx.MustGet(accessTokenContextKey).(*domain.AccessToken)
+}
+
+// RequireAPIToken returns a middleware which requires the static API token
+// as the bearer token, for endpoints called by operators rather than users.
+func RequireAPIToken(apiToken string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := httputils.BearerToken(ctx.GetHeader("Authorization"))
+		if !ok {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			UnauthorizedError(ctx, errors.New("bearer token is required"))
+			ctx.Abort()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiToken)) != 1 {
+			ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			UnauthorizedError(ctx, errors.New("api token is invalid"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
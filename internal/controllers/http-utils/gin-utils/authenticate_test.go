@@ -0,0 +1,38 @@
+package ginutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAPIToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/admin", RequireAPIToken("admin-token"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := map[string]struct {
+		authorization string
+		status        int
+	}{
+		"valid":   {"Bearer admin-token", http.StatusNoContent},
+		"invalid": {"Bearer other-token", http.StatusUnauthorized},
+		"missing": {"", http.StatusUnauthorized},
+		"basic":   {"Basic YWRtaW4tdG9rZW4=", http.StatusUnauthorized},
+	}
+	for name, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+
+		assert.Equal(t, test.status, w.Code, name)
+	}
+}
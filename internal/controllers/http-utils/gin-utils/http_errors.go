@@ -31,3 +31,15 @@ func InternalError(ctx *gin.Context) {
 func UnauthorizedError(ctx *gin.Context, err error) {
 	Error(ctx, http.StatusUnauthorized, err)
 }
+
+func NotFoundError(ctx *gin.Context, err error) {
+	Error(ctx, http.StatusNotFound, err)
+}
+
+func ConflictError(ctx *gin.Context, err error) {
+	Error(ctx, http.StatusConflict, err)
+}
+
+func ForbiddenError(ctx *gin.Context, code string, err error) {
+	ctx.JSON(http.StatusForbidden, httputils.HTTPError{Message: err.Error(), Code: code})
+}
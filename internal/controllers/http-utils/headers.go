@@ -0,0 +1,13 @@
+package httputils
+
+// Identity headers set for upstreams by forward authentication.
+const (
+	UserIDHeaderName    = "X-User-ID"
+	SessionIDHeaderName = "X-Session-ID"
+	TokenIDHeaderName   = "X-Token-ID"
+	// AuthTimeHeaderName is the authentication time in Unix seconds.
+	AuthTimeHeaderName = "X-Auth-Time"
+	ACRHeaderName      = "X-ACR"
+	// AMRHeaderName lists the authentication methods separated by commas.
+	AMRHeaderName = "X-AMR"
+)
@@ -1,11 +1,138 @@
 package httputils
 
 import (
+	iputils "auth/internal/utils/ip-utils"
+	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
-func GetRequestIP(r *http.Request) string {
-	split := strings.Split(r.RemoteAddr, ":")
-	return strings.Join(split[:len(split)-1], ":")
+// Client address headers that can be set by trusted proxies.
+const (
+	ForwardedHeader     = "Forwarded"
+	XForwardedForHeader = "X-Forwarded-For"
+	XRealIPHeader       = "X-Real-IP"
+)
+
+// TrustedProxies is a list of networks whose client address header is
+// trusted. Only the header the proxies set is read, the others can be passed
+// through from the client unchanged and are ignored.
+type TrustedProxies struct {
+	Networks []netip.Prefix
+	Header   string
+}
+
+func ParseTrustedProxies(cidrs []string, header string) (TrustedProxies, error) {
+	header = http.CanonicalHeaderKey(header)
+	switch header {
+	case ForwardedHeader, XForwardedForHeader, http.CanonicalHeaderKey(XRealIPHeader):
+	default:
+		return TrustedProxies{}, fmt.Errorf("unsupported client address header %q", header)
+	}
+	networks, err := iputils.ParsePrefixes(cidrs)
+	if err != nil {
+		return TrustedProxies{}, err
+	}
+	return TrustedProxies{Networks: networks, Header: header}, nil
+}
+
+func (p TrustedProxies) trusts(addr netip.Addr) bool {
+	return iputils.ContainsAddr(p.Networks, addr)
+}
+
+// GetRequestIP returns the client IP of the request in canonical form.
+// Client address headers are taken into account only when the request comes
+// from a trusted proxy, in which case the forwarding chain of the configured
+// header is walked from the nearest hop until the first untrusted address.
+func GetRequestIP(r *http.Request, trustedProxies TrustedProxies) string {
+	return GetClientIP(r.RemoteAddr, r.Header, trustedProxies)
+}
+
+// GetClientIP is GetRequestIP for transports other than net/http, e.g. gRPC
+// with metadata converted to header.
+func GetClientIP(remoteAddr string, header http.Header, trustedProxies TrustedProxies) string {
+	remoteIP, err := parseRemoteAddr(remoteAddr)
+	if err != nil {
+		return iputils.Normalize(remoteAddr)
+	}
+	if !trustedProxies.trusts(remoteIP) {
+		return remoteIP.String()
+	}
+
+	var forwardedFor []string
+	switch trustedProxies.Header {
+	case ForwardedHeader:
+		forwardedFor = parseForwardedHeader(header.Values(ForwardedHeader))
+	case XForwardedForHeader:
+		forwardedFor = parseXForwardedForHeader(header.Values(XForwardedForHeader))
+	case http.CanonicalHeaderKey(XRealIPHeader):
+		if value := header.Get(XRealIPHeader); value != "" {
+			forwardedFor = []string{value}
+		}
+	}
+
+	clientAddr := remoteIP
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		addr, err := parseForwardedAddr(forwardedFor[i])
+		if err != nil {
+			break
+		}
+		clientAddr = addr
+		if !trustedProxies.trusts(addr) {
+			break
+		}
+	}
+
+	return clientAddr.String()
+}
+
+func parseRemoteAddr(remoteAddr string) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return iputils.ParseAddr(host)
+}
+
+func parseXForwardedForHeader(values []string) []string {
+	var addrs []string
+	for _, value := range values {
+		for _, addr := range strings.Split(value, ",") {
+			addrs = append(addrs, strings.TrimSpace(addr))
+		}
+	}
+	return addrs
+}
+
+// parseForwardedHeader extracts "for" parameters of RFC 7239 Forwarded header
+// elements. Elements without "for" parameter are kept as empty strings so that
+// the hops count is preserved.
+func parseForwardedHeader(values []string) []string {
+	var addrs []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var addr string
+			for _, pair := range strings.Split(element, ";") {
+				name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(name, "for") {
+					addr = strings.Trim(value, `"`)
+				}
+			}
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// parseForwardedAddr parses an address from X-Forwarded-For, X-Real-IP or
+// Forwarded "for" parameter. Addresses can contain port and IPv6 addresses
+// can be enclosed in square brackets.
+func parseForwardedAddr(addr string) (netip.Addr, error) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	return iputils.ParseAddr(addr)
 }
@@ -0,0 +1,99 @@
+package httputils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var trustedProxies = mustParseTrustedProxies(XForwardedForHeader, "10.0.0.0/8", "fd00::/8")
+
+func TestGetRequestIP_UntrustedRemoteAddrIgnoresHeaders(t *testing.T) {
+	r := newRequest("203.0.113.7:5555", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+		"X-Real-IP":       "198.51.100.2",
+		"Forwarded":       "for=198.51.100.3",
+	})
+
+	assert.Equal(t, "203.0.113.7", GetRequestIP(r, trustedProxies))
+}
+
+func TestGetRequestIP_IgnoresNotConfiguredHeaders(t *testing.T) {
+	r := newRequest("10.0.0.1:5555", map[string]string{
+		"X-Real-IP": "198.51.100.2",
+		"Forwarded": "for=198.51.100.3",
+	})
+
+	assert.Equal(t, "10.0.0.1", GetRequestIP(r, trustedProxies))
+}
+
+func TestGetRequestIP_XForwardedFor(t *testing.T) {
+	r := newRequest("10.0.0.1:5555", map[string]string{
+		"X-Forwarded-For": "198.51.100.9, 203.0.113.7, 10.1.1.1",
+	})
+
+	assert.Equal(t, "203.0.113.7", GetRequestIP(r, trustedProxies))
+}
+
+func TestGetRequestIP_XForwardedForAllTrusted(t *testing.T) {
+	r := newRequest("10.0.0.1:5555", map[string]string{
+		"X-Forwarded-For": "10.2.2.2, 10.1.1.1",
+	})
+
+	assert.Equal(t, "10.2.2.2", GetRequestIP(r, trustedProxies))
+}
+
+func TestGetRequestIP_XForwardedForInvalidHop(t *testing.T) {
+	r := newRequest("10.0.0.1:5555", map[string]string{
+		"X-Forwarded-For": "198.51.100.9, garbage, 10.1.1.1",
+	})
+
+	assert.Equal(t, "10.1.1.1", GetRequestIP(r, trustedProxies))
+}
+
+func TestGetRequestIP_XRealIP(t *testing.T) {
+	r := newRequest("10.0.0.1:5555", map[string]string{
+		"X-Real-IP": "203.0.113.7",
+	})
+
+	assert.Equal(t, "203.0.113.7",
+		GetRequestIP(r, mustParseTrustedProxies("x-real-ip", "10.0.0.0/8")))
+}
+
+func TestGetRequestIP_Forwarded(t *testing.T) {
+	r := newRequest("[fd00::1]:5555", map[string]string{
+		"Forwarded": `for="[2001:DB8:0:0::17]:4711";proto=https, for=10.1.1.1;by=10.0.0.1`,
+	})
+
+	assert.Equal(t, "2001:db8::17",
+		GetRequestIP(r, mustParseTrustedProxies(ForwardedHeader, "10.0.0.0/8", "fd00::/8")))
+}
+
+func TestGetRequestIP_NormalizesIPv6(t *testing.T) {
+	assert.Equal(t, "2001:db8::1",
+		GetRequestIP(newRequest("[2001:0DB8:0000::1%eth0]:80", nil), TrustedProxies{}))
+	assert.Equal(t, "192.0.2.1",
+		GetRequestIP(newRequest("[::ffff:192.0.2.1]:80", nil), TrustedProxies{}))
+}
+
+func newRequest(remoteAddr string, headers map[string]string) *http.Request {
+	r := &http.Request{RemoteAddr: remoteAddr, Header: http.Header{}}
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+func TestParseTrustedProxies_UnsupportedHeader(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/8"}, "X-Client-IP")
+	assert.Error(t, err)
+}
+
+func mustParseTrustedProxies(header string, cidrs ...string) TrustedProxies {
+	trustedProxies, err := ParseTrustedProxies(cidrs, header)
+	if err != nil {
+		panic(err)
+	}
+	return trustedProxies
+}
@@ -0,0 +1,76 @@
+package magiclinkcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type exchangeLinkRequestBody struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type exchangeLinkResponseBody struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// @Summary		Exchange magic link
+// @Description	Create a session with the token of a magic link. The request must carry the nonce cookie
+// @Description	set when the link was requested, the link is used up either way.
+// @Tags			magic-link
+// @Accept			json
+// @Produce		json
+// @Param			token	body		exchangeLinkRequestBody		yes	"Token of the link"
+// @Success		201		{object}	exchangeLinkResponseBody	"Success"
+// @Failure		400		{object}	httputils.HTTPError			"Bad request"
+// @Failure		401		{object}	httputils.HTTPError			"Link is invalid, expired or requested from another browser"
+// @Failure		403		{object}	httputils.HTTPError			"IP address is not allowed or the user has the maximum number of sessions"
+// @Failure		500		{object}	httputils.HTTPError			"Internal server error"
+// @Router			/magic-link/callback [post]
+func (controller *MagicLinkController) exchangeLink(c *gin.Context) {
+	var reqBody exchangeLinkRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+	// a missing cookie is checked by the service like a wrong one, so the
+	// link is used up
+	nonce, _ := c.Cookie(nonceCookieName)
+
+	var unauthorizedError *domain.UnauthorizedError
+	var ipNotAllowedError *domain.IPNotAllowedError
+	var sessionLimitReachedError *domain.SessionLimitReachedError
+	session, err := controller.magicLinkService.ExchangeLink(
+		reqBody.Token, nonce,
+		httputils.GetRequestIP(c.Request, controller.trustedProxies))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		ginutils.UnauthorizedError(c, err)
+		return
+	case errors.As(err, &ipNotAllowedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeIPNotAllowed, err)
+		return
+	case errors.As(err, &sessionLimitReachedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeSessionLimitReached, err)
+		return
+	default:
+		slogutils.Error("exchange magic link", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	controller.setNonceCookie(c, "", -1)
+	c.JSON(http.StatusCreated, exchangeLinkResponseBody{
+		AccessToken: string(session.AccessTokenSigned),
+		RefreshToken: base64.StdEncoding.
+			EncodeToString(session.RefreshTokenValue),
+	})
+}
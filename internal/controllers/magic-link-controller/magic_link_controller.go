@@ -0,0 +1,79 @@
+package magiclinkcontroller
+
+import (
+	"auth/internal/config"
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	clientIDParamName = "clientID"
+	nonceCookieName   = "magic_link_nonce"
+	nonceCookiePath   = "/magic-link"
+)
+
+// MagicLinkController serves passwordless logins. Requesting a link sets a
+// nonce cookie, which the callback requires, so both must be called from the
+// same browser with credentials.
+type MagicLinkController struct {
+	cfg              config.MagicLinkConfig
+	cookieSameSite   http.SameSite
+	magicLinkService MagicLinkService
+	trustedProxies   httputils.TrustedProxies
+}
+
+type MagicLinkService interface {
+	RequestLink(email, clientID, requestIP, userAgent string) (string, error)
+	ExchangeLink(token, nonce, requestIP string) (*domain.Session, error)
+}
+
+func NewMagicLinkController(
+	cfg config.MagicLinkConfig,
+	magicLinkService MagicLinkService,
+	trustedProxies httputils.TrustedProxies,
+) (*MagicLinkController, error) {
+
+	var cookieSameSite http.SameSite
+	switch strings.ToLower(cfg.CookieSameSite) {
+	case "strict":
+		cookieSameSite = http.SameSiteStrictMode
+	case "lax":
+		cookieSameSite = http.SameSiteLaxMode
+	case "none":
+		cookieSameSite = http.SameSiteNoneMode
+	default:
+		return nil, errors.Errorf("unknown cookie same site mode %q", cfg.CookieSameSite)
+	}
+
+	return &MagicLinkController{
+		cfg:              cfg,
+		cookieSameSite:   cookieSameSite,
+		magicLinkService: magicLinkService,
+		trustedProxies:   trustedProxies,
+	}, nil
+}
+
+func (c *MagicLinkController) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("magic-link")
+	group.POST("", c.requestLink)
+	group.POST("/callback", c.exchangeLink)
+}
+
+// setNonceCookie sets the nonce cookie, maxAge < 0 deletes it.
+func (c *MagicLinkController) setNonceCookie(ctx *gin.Context, nonce string, maxAge int) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     nonceCookieName,
+		Value:    nonce,
+		Path:     nonceCookiePath,
+		Domain:   c.cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: c.cookieSameSite,
+	})
+}
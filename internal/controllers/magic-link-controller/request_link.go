@@ -0,0 +1,47 @@
+package magiclinkcontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type requestLinkRequestBody struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// @Summary		Request magic link
+// @Description	Email a single-use login link to the user and set the nonce cookie the link is bound to.
+// @Description	The response is the same whether the email is registered or not.
+// @Tags			magic-link
+// @Accept			json
+// @Param			clientID	query	string					no	"Client ID"
+// @Param			email		body	requestLinkRequestBody	yes	"Email of the user"
+// @Success		202			"Accepted"
+// @Failure		400			{object}	httputils.HTTPError	"Bad request"
+// @Failure		500			{object}	httputils.HTTPError	"Internal server error"
+// @Router			/magic-link [post]
+func (controller *MagicLinkController) requestLink(c *gin.Context) {
+	var reqBody requestLinkRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	nonce, err := controller.magicLinkService.RequestLink(
+		reqBody.Email,
+		c.Query(clientIDParamName),
+		httputils.GetRequestIP(c.Request, controller.trustedProxies),
+		c.Request.UserAgent())
+	if err != nil {
+		slogutils.Error("request magic link", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	controller.setNonceCookie(c, nonce, int(controller.cfg.Duration.Seconds()))
+	c.Status(http.StatusAccepted)
+}
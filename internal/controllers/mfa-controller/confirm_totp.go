@@ -0,0 +1,56 @@
+package mfacontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type confirmTOTPRequestBody struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type confirmTOTPResponseBody RecoveryCodesDTO
+
+// @Summary		Confirm TOTP
+// @Description	Confirm the enrolled TOTP secret with a code of the authenticator app.
+// @Description	Recovery codes are returned once, each of them can replace a TOTP code once.
+// @Tags			mfa
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					yes	"Bearer access token"
+// @Param			code			body		confirmTOTPRequestBody	yes	"TOTP code"
+// @Success		200				{object}	confirmTOTPResponseBody	"Success"
+// @Failure		400				{object}	httputils.HTTPError		"Bad request"
+// @Failure		401				{object}	httputils.HTTPError		"Unauthorized or code is invalid"
+// @Failure		403				{object}	httputils.HTTPError		"Verification is locked"
+// @Failure		404				{object}	httputils.HTTPError		"TOTP is not enrolled"
+// @Failure		409				{object}	httputils.HTTPError		"TOTP is already confirmed"
+// @Failure		500				{object}	httputils.HTTPError		"Internal server error"
+// @Router			/mfa/totp/confirm [post]
+func (controller *MFAController) confirmTOTP(c *gin.Context) {
+	var reqBody confirmTOTPRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	var conflictError *domain.ConflictError
+	recoveryCodes, err := controller.mfaService.ConfirmTOTP(
+		ginutils.AccessToken(c).UserID, reqBody.Code)
+	switch {
+	case err == nil:
+	case errors.As(err, &conflictError):
+		ginutils.ConflictError(c, err)
+		return
+	default:
+		secondFactorError(c, "confirm totp", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, confirmTOTPResponseBody{RecoveryCodes: recoveryCodes})
+}
@@ -0,0 +1,25 @@
+package mfacontroller
+
+import "auth/internal/domain"
+
+// SecondFactorDTO holds either a TOTP code or a recovery code.
+type SecondFactorDTO struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+func (dto SecondFactorDTO) toDomain() domain.SecondFactor {
+	return domain.SecondFactor{
+		TOTPCode:     dto.Code,
+		RecoveryCode: dto.RecoveryCode,
+	}
+}
+
+// StepUpTicketDTO is passed to session creation to step up the session.
+type StepUpTicketDTO struct {
+	StepUpTicket string `json:"stepUpTicket"`
+}
+
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -0,0 +1,48 @@
+package mfacontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type enrollTOTPResponseBody struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// @Summary		Enroll TOTP
+// @Description	Generate a TOTP secret for an authenticator app. The otpauth URI is usually shown as a QR code.
+// @Description	The secret is used after it is confirmed with a code. Enrolling again replaces a not confirmed secret.
+// @Tags			mfa
+// @Produce		json
+// @Param			Authorization	header		string					yes	"Bearer access token"
+// @Success		201				{object}	enrollTOTPResponseBody	"Success"
+// @Failure		401				{object}	httputils.HTTPError		"Unauthorized"
+// @Failure		409				{object}	httputils.HTTPError		"TOTP is already enrolled"
+// @Failure		500				{object}	httputils.HTTPError		"Internal server error"
+// @Router			/mfa/totp/enroll [post]
+func (controller *MFAController) enrollTOTP(c *gin.Context) {
+	var conflictError *domain.ConflictError
+	enrollment, err := controller.mfaService.EnrollTOTP(ginutils.AccessToken(c).UserID)
+	switch {
+	case err == nil:
+	case errors.As(err, &conflictError):
+		ginutils.ConflictError(c, err)
+		return
+	default:
+		slogutils.Error("enroll totp", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, enrollTOTPResponseBody{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.OTPAuthURI,
+	})
+}
@@ -0,0 +1,71 @@
+package mfacontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const userIDParamName = "userID"
+
+// MFAController serves second factor enrollment and step-up. Users manage
+// their factors with access tokens, the backend which creates sessions
+// steps up users by ID.
+type MFAController struct {
+	authService AuthService
+	mfaService  MFAService
+}
+
+type AuthService interface {
+	ginutils.AccessTokenValidator
+}
+
+type MFAService interface {
+	EnrollTOTP(userID uuid.UUID) (*domain.TOTPEnrollment, error)
+	ConfirmTOTP(userID uuid.UUID, code string) ([]string, error)
+	StepUpSession(accessToken *domain.AccessToken, factor domain.SecondFactor) error
+	StepUpUser(userID uuid.UUID, factor domain.SecondFactor) (uuid.UUID, error)
+	RegenerateRecoveryCodes(userID uuid.UUID, totpCode string) ([]string, error)
+}
+
+func NewMFAController(authService AuthService, mfaService MFAService) *MFAController {
+	return &MFAController{
+		authService: authService,
+		mfaService:  mfaService,
+	}
+}
+
+func (c *MFAController) RegisterRoutes(engine *gin.Engine) {
+	mfaGroup := engine.Group("mfa", ginutils.Authenticate(c.authService))
+	mfaGroup.POST("/totp/enroll", c.enrollTOTP)
+	mfaGroup.POST("/totp/confirm", c.confirmTOTP)
+	mfaGroup.POST("/step-up", c.stepUpSession)
+	mfaGroup.POST("/recovery-codes", c.regenerateRecoveryCodes)
+
+	userGroup := engine.Group("users")
+	userGroup.POST("/:"+userIDParamName+"/step-up", c.stepUpUser)
+}
+
+// secondFactorError writes the response for errors of second factor
+// verification.
+func secondFactorError(c *gin.Context, msg string, err error) {
+	var unauthorizedError *domain.UnauthorizedError
+	var notFoundError *domain.NotFoundError
+	var mfaLockedError *domain.MFALockedError
+	switch {
+	case errors.As(err, &unauthorizedError):
+		ginutils.UnauthorizedError(c, err)
+	case errors.As(err, &notFoundError):
+		ginutils.NotFoundError(c, err)
+	case errors.As(err, &mfaLockedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeMFALocked, err)
+	default:
+		slogutils.Error(msg, err)
+		ginutils.InternalError(c)
+	}
+}
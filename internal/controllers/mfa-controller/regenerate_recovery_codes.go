@@ -0,0 +1,46 @@
+package mfacontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type regenerateRecoveryCodesRequestBody struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type regenerateRecoveryCodesResponseBody RecoveryCodesDTO
+
+// @Summary		Regenerate recovery codes
+// @Description	Replace recovery codes of the user given a TOTP code. Previous codes stop working.
+// @Tags			mfa
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string								yes	"Bearer access token"
+// @Param			code			body		regenerateRecoveryCodesRequestBody	yes	"TOTP code"
+// @Success		200				{object}	regenerateRecoveryCodesResponseBody	"Success"
+// @Failure		400				{object}	httputils.HTTPError					"Bad request"
+// @Failure		401				{object}	httputils.HTTPError					"Unauthorized or code is invalid"
+// @Failure		403				{object}	httputils.HTTPError					"Verification is locked"
+// @Failure		404				{object}	httputils.HTTPError					"TOTP is not enrolled"
+// @Failure		500				{object}	httputils.HTTPError					"Internal server error"
+// @Router			/mfa/recovery-codes [post]
+func (controller *MFAController) regenerateRecoveryCodes(c *gin.Context) {
+	var reqBody regenerateRecoveryCodesRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	recoveryCodes, err := controller.mfaService.RegenerateRecoveryCodes(
+		ginutils.AccessToken(c).UserID, reqBody.Code)
+	if err != nil {
+		secondFactorError(c, "regenerate recovery codes", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, regenerateRecoveryCodesResponseBody{RecoveryCodes: recoveryCodes})
+}
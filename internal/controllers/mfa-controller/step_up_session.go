@@ -0,0 +1,41 @@
+package mfacontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stepUpSessionRequestBody SecondFactorDTO
+
+// @Summary		Step up session
+// @Description	Verify a TOTP code or a recovery code and step up the session of the access token.
+// @Description	Access tokens returned by the next refresh of the session have "acr": "aal2" and the "amr" claim.
+// @Tags			mfa
+// @Accept			json
+// @Param			Authorization	header	string						yes	"Bearer access token"
+// @Param			factor			body	stepUpSessionRequestBody	yes	"TOTP code or recovery code"
+// @Success		204				"Success"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized or code is invalid"
+// @Failure		403				{object}	httputils.HTTPError	"Verification is locked"
+// @Failure		404				{object}	httputils.HTTPError	"TOTP is not enrolled"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/mfa/step-up [post]
+func (controller *MFAController) stepUpSession(c *gin.Context) {
+	var reqBody stepUpSessionRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	err := controller.mfaService.StepUpSession(
+		ginutils.AccessToken(c), SecondFactorDTO(reqBody).toDomain())
+	if err != nil {
+		secondFactorError(c, "step up session", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
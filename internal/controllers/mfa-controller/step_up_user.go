@@ -0,0 +1,50 @@
+package mfacontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type stepUpUserRequestBody SecondFactorDTO
+
+type stepUpUserResponseBody StepUpTicketDTO
+
+// @Summary		Step up user
+// @Description	Verify a TOTP code or a recovery code of the user before creating a session.
+// @Description	The session created for the user with the returned ticket within minutes has "acr": "aal2" and the "amr" claim.
+// @Tags			user
+// @Accept			json
+// @Produce		json
+// @Param			userID	path		string					yes	"User ID"
+// @Param			factor	body		stepUpUserRequestBody	yes	"TOTP code or recovery code"
+// @Success		200		{object}	stepUpUserResponseBody	"Success"
+// @Failure		400		{object}	httputils.HTTPError		"Bad request"
+// @Failure		401		{object}	httputils.HTTPError		"Code is invalid"
+// @Failure		403		{object}	httputils.HTTPError		"Verification is locked"
+// @Failure		404		{object}	httputils.HTTPError		"TOTP is not enrolled"
+// @Failure		500		{object}	httputils.HTTPError		"Internal server error"
+// @Router			/users/{userID}/step-up [post]
+func (controller *MFAController) stepUpUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param(userIDParamName))
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "parse userID"))
+		return
+	}
+	var reqBody stepUpUserRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	ticket, err := controller.mfaService.StepUpUser(userID, SecondFactorDTO(reqBody).toDomain())
+	if err != nil {
+		secondFactorError(c, "step up user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stepUpUserResponseBody{StepUpTicket: ticket.String()})
+}
@@ -0,0 +1,28 @@
+package notificationcontroller
+
+import (
+	"auth/internal/domain"
+	"time"
+)
+
+type NotificationDTO struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+}
+
+func newNotificationDTO(notification domain.InAppNotification) NotificationDTO {
+	dto := NotificationDTO{
+		ID:        notification.ID.String(),
+		Title:     notification.Title,
+		Body:      notification.Body,
+		CreatedAt: notification.CreationTime,
+	}
+	if !notification.ReadTime.IsZero() {
+		dto.ReadAt = &notification.ReadTime
+	}
+
+	return dto
+}
@@ -0,0 +1,58 @@
+package notificationcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultNotificationsLimit = 50
+
+type getNotificationsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type getNotificationsResponseBody struct {
+	Notifications []NotificationDTO `json:"notifications"`
+}
+
+// @Summary		List notifications
+// @Description	List in-app notifications of the user, most recent first.
+// @Tags			notification
+// @Produce		json
+// @Param			Authorization	header		string							yes	"Bearer access token"
+// @Param			limit			query		int								no	"Max number of notifications, 50 by default"
+// @Param			offset			query		int								no	"Number of notifications to skip"
+// @Success		200				{object}	getNotificationsResponseBody	"Success"
+// @Failure		400				{object}	httputils.HTTPError				"Bad request"
+// @Failure		401				{object}	httputils.HTTPError				"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError				"Internal server error"
+// @Router			/notifications [get]
+func (controller *NotificationController) getNotifications(c *gin.Context) {
+	var query getNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		ginutils.BindQueryError(c, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultNotificationsLimit
+	}
+
+	notifications, err := controller.notificationService.GetInAppNotifications(
+		ginutils.AccessToken(c).UserID, query.Limit, query.Offset)
+	if err != nil {
+		slogutils.Error("get in-app notifications", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	dtos := make([]NotificationDTO, 0, len(notifications))
+	for _, notification := range notifications {
+		dtos = append(dtos, newNotificationDTO(notification))
+	}
+
+	c.JSON(http.StatusOK, getNotificationsResponseBody{Notifications: dtos})
+}
@@ -0,0 +1,49 @@
+package notificationcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const notificationIDParamName = "notificationID"
+
+// @Summary		Mark notification read
+// @Description	Mark the in-app notification of the user as read.
+// @Tags			notification
+// @Param			Authorization	header	string	yes	"Bearer access token"
+// @Param			notificationID	path	string	yes	"Notification ID"
+// @Success		204				"Success"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		404				{object}	httputils.HTTPError	"Notification not found"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/notifications/{notificationID}/read [post]
+func (controller *NotificationController) markNotificationRead(c *gin.Context) {
+	notificationID, err := uuid.Parse(c.Param(notificationIDParamName))
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "parse notificationID"))
+		return
+	}
+
+	var notFoundError *domain.NotFoundError
+	err = controller.notificationService.MarkInAppNotificationRead(
+		ginutils.AccessToken(c).UserID, notificationID)
+	switch {
+	case err == nil:
+	case errors.As(err, &notFoundError):
+		ginutils.NotFoundError(c, err)
+		return
+	default:
+		slogutils.Error("mark in-app notification read", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
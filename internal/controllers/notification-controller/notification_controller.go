@@ -0,0 +1,42 @@
+package notificationcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationController serves the in-app notifications inbox to users
+// authenticated with access tokens.
+type NotificationController struct {
+	authService         AuthService
+	notificationService NotificationService
+}
+
+type AuthService interface {
+	ginutils.AccessTokenValidator
+}
+
+type NotificationService interface {
+	GetInAppNotifications(userID uuid.UUID, limit, offset int) ([]domain.InAppNotification, error)
+	MarkInAppNotificationRead(userID, id uuid.UUID) error
+}
+
+func NewNotificationController(
+	authService AuthService,
+	notificationService NotificationService,
+) *NotificationController {
+
+	return &NotificationController{
+		authService:         authService,
+		notificationService: notificationService,
+	}
+}
+
+func (c *NotificationController) RegisterRoutes(engine *gin.Engine) {
+	notificationGroup := engine.Group("notifications", ginutils.Authenticate(c.authService))
+	notificationGroup.GET("", c.getNotifications)
+	notificationGroup.POST("/:"+notificationIDParamName+"/read", c.markNotificationRead)
+}
@@ -0,0 +1,24 @@
+package revocationcontroller
+
+import (
+	"auth/internal/domain"
+	"time"
+)
+
+type RevocationDTO struct {
+	Seq       int64                   `json:"seq"`
+	Kind      domain.RevokedTokenKind `json:"kind"`
+	ID        string                  `json:"id"`
+	RevokedAt time.Time               `json:"revokedAt"`
+	ExpiresAt time.Time               `json:"expiresAt"`
+}
+
+func newRevocationDTO(revokedToken domain.RevokedToken) RevocationDTO {
+	return RevocationDTO{
+		Seq:       revokedToken.Seq,
+		Kind:      revokedToken.Kind,
+		ID:        revokedToken.ID.String(),
+		RevokedAt: revokedToken.RevocationTime,
+		ExpiresAt: revokedToken.ExpirationTime,
+	}
+}
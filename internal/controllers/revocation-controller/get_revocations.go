@@ -0,0 +1,42 @@
+package revocationcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getRevocationsResponseBody struct {
+	Cursor      int64           `json:"cursor"`
+	Revocations []RevocationDTO `json:"revocations"`
+}
+
+// @Summary		Get revocations snapshot
+// @Description	Get all revoked and not yet expired sessions ("sid" kind) and access tokens ("jti" kind).
+// @Description	"user" kind is the user's tokens watermark, access tokens of the user issued before its revokedAt are revoked.
+// @Description	Returned cursor should be passed to the revocations stream to receive revocations made after the snapshot.
+// @Tags			revocation
+// @Produce		json
+// @Success		200	{object}	getRevocationsResponseBody	"Success"
+// @Failure		500	{object}	httputils.HTTPError			"Internal server error"
+// @Router			/revocations [get]
+func (controller *RevocationController) getRevocations(c *gin.Context) {
+	revokedTokens, cursor, err := controller.revocationService.GetRevocationsSnapshot()
+	if err != nil {
+		slogutils.Error("get revocations snapshot", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	revocations := make([]RevocationDTO, 0, len(revokedTokens))
+	for _, revokedToken := range revokedTokens {
+		revocations = append(revocations, newRevocationDTO(revokedToken))
+	}
+
+	c.JSON(http.StatusOK, getRevocationsResponseBody{
+		Cursor:      cursor,
+		Revocations: revocations,
+	})
+}
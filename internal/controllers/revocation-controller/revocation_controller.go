@@ -0,0 +1,57 @@
+package revocationcontroller
+
+import (
+	"auth/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamHeartbeatPeriod = time.Second * 15
+	streamBatchSize       = 100
+)
+
+type RevocationController struct {
+	revocationService RevocationService
+
+	mu sync.Mutex
+	// revoked is closed and replaced on every NotifyRevocations call to wake
+	// up all open streams at once.
+	revoked chan struct{}
+}
+
+type RevocationService interface {
+	GetRevocationsSnapshot() (revokedTokens []domain.RevokedToken, cursor int64, err error)
+	GetRevocationsAfter(cursor int64, limit int) ([]domain.RevokedToken, error)
+}
+
+func NewRevocationController(revocationService RevocationService) *RevocationController {
+	return &RevocationController{
+		revocationService: revocationService,
+		revoked:           make(chan struct{}),
+	}
+}
+
+// NotifyRevocations wakes up open revocation streams to send revocations
+// made since their cursors.
+func (c *RevocationController) NotifyRevocations() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(c.revoked)
+	c.revoked = make(chan struct{})
+}
+
+// revocationSignal returns a channel closed on the next NotifyRevocations call.
+func (c *RevocationController) revocationSignal() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.revoked
+}
+
+func (c *RevocationController) RegisterRoutes(engine *gin.Engine) {
+	revocationGroup := engine.Group("revocations")
+	revocationGroup.GET("", c.getRevocations)
+	revocationGroup.GET("/stream", c.streamRevocations)
+}
@@ -0,0 +1,124 @@
+package revocationcontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const cursorParamName = "cursor"
+
+// @Summary		Stream revocations
+// @Description	Stream revocations made after the cursor as Server-Sent Events.
+// @Description	Each "revocation" event has the revocation seq as its ID and RevocationDTO as its data.
+// @Description	The cursor is taken from "cursor" query param or from "Last-Event-ID" header on reconnect.
+// @Description	Without the cursor only revocations made after the request are streamed.
+// @Description	Revocations which have expired since the cursor was taken may be skipped.
+// @Tags			revocation
+// @Produce		text/event-stream
+// @Param			cursor			query		int		no	"Seq of the last received revocation"
+// @Param			Last-Event-ID	header		string	no	"ID of the last received event"
+// @Success		200				{object}	RevocationDTO		"Stream of revocation events"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/revocations/stream [get]
+func (controller *RevocationController) streamRevocations(c *gin.Context) {
+	cursorStr := c.Query(cursorParamName)
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		cursorStr = lastEventID
+	}
+
+	var cursor int64
+	var err error
+	if cursorStr != "" {
+		cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+		if err != nil {
+			ginutils.BadRequest(c, errors.Wrap(err, "parse cursor"))
+			return
+		}
+	} else {
+		_, cursor, err = controller.revocationService.GetRevocationsSnapshot()
+		if err != nil {
+			slogutils.Error("get revocations cursor", err)
+			ginutils.InternalError(c)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// The signal is taken before each read, so revocations made during the
+	// read wake the stream up again instead of being missed.
+	revoked := controller.revocationSignal()
+	cursor, err = controller.writeRevocationEvents(c, cursor)
+	if err != nil {
+		slogutils.Error("stream revocations", err)
+		return
+	}
+
+	heartbeatTicker := time.NewTicker(streamHeartbeatPeriod)
+	defer heartbeatTicker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeatTicker.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-revoked:
+			revoked = controller.revocationSignal()
+			cursor, err = controller.writeRevocationEvents(c, cursor)
+			if err != nil {
+				slogutils.Error("stream revocations", err)
+				return
+			}
+		}
+	}
+}
+
+// writeRevocationEvents writes all revocations made after the cursor and
+// returns the new cursor.
+func (controller *RevocationController) writeRevocationEvents(
+	c *gin.Context, cursor int64,
+) (int64, error) {
+
+	for {
+		revokedTokens, err := controller.revocationService.
+			GetRevocationsAfter(cursor, streamBatchSize)
+		if err != nil {
+			return cursor, errors.Wrap(err, "get revocations")
+		}
+
+		for _, revokedToken := range revokedTokens {
+			data, err := json.Marshal(newRevocationDTO(revokedToken))
+			if err != nil {
+				return cursor, errors.Wrap(err, "marshal revocation")
+			}
+			_, err = fmt.Fprintf(c.Writer,
+				"id: %d\nevent: revocation\ndata: %s\n\n",
+				revokedToken.Seq, data)
+			if err != nil {
+				return cursor, errors.Wrap(err, "write event")
+			}
+			cursor = revokedToken.Seq
+		}
+		c.Writer.Flush()
+
+		if len(revokedTokens) < streamBatchSize {
+			return cursor, nil
+		}
+	}
+}
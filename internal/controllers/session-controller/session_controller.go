@@ -0,0 +1,216 @@
+package sessioncontroller
+
+import (
+	grpcutils "auth/internal/controllers/grpc-utils"
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/domain"
+	sessionv1 "auth/pkg/proto/session/v1"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SessionController implements SessionService gRPC API on top of the same
+// AuthService as the HTTP API.
+type SessionController struct {
+	sessionv1.UnimplementedSessionServiceServer
+	authService    AuthService
+	trustedProxies httputils.TrustedProxies
+}
+
+// errorInfoDomain is the domain of google.rpc.ErrorInfo details.
+const errorInfoDomain = "auth"
+
+//go:generate mockery --name AuthService --filename auth_service.go
+type AuthService interface {
+	CreateSession(
+		userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
+	) (*domain.Session, error)
+	RefreshSession(session *domain.Session, requestIP, userAgent string) (*domain.Session, error)
+	CompleteRefreshChallenge(
+		session *domain.Session, challengeID uuid.UUID, code string,
+		requestIP, userAgent string,
+	) (*domain.Session, error)
+	RevokeSession(accessTokenSigned []byte, requestIP string) error
+	ListSessions(userID uuid.UUID) ([]domain.RefreshToken, error)
+}
+
+func NewSessionController(
+	authService AuthService,
+	trustedProxies httputils.TrustedProxies,
+) *SessionController {
+
+	return &SessionController{
+		authService:    authService,
+		trustedProxies: trustedProxies,
+	}
+}
+
+func (c *SessionController) Register(server *grpc.Server) {
+	sessionv1.RegisterSessionServiceServer(server, c)
+}
+
+func (c *SessionController) CreateSession(
+	ctx context.Context, req *sessionv1.CreateSessionRequest,
+) (*sessionv1.CreateSessionResponse, error) {
+
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "parse user_id").Error())
+	}
+	stepUpTicket := uuid.Nil
+	if req.GetStepUpTicket() != "" {
+		stepUpTicket, err = uuid.Parse(req.GetStepUpTicket())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument,
+				errors.Wrap(err, "parse step_up_ticket").Error())
+		}
+	}
+
+	session, err := c.authService.CreateSession(
+		userID, req.GetClientId(), grpcutils.GetRequestIP(ctx, c.trustedProxies),
+		domain.SessionOptions{
+			AMR:             req.GetAmr(),
+			LifetimeProfile: req.GetLifetimeProfile(),
+			RememberMe:      req.GetRememberMe(),
+			StepUpTicket:    stepUpTicket,
+		})
+	if err != nil {
+		return nil, toStatusError(ctx, "create session", err)
+	}
+
+	return &sessionv1.CreateSessionResponse{Tokens: newTokens(session)}, nil
+}
+
+func (c *SessionController) RefreshSession(
+	ctx context.Context, req *sessionv1.RefreshSessionRequest,
+) (*sessionv1.RefreshSessionResponse, error) {
+
+	session, err := c.authService.RefreshSession(
+		&domain.Session{
+			AccessTokenSigned: []byte(req.GetTokens().GetAccessToken()),
+			RefreshTokenValue: req.GetTokens().GetRefreshToken(),
+		},
+		grpcutils.GetRequestIP(ctx, c.trustedProxies),
+		grpcutils.GetUserAgent(ctx))
+	if err != nil {
+		return nil, toStatusError(ctx, "refresh session", err)
+	}
+
+	return &sessionv1.RefreshSessionResponse{Tokens: newTokens(session)}, nil
+}
+
+func (c *SessionController) CompleteRefreshChallenge(
+	ctx context.Context, req *sessionv1.CompleteRefreshChallengeRequest,
+) (*sessionv1.CompleteRefreshChallengeResponse, error) {
+
+	challengeID, err := uuid.Parse(req.GetChallengeId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "parse challenge_id").Error())
+	}
+
+	session, err := c.authService.CompleteRefreshChallenge(
+		&domain.Session{
+			AccessTokenSigned: []byte(req.GetTokens().GetAccessToken()),
+			RefreshTokenValue: req.GetTokens().GetRefreshToken(),
+		},
+		challengeID, req.GetCode(),
+		grpcutils.GetRequestIP(ctx, c.trustedProxies),
+		grpcutils.GetUserAgent(ctx))
+	if err != nil {
+		return nil, toStatusError(ctx, "complete refresh challenge", err)
+	}
+
+	return &sessionv1.CompleteRefreshChallengeResponse{Tokens: newTokens(session)}, nil
+}
+
+func (c *SessionController) RevokeSession(
+	ctx context.Context, req *sessionv1.RevokeSessionRequest,
+) (*sessionv1.RevokeSessionResponse, error) {
+
+	err := c.authService.RevokeSession(
+		[]byte(req.GetAccessToken()),
+		grpcutils.GetRequestIP(ctx, c.trustedProxies))
+	if err != nil {
+		return nil, toStatusError(ctx, "revoke session", err)
+	}
+
+	return &sessionv1.RevokeSessionResponse{}, nil
+}
+
+func (c *SessionController) ListSessions(
+	ctx context.Context, req *sessionv1.ListSessionsRequest,
+) (*sessionv1.ListSessionsResponse, error) {
+
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "parse user_id").Error())
+	}
+
+	refreshTokens, err := c.authService.ListSessions(userID)
+	if err != nil {
+		return nil, toStatusError(ctx, "list sessions", err)
+	}
+
+	sessions := make([]*sessionv1.Session, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		sessions = append(sessions, &sessionv1.Session{
+			Id:          refreshToken.SessionID.String(),
+			ClientId:    refreshToken.ClientID,
+			RefreshedAt: timestamppb.New(refreshToken.CreationTime),
+			ExpiresAt:   timestamppb.New(refreshToken.ExpirationTime),
+		})
+	}
+
+	return &sessionv1.ListSessionsResponse{Sessions: sessions}, nil
+}
+
+func newTokens(session *domain.Session) *sessionv1.Tokens {
+	return &sessionv1.Tokens{
+		AccessToken:  string(session.AccessTokenSigned),
+		RefreshToken: session.RefreshTokenValue,
+	}
+}
+
+// toStatusError maps domain errors to gRPC status codes the same way the
+// HTTP API maps them to HTTP statuses.
+func toStatusError(ctx context.Context, operation string, err error) error {
+	var unauthorizedError *domain.UnauthorizedError
+	var ipNotAllowedError *domain.IPNotAllowedError
+	var challengeRequiredError *domain.ChallengeRequiredError
+	var validationError *domain.ValidationError
+	var sessionLimitReachedError *domain.SessionLimitReachedError
+	switch {
+	case errors.As(err, &validationError):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &unauthorizedError):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.As(err, &ipNotAllowedError):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &sessionLimitReachedError):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.As(err, &challengeRequiredError):
+		st, detailsErr := status.New(codes.PermissionDenied, err.Error()).
+			WithDetails(&errdetails.ErrorInfo{
+				Reason: httputils.ErrorCodeChallengeRequired,
+				Domain: errorInfoDomain,
+				Metadata: map[string]string{
+					"challenge_id": challengeRequiredError.ChallengeID.String(),
+				},
+			})
+		if detailsErr != nil {
+			grpcutils.Logger(ctx).Error(operation, "error", detailsErr.Error())
+			return status.Error(codes.Internal, "")
+		}
+		return st.Err()
+	default:
+		grpcutils.Logger(ctx).Error(operation, "error", err.Error())
+		return status.Error(codes.Internal, "")
+	}
+}
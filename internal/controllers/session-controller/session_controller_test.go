@@ -0,0 +1,186 @@
+package sessioncontroller
+
+import (
+	grpcutils "auth/internal/controllers/grpc-utils"
+	httputils "auth/internal/controllers/http-utils"
+	"auth/internal/controllers/session-controller/mocks"
+	"auth/internal/domain"
+	sessionv1 "auth/pkg/proto/session/v1"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	userID   = uuid.New()
+	clientID = "web"
+	session  = &domain.Session{
+		AccessTokenSigned: []byte("access-token"),
+		RefreshTokenValue: []byte{1, 2, 3},
+	}
+)
+
+func newClientAndMock(t *testing.T) (sessionv1.SessionServiceClient, *mocks.AuthService) {
+	authService := mocks.NewAuthService(t)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		grpcutils.RequestIDInterceptor,
+		grpcutils.LoggingInterceptor,
+	))
+	NewSessionController(authService, httputils.TrustedProxies{}).Register(server)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return sessionv1.NewSessionServiceClient(conn), authService
+}
+
+func TestCreateSession_Success(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	amr := []string{domain.AMRPassword}
+	authService.On("CreateSession", userID, clientID, mock.Anything,
+		domain.SessionOptions{AMR: amr}).
+		Return(session, nil)
+
+	var header metadata.MD
+	resp, err := client.CreateSession(context.Background(),
+		&sessionv1.CreateSessionRequest{UserId: userID.String(), ClientId: clientID, Amr: amr},
+		grpc.Header(&header))
+
+	require.NoError(t, err)
+	assert.Equal(t, string(session.AccessTokenSigned), resp.GetTokens().GetAccessToken())
+	assert.Equal(t, session.RefreshTokenValue, resp.GetTokens().GetRefreshToken())
+	assert.NotEmpty(t, header.Get("x-request-id"))
+}
+
+func TestCreateSession_InvalidUserID(t *testing.T) {
+	client, _ := newClientAndMock(t)
+
+	_, err := client.CreateSession(context.Background(),
+		&sessionv1.CreateSessionRequest{UserId: "user"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateSession_IPNotAllowed(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("CreateSession", userID, clientID, mock.Anything, mock.Anything).
+		Return(nil, &domain.IPNotAllowedError{IP: "1.1.1.1", ClientID: clientID})
+
+	_, err := client.CreateSession(context.Background(),
+		&sessionv1.CreateSessionRequest{UserId: userID.String(), ClientId: clientID})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestCreateSession_SessionLimitReached(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("CreateSession", userID, clientID, mock.Anything, mock.Anything).
+		Return(nil, &domain.SessionLimitReachedError{MaxSessions: 3})
+
+	_, err := client.CreateSession(context.Background(),
+		&sessionv1.CreateSessionRequest{UserId: userID.String(), ClientId: clientID})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestRefreshSession_Unauthorized(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	authService.On("RefreshSession", session, mock.Anything, mock.Anything).
+		Return(nil, &domain.UnauthorizedError{Message: "refresh token is expired"})
+
+	_, err := client.RefreshSession(context.Background(),
+		&sessionv1.RefreshSessionRequest{Tokens: &sessionv1.Tokens{
+			AccessToken:  string(session.AccessTokenSigned),
+			RefreshToken: session.RefreshTokenValue,
+		}})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRefreshSession_ChallengeRequired(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	challengeID := uuid.New()
+	authService.On("RefreshSession", session, mock.Anything, mock.Anything).
+		Return(nil, &domain.ChallengeRequiredError{ChallengeID: challengeID})
+
+	_, err := client.RefreshSession(context.Background(),
+		&sessionv1.RefreshSessionRequest{Tokens: &sessionv1.Tokens{
+			AccessToken:  string(session.AccessTokenSigned),
+			RefreshToken: session.RefreshTokenValue,
+		}})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	require.Len(t, st.Details(), 1)
+	errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "challenge_required", errorInfo.GetReason())
+	assert.Equal(t, challengeID.String(), errorInfo.GetMetadata()["challenge_id"])
+}
+
+func TestCompleteRefreshChallenge_Success(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	challengeID := uuid.New()
+	authService.On("CompleteRefreshChallenge",
+		session, challengeID, "123456", mock.Anything, mock.Anything).
+		Return(session, nil)
+
+	resp, err := client.CompleteRefreshChallenge(context.Background(),
+		&sessionv1.CompleteRefreshChallengeRequest{
+			Tokens: &sessionv1.Tokens{
+				AccessToken:  string(session.AccessTokenSigned),
+				RefreshToken: session.RefreshTokenValue,
+			},
+			ChallengeId: challengeID.String(),
+			Code:        "123456",
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, string(session.AccessTokenSigned), resp.GetTokens().GetAccessToken())
+}
+
+func TestListSessions_Success(t *testing.T) {
+	client, authService := newClientAndMock(t)
+	refreshToken := domain.RefreshToken{
+		ID:             uuid.New(),
+		SessionID:      uuid.New(),
+		UserID:         userID,
+		ClientID:       clientID,
+		CreationTime:   time.Now().Add(-time.Hour).UTC(),
+		ExpirationTime: time.Now().Add(time.Hour).UTC(),
+	}
+	authService.On("ListSessions", userID).
+		Return([]domain.RefreshToken{refreshToken}, nil)
+
+	resp, err := client.ListSessions(context.Background(),
+		&sessionv1.ListSessionsRequest{UserId: userID.String()})
+
+	require.NoError(t, err)
+	require.Len(t, resp.GetSessions(), 1)
+	assert.Equal(t, refreshToken.SessionID.String(), resp.GetSessions()[0].GetId())
+	assert.Equal(t, clientID, resp.GetSessions()[0].GetClientId())
+	assert.Equal(t, refreshToken.CreationTime, resp.GetSessions()[0].GetRefreshedAt().AsTime())
+	assert.Equal(t, refreshToken.ExpirationTime, resp.GetSessions()[0].GetExpiresAt().AsTime())
+}
@@ -15,29 +15,75 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/sessions": {
+        "/admin/ip-access-rules/reload": {
             "post": {
-                "description": "Create new access and refresh tokens given user ID",
+                "description": "Reload IP allowlists and denylists from the database on all service instances.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload IP access rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/notification-outbox/dead": {
+            "get": {
+                "description": "List outbox notifications which failed to be sent after all attempts, most recent first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "admin"
                 ],
-                "summary": "Create new session",
+                "summary": "List dead notifications",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Bearer admin API token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of notifications, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of notifications to skip",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
+                    "200": {
                         "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/authcontroller.createSessionResponseBody"
+                            "$ref": "#/definitions/admincontroller.getDeadNotificationsResponseBody"
                         }
                     },
                     "400": {
@@ -46,6 +92,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/httputils.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -55,9 +107,152 @@ const docTemplate = `{
                 }
             }
         },
-        "/sessions/refresh": {
+        "/admin/notification-outbox/dead/{notificationID}/replay": {
+            "post": {
+                "description": "Schedule a dead outbox notification for sending again with attempts reset.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay dead notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin API token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "notificationID",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead notification not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify": {
+            "get": {
+                "description": "Forward authentication endpoint for nginx auth_request and Traefik ForwardAuth.\nValidates the bearer access token including revocations and returns identity headers.\nAccepts any HTTP method, so proxies may forward the original one.\nWith maxAge, tokens of users who authenticated longer ago are rejected with\nthe insufficient_user_authentication error of RFC 9470.",
+                "tags": [
+                    "token"
+                ],
+                "summary": "Verify request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum authentication age in seconds",
+                        "name": "maxAge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success, identity is returned in X-User-ID, X-Session-ID and X-Token-ID headers, authentication context in X-Auth-Time, X-ACR and X-AMR headers"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/magic-link": {
+            "post": {
+                "description": "Email a single-use login link to the user and set the nonce cookie the link is bound to.\nThe response is the same whether the email is registered or not.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "magic-link"
+                ],
+                "summary": "Request magic link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "clientID",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Email of the user",
+                        "name": "email",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/magiclinkcontroller.requestLinkRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/magic-link/callback": {
             "post": {
-                "description": "Create a new access and refresh tokens pair from given access and refresh tokens.\nProvided refresh token is invalidated on success.\nProvided refresh token should have been issued with provided access token.\nProvided access token can be expired, but refresh token can't.",
+                "description": "Create a session with the token of a magic link. The request must carry the nonce cookie\nset when the link was requested, the link is used up either way.",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,16 +260,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "magic-link"
                 ],
-                "summary": "Refresh session",
+                "summary": "Exchange magic link",
                 "parameters": [
                     {
-                        "description": "Access and refresh tokens",
-                        "name": "access_and_refresh_tokens",
+                        "description": "Token of the link",
+                        "name": "token",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/authcontroller.refreshSessionRequestBody"
+                            "$ref": "#/definitions/magiclinkcontroller.exchangeLinkRequestBody"
                         }
                     }
                 ],
@@ -82,7 +277,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/authcontroller.refreshSessionResponseBody"
+                            "$ref": "#/definitions/magiclinkcontroller.exchangeLinkResponseBody"
                         }
                     },
                     "400": {
@@ -92,7 +287,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Link is invalid, expired or requested from another browser",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "IP address is not allowed or the user has the maximum number of sessions",
                         "schema": {
                             "$ref": "#/definitions/httputils.HTTPError"
                         }
@@ -105,47 +306,1922 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "authcontroller.createSessionResponseBody": {
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "refreshToken": {
-                    "type": "string"
-                }
-            }
-        },
-        "authcontroller.refreshSessionRequestBody": {
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "refreshToken": {
-                    "type": "string"
-                }
-            }
         },
-        "authcontroller.refreshSessionResponseBody": {
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "refreshToken": {
-                    "type": "string"
+        "/mfa/recovery-codes": {
+            "post": {
+                "description": "Replace recovery codes of the user given a TOTP code. Previous codes stop working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.regenerateRecoveryCodesRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.regenerateRecoveryCodesResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or code is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification is locked",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "TOTP is not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "httputils.HTTPError": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
+        "/mfa/step-up": {
+            "post": {
+                "description": "Verify a TOTP code or a recovery code and step up the session of the access token.\nAccess tokens returned by the next refresh of the session have \"acr\": \"aal2\" and the \"amr\" claim.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Step up session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "factor",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.stepUpSessionRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or code is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification is locked",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "TOTP is not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/totp/confirm": {
+            "post": {
+                "description": "Confirm the enrolled TOTP secret with a code of the authenticator app.\nRecovery codes are returned once, each of them can replace a TOTP code once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Confirm TOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.confirmTOTPRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.confirmTOTPResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or code is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification is locked",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "TOTP is not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP is already confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/totp/enroll": {
+            "post": {
+                "description": "Generate a TOTP secret for an authenticator app. The otpauth URI is usually shown as a QR code.\nThe secret is used after it is confirmed with a code. Enrolling again replaces a not confirmed secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mfa"
+                ],
+                "summary": "Enroll TOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.enrollTOTPResponseBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP is already enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "List in-app notifications of the user, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notification"
+                ],
+                "summary": "List notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of notifications, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of notifications to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/notificationcontroller.getNotificationsResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{notificationID}/read": {
+            "post": {
+                "description": "Mark the in-app notification of the user as read.",
+                "tags": [
+                    "notification"
+                ],
+                "summary": "Mark notification read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "notificationID",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Notification not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/revocations": {
+            "get": {
+                "description": "Get all revoked and not yet expired sessions (\"sid\" kind) and access tokens (\"jti\" kind).\n\"user\" kind is the user's tokens watermark, access tokens of the user issued before its revokedAt are revoked.\nReturned cursor should be passed to the revocations stream to receive revocations made after the snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Get revocations snapshot",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/revocationcontroller.getRevocationsResponseBody"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/revocations/stream": {
+            "get": {
+                "description": "Stream revocations made after the cursor as Server-Sent Events.\nEach \"revocation\" event has the revocation seq as its ID and RevocationDTO as its data.\nThe cursor is taken from \"cursor\" query param or from \"Last-Event-ID\" header on reconnect.\nWithout the cursor only revocations made after the request are streamed.\nRevocations which have expired since the cursor was taken may be skipped.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "revocation"
+                ],
+                "summary": "Stream revocations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Seq of the last received revocation",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of revocation events",
+                        "schema": {
+                            "$ref": "#/definitions/revocationcontroller.RevocationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "description": "Create new access and refresh tokens given user ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Create new session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "clientID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Methods the user authenticated with, RFC 8176 values like pwd",
+                        "name": "amr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Use the remember me lifetime profile",
+                        "name": "rememberMe",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Lifetime profile of the tokens, the client default if empty",
+                        "name": "lifetimeProfile",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ticket returned by the step-up of the user for this login",
+                        "name": "stepUpTicket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.createSessionResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "IP address is not allowed or the user has the maximum number of sessions",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/refresh": {
+            "post": {
+                "description": "Create a new access and refresh tokens pair from given access and refresh tokens.\nProvided refresh token is invalidated on success.\nProvided refresh token should have been issued with provided access token.\nProvided access token can be expired, but refresh token can't.\nA refresh from a new IP address may require a code emailed to the user, then the refresh is completed by POST /sessions/refresh/challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Refresh session",
+                "parameters": [
+                    {
+                        "description": "Access and refresh tokens",
+                        "name": "access_and_refresh_tokens",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.refreshSessionRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.refreshSessionResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "IP address is not allowed or refresh must be confirmed with the emailed code (challengeId is set)",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.ChallengeRequiredErrorDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/refresh/challenge": {
+            "post": {
+                "description": "Complete the refresh which returned the challenge_required error with the code emailed to the user.\nThe request must come from the same IP address with the same tokens as the refresh.\nThe code expires in minutes and the challenge is deleted after the attempts limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Complete refresh challenge",
+                "parameters": [
+                    {
+                        "description": "Tokens, challenge ID and code",
+                        "name": "challenge",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.completeRefreshChallengeRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.completeRefreshChallengeResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "IP address is not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/revoke": {
+            "post": {
+                "description": "Invalidate the refresh token the access token was issued with.\nAll access tokens issued with the refresh token are rejected from now on, even not yet expired ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "description": "Access token",
+                        "name": "access_token",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.revokeSessionRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/revoke-link": {
+            "get": {
+                "description": "Show the confirmation page of the \"This wasn't me\" link from security notifications.\nOpening the page doesn't use the link up, so mail scanners can't revoke sessions.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "\"This wasn't me\" page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed link token",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page"
+                    },
+                    "400": {
+                        "description": "Error page"
+                    },
+                    "401": {
+                        "description": "Error page"
+                    },
+                    "500": {
+                        "description": "Error page"
+                    }
+                }
+            },
+            "post": {
+                "description": "Revoke the session the link was sent about, or all sessions of the user by raising the user's tokens watermark.\nThe link can be used once, every use and reuse attempt is audited.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke by \"This wasn't me\" link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Signed link token",
+                        "name": "token",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "session",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "What to revoke",
+                        "name": "scope",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result page"
+                    },
+                    "400": {
+                        "description": "Error page"
+                    },
+                    "401": {
+                        "description": "Error page"
+                    },
+                    "500": {
+                        "description": "Error page"
+                    }
+                }
+            }
+        },
+        "/tokens/introspect": {
+            "post": {
+                "description": "Check whether the access token is active as described in RFC 7662.\nInactive tokens are reported with \"active\": false only.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Introspect access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "token",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.introspectTokenResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/revoke": {
+            "post": {
+                "description": "Invalidate a single access token as described in RFC 7009. The session the token belongs to stays active.\nInvalid and already expired tokens are ignored.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "token"
+                ],
+                "summary": "Revoke access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "token",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/step-up": {
+            "post": {
+                "description": "Verify a TOTP code or a recovery code of the user before creating a session.\nThe session created for the user with the returned ticket within minutes has \"acr\": \"aal2\" and the \"amr\" claim.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Step up user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path"
+                    },
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "factor",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.stepUpUserRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/mfacontroller.stepUpUserResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Code is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification is locked",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "TOTP is not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/tokens-not-before": {
+            "put": {
+                "description": "Invalidate all access and refresh tokens of the user issued before given time, even not yet expired ones.\nCurrent time is used if the time is not provided. Time can't be in the future.\nThe watermark is never moved back, so earlier time than the current watermark is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Invalidate user's tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path"
+                    },
+                    {
+                        "description": "Tokens watermark",
+                        "name": "not_before",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authcontroller.raiseUserTokensNotBeforeRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/credentials": {
+            "get": {
+                "description": "List passkeys of the user, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "List passkeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.getCredentialsResponseBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/credentials/{credentialID}": {
+            "delete": {
+                "description": "Delete the passkey of the user. Sessions created with it are not revoked.",
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Delete passkey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential ID encoded as base64url",
+                        "name": "credentialID",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Passkey not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/login/begin": {
+            "post": {
+                "description": "Start a login with a passkey. Pass publicKey to navigator.credentials.get\nand the result to /webauthn/login/finish with the ceremony ID within the timeout.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Begin passkey login",
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.beginLoginResponseBody"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/login/finish": {
+            "post": {
+                "description": "Verify the result of navigator.credentials.get and create a session of the owner of the passkey.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Finish passkey login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "clientID",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Ceremony ID and the credential assertion",
+                        "name": "assertion",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.finishLoginRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.finishLoginResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "IP address is not allowed or the user has the maximum number of sessions",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/registration/begin": {
+            "post": {
+                "description": "Start registration of a passkey of the user. Pass publicKey to navigator.credentials.create\nand the result to /webauthn/registration/finish with the ceremony ID within the timeout.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Begin passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.beginRegistrationResponseBody"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webauthn/registration/finish": {
+            "post": {
+                "description": "Verify the result of navigator.credentials.create and store the passkey.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webauthn"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer access token",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Ceremony ID, passkey name and the created credential",
+                        "name": "credential",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.finishRegistrationRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/webauthncontroller.finishRegistrationResponseBody"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request or verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputils.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "admincontroller.OutboxNotificationDTO": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "failedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "admincontroller.getDeadNotificationsResponseBody": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admincontroller.OutboxNotificationDTO"
+                    }
+                }
+            }
+        },
+        "authcontroller.ChallengeRequiredErrorDTO": {
+            "type": "object",
+            "properties": {
+                "challengeId": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.completeRefreshChallengeRequestBody": {
+            "type": "object",
+            "required": [
+                "accessToken",
+                "challengeId",
+                "code",
+                "refreshToken"
+            ],
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "challengeId": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.completeRefreshChallengeResponseBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.createSessionResponseBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.introspectTokenResponseBody": {
+            "type": "object",
+            "properties": {
+                "acr": {
+                    "description": "ACR is set for stepped up sessions, AMR if the methods are known.",
+                    "type": "string"
+                },
+                "active": {
+                    "type": "boolean"
+                },
+                "amr": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auth_time": {
+                    "description": "AuthTime is set if the authentication time of the session is known.",
+                    "type": "integer"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "sid": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.raiseUserTokensNotBeforeRequestBody": {
+            "type": "object",
+            "properties": {
+                "notBefore": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.refreshSessionRequestBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.refreshSessionResponseBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authcontroller.revokeSessionRequestBody": {
+            "type": "object",
+            "required": [
+                "accessToken"
+            ],
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RevokedTokenKind": {
+            "type": "string",
+            "enum": [
+                "jti",
+                "sid",
+                "user"
+            ],
+            "x-enum-varnames": [
+                "RevokedTokenKindAccessToken",
+                "RevokedTokenKindSession",
+                "RevokedTokenKindUser"
+            ]
+        },
+        "httputils.HTTPError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "magiclinkcontroller.exchangeLinkRequestBody": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "magiclinkcontroller.exchangeLinkResponseBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "magiclinkcontroller.requestLinkRequestBody": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.confirmTOTPRequestBody": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.confirmTOTPResponseBody": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "mfacontroller.enrollTOTPResponseBody": {
+            "type": "object",
+            "properties": {
+                "otpauthUri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.regenerateRecoveryCodesRequestBody": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.regenerateRecoveryCodesResponseBody": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "mfacontroller.stepUpSessionRequestBody": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "recoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.stepUpUserRequestBody": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "recoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "mfacontroller.stepUpUserResponseBody": {
+            "type": "object",
+            "properties": {
+                "stepUpTicket": {
+                    "type": "string"
+                }
+            }
+        },
+        "notificationcontroller.NotificationDTO": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "readAt": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "notificationcontroller.getNotificationsResponseBody": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notificationcontroller.NotificationDTO"
+                    }
+                }
+            }
+        },
+        "revocationcontroller.RevocationDTO": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/domain.RevokedTokenKind"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "seq": {
+                    "type": "integer"
+                }
+            }
+        },
+        "revocationcontroller.getRevocationsResponseBody": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "integer"
+                },
+                "revocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/revocationcontroller.RevocationDTO"
+                    }
+                }
+            }
+        },
+        "webauthncontroller.AuthenticatorSelectionDTO": {
+            "type": "object",
+            "properties": {
+                "requireResidentKey": {
+                    "type": "boolean"
+                },
+                "residentKey": {
+                    "type": "string"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.PublicKeyCredentialCreationOptionsDTO": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "type": "string"
+                },
+                "authenticatorSelection": {
+                    "$ref": "#/definitions/webauthncontroller.AuthenticatorSelectionDTO"
+                },
+                "challenge": {
+                    "type": "string"
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webauthncontroller.PublicKeyCredentialDescriptorDTO"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webauthncontroller.PublicKeyCredentialParametersDTO"
+                    }
+                },
+                "rp": {
+                    "$ref": "#/definitions/webauthncontroller.RelyingPartyDTO"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/webauthncontroller.UserEntityDTO"
+                }
+            }
+        },
+        "webauthncontroller.PublicKeyCredentialDescriptorDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.PublicKeyCredentialParametersDTO": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.PublicKeyCredentialRequestOptionsDTO": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "rpId": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.RelyingPartyDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.UserEntityDTO": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.WebAuthnCredentialDTO": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "webauthncontroller.assertionCredentialDTO": {
+            "type": "object",
+            "required": [
+                "id",
+                "response"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "response": {
+                    "$ref": "#/definitions/webauthncontroller.assertionResponseDTO"
+                }
+            }
+        },
+        "webauthncontroller.assertionResponseDTO": {
+            "type": "object",
+            "required": [
+                "authenticatorData",
+                "clientDataJSON",
+                "signature"
+            ],
+            "properties": {
+                "authenticatorData": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                },
+                "userHandle": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.attestationCredentialDTO": {
+            "type": "object",
+            "required": [
+                "id",
+                "response"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "response": {
+                    "$ref": "#/definitions/webauthncontroller.attestationResponseDTO"
+                }
+            }
+        },
+        "webauthncontroller.attestationResponseDTO": {
+            "type": "object",
+            "required": [
+                "attestationObject",
+                "clientDataJSON"
+            ],
+            "properties": {
+                "attestationObject": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "webauthncontroller.beginLoginResponseBody": {
+            "type": "object",
+            "properties": {
+                "ceremonyId": {
+                    "type": "string"
+                },
+                "publicKey": {
+                    "$ref": "#/definitions/webauthncontroller.PublicKeyCredentialRequestOptionsDTO"
+                }
+            }
+        },
+        "webauthncontroller.beginRegistrationResponseBody": {
+            "type": "object",
+            "properties": {
+                "ceremonyId": {
+                    "type": "string"
+                },
+                "publicKey": {
+                    "$ref": "#/definitions/webauthncontroller.PublicKeyCredentialCreationOptionsDTO"
+                }
+            }
+        },
+        "webauthncontroller.finishLoginRequestBody": {
+            "type": "object",
+            "required": [
+                "ceremonyId",
+                "credential"
+            ],
+            "properties": {
+                "ceremonyId": {
+                    "type": "string"
+                },
+                "credential": {
+                    "$ref": "#/definitions/webauthncontroller.assertionCredentialDTO"
+                }
+            }
+        },
+        "webauthncontroller.finishLoginResponseBody": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "webauthncontroller.finishRegistrationRequestBody": {
+            "type": "object",
+            "required": [
+                "ceremonyId",
+                "credential"
+            ],
+            "properties": {
+                "ceremonyId": {
+                    "type": "string"
+                },
+                "credential": {
+                    "$ref": "#/definitions/webauthncontroller.attestationCredentialDTO"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "webauthncontroller.finishRegistrationResponseBody": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "webauthncontroller.getCredentialsResponseBody": {
+            "type": "object",
+            "properties": {
+                "credentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webauthncontroller.WebAuthnCredentialDTO"
+                    }
                 }
             }
         }
@@ -0,0 +1,36 @@
+package webauthncontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type beginLoginResponseBody struct {
+	CeremonyID string                               `json:"ceremonyId"`
+	PublicKey  PublicKeyCredentialRequestOptionsDTO `json:"publicKey"`
+}
+
+// @Summary		Begin passkey login
+// @Description	Start a login with a passkey. Pass publicKey to navigator.credentials.get
+// @Description	and the result to /webauthn/login/finish with the ceremony ID within the timeout.
+// @Tags			webauthn
+// @Produce		json
+// @Success		201	{object}	beginLoginResponseBody	"Success"
+// @Failure		500	{object}	httputils.HTTPError		"Internal server error"
+// @Router			/webauthn/login/begin [post]
+func (controller *WebAuthnController) beginLogin(c *gin.Context) {
+	options, err := controller.webAuthnService.BeginLogin()
+	if err != nil {
+		slogutils.Error("begin webauthn login", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, beginLoginResponseBody{
+		CeremonyID: options.CeremonyID.String(),
+		PublicKey:  newRequestOptionsDTO(options),
+	})
+}
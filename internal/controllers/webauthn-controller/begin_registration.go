@@ -0,0 +1,38 @@
+package webauthncontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type beginRegistrationResponseBody struct {
+	CeremonyID string                                `json:"ceremonyId"`
+	PublicKey  PublicKeyCredentialCreationOptionsDTO `json:"publicKey"`
+}
+
+// @Summary		Begin passkey registration
+// @Description	Start registration of a passkey of the user. Pass publicKey to navigator.credentials.create
+// @Description	and the result to /webauthn/registration/finish with the ceremony ID within the timeout.
+// @Tags			webauthn
+// @Produce		json
+// @Param			Authorization	header		string							yes	"Bearer access token"
+// @Success		201				{object}	beginRegistrationResponseBody	"Success"
+// @Failure		401				{object}	httputils.HTTPError				"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError				"Internal server error"
+// @Router			/webauthn/registration/begin [post]
+func (controller *WebAuthnController) beginRegistration(c *gin.Context) {
+	options, err := controller.webAuthnService.BeginRegistration(ginutils.AccessToken(c).UserID)
+	if err != nil {
+		slogutils.Error("begin webauthn registration", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, beginRegistrationResponseBody{
+		CeremonyID: options.CeremonyID.String(),
+		PublicKey:  newCreationOptionsDTO(options),
+	})
+}
@@ -0,0 +1,46 @@
+package webauthncontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// @Summary		Delete passkey
+// @Description	Delete the passkey of the user. Sessions created with it are not revoked.
+// @Tags			webauthn
+// @Param			Authorization	header	string	yes	"Bearer access token"
+// @Param			credentialID	path	string	yes	"Credential ID encoded as base64url"
+// @Success		204				"Success"
+// @Failure		400				{object}	httputils.HTTPError	"Bad request"
+// @Failure		401				{object}	httputils.HTTPError	"Unauthorized"
+// @Failure		404				{object}	httputils.HTTPError	"Passkey not found"
+// @Failure		500				{object}	httputils.HTTPError	"Internal server error"
+// @Router			/webauthn/credentials/{credentialID} [delete]
+func (controller *WebAuthnController) deleteCredential(c *gin.Context) {
+	credentialID, err := base64.RawURLEncoding.DecodeString(c.Param(credentialIDParamName))
+	if err != nil {
+		ginutils.BadRequest(c, errors.Wrap(err, "decode credentialID"))
+		return
+	}
+
+	var notFoundError *domain.NotFoundError
+	err = controller.webAuthnService.DeleteCredential(ginutils.AccessToken(c).UserID, credentialID)
+	switch {
+	case err == nil:
+	case errors.As(err, &notFoundError):
+		ginutils.NotFoundError(c, err)
+		return
+	default:
+		slogutils.Error("delete webauthn credential", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,151 @@
+package webauthncontroller
+
+import (
+	"auth/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Base64URL is binary data encoded as base64url, the encoding of binary
+// fields in JSON of the WebAuthn API. Padding is accepted on input.
+type Base64URL []byte
+
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(str, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+
+	return nil
+}
+
+type SessionDTO struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type WebAuthnCredentialDTO struct {
+	ID         Base64URL  `json:"id" swaggertype:"string"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+func newWebAuthnCredentialDTO(credential domain.WebAuthnCredential) WebAuthnCredentialDTO {
+	dto := WebAuthnCredentialDTO{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		Transports: credential.Transports,
+		CreatedAt:  credential.CreationTime,
+	}
+	if !credential.LastUsedTime.IsZero() {
+		dto.LastUsedAt = &credential.LastUsedTime
+	}
+
+	return dto
+}
+
+type PublicKeyCredentialDescriptorDTO struct {
+	Type string    `json:"type"`
+	ID   Base64URL `json:"id" swaggertype:"string"`
+}
+
+type PublicKeyCredentialParametersDTO struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type RelyingPartyDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntityDTO struct {
+	ID          Base64URL `json:"id" swaggertype:"string"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+}
+
+type AuthenticatorSelectionDTO struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// PublicKeyCredentialCreationOptionsDTO is passed as the publicKey option to
+// navigator.credentials.create.
+type PublicKeyCredentialCreationOptionsDTO struct {
+	RP                     RelyingPartyDTO                    `json:"rp"`
+	User                   UserEntityDTO                      `json:"user"`
+	Challenge              Base64URL                          `json:"challenge" swaggertype:"string"`
+	PubKeyCredParams       []PublicKeyCredentialParametersDTO `json:"pubKeyCredParams"`
+	Timeout                int64                              `json:"timeout"`
+	ExcludeCredentials     []PublicKeyCredentialDescriptorDTO `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelectionDTO          `json:"authenticatorSelection"`
+	Attestation            string                             `json:"attestation"`
+}
+
+func newCreationOptionsDTO(
+	options *domain.WebAuthnRegistrationOptions,
+) PublicKeyCredentialCreationOptionsDTO {
+
+	params := make([]PublicKeyCredentialParametersDTO, 0, len(options.Algorithms))
+	for _, alg := range options.Algorithms {
+		params = append(params, PublicKeyCredentialParametersDTO{Type: "public-key", Alg: alg})
+	}
+	excludeCredentials := make([]PublicKeyCredentialDescriptorDTO, 0, len(options.ExcludeCredentialIDs))
+	for _, id := range options.ExcludeCredentialIDs {
+		excludeCredentials = append(excludeCredentials,
+			PublicKeyCredentialDescriptorDTO{Type: "public-key", ID: id})
+	}
+
+	return PublicKeyCredentialCreationOptionsDTO{
+		RP: RelyingPartyDTO{ID: options.RPID, Name: options.RPName},
+		User: UserEntityDTO{
+			ID:          options.UserHandle,
+			Name:        options.UserName,
+			DisplayName: options.UserName,
+		},
+		Challenge:          options.Challenge,
+		PubKeyCredParams:   params,
+		Timeout:            options.Timeout.Milliseconds(),
+		ExcludeCredentials: excludeCredentials,
+		// passkeys are discoverable, so users log in without a user name
+		AuthenticatorSelection: AuthenticatorSelectionDTO{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   options.UserVerification,
+		},
+		Attestation: "none",
+	}
+}
+
+// PublicKeyCredentialRequestOptionsDTO is passed as the publicKey option to
+// navigator.credentials.get.
+type PublicKeyCredentialRequestOptionsDTO struct {
+	Challenge        Base64URL `json:"challenge" swaggertype:"string"`
+	RPID             string    `json:"rpId"`
+	Timeout          int64     `json:"timeout"`
+	UserVerification string    `json:"userVerification"`
+}
+
+func newRequestOptionsDTO(options *domain.WebAuthnLoginOptions) PublicKeyCredentialRequestOptionsDTO {
+	return PublicKeyCredentialRequestOptionsDTO{
+		Challenge:        options.Challenge,
+		RPID:             options.RPID,
+		Timeout:          options.Timeout.Milliseconds(),
+		UserVerification: options.UserVerification,
+	}
+}
@@ -0,0 +1,91 @@
+package webauthncontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type assertionResponseDTO struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON" binding:"required" swaggertype:"string"`
+	AuthenticatorData Base64URL `json:"authenticatorData" binding:"required" swaggertype:"string"`
+	Signature         Base64URL `json:"signature" binding:"required" swaggertype:"string"`
+	UserHandle        Base64URL `json:"userHandle" swaggertype:"string"`
+}
+
+type assertionCredentialDTO struct {
+	ID       Base64URL            `json:"id" binding:"required" swaggertype:"string"`
+	Response assertionResponseDTO `json:"response" binding:"required"`
+}
+
+type finishLoginRequestBody struct {
+	CeremonyID string                 `json:"ceremonyId" binding:"required,uuid"`
+	Credential assertionCredentialDTO `json:"credential" binding:"required"`
+}
+
+type finishLoginResponseBody SessionDTO
+
+// @Summary		Finish passkey login
+// @Description	Verify the result of navigator.credentials.get and create a session of the owner of the passkey.
+// @Tags			webauthn
+// @Accept			json
+// @Produce		json
+// @Param			clientID	query		string					no	"Client ID"
+// @Param			assertion	body		finishLoginRequestBody	yes	"Ceremony ID and the credential assertion"
+// @Success		201			{object}	finishLoginResponseBody	"Success"
+// @Failure		400			{object}	httputils.HTTPError		"Bad request"
+// @Failure		401			{object}	httputils.HTTPError		"Verification failed"
+// @Failure		403			{object}	httputils.HTTPError		"IP address is not allowed or the user has the maximum number of sessions"
+// @Failure		500			{object}	httputils.HTTPError		"Internal server error"
+// @Router			/webauthn/login/finish [post]
+func (controller *WebAuthnController) finishLogin(c *gin.Context) {
+	var reqBody finishLoginRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	var unauthorizedError *domain.UnauthorizedError
+	var ipNotAllowedError *domain.IPNotAllowedError
+	var sessionLimitReachedError *domain.SessionLimitReachedError
+	session, err := controller.webAuthnService.FinishLogin(
+		uuid.MustParse(reqBody.CeremonyID),
+		domain.WebAuthnAssertion{
+			CredentialID:      reqBody.Credential.ID,
+			ClientDataJSON:    reqBody.Credential.Response.ClientDataJSON,
+			AuthenticatorData: reqBody.Credential.Response.AuthenticatorData,
+			Signature:         reqBody.Credential.Response.Signature,
+			UserHandle:        reqBody.Credential.Response.UserHandle,
+		},
+		c.Query(clientIDParamName),
+		httputils.GetRequestIP(c.Request, controller.trustedProxies))
+	switch {
+	case err == nil:
+	case errors.As(err, &unauthorizedError):
+		ginutils.UnauthorizedError(c, err)
+		return
+	case errors.As(err, &ipNotAllowedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeIPNotAllowed, err)
+		return
+	case errors.As(err, &sessionLimitReachedError):
+		ginutils.ForbiddenError(c, httputils.ErrorCodeSessionLimitReached, err)
+		return
+	default:
+		slogutils.Error("finish webauthn login", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, finishLoginResponseBody{
+		AccessToken: string(session.AccessTokenSigned),
+		RefreshToken: base64.StdEncoding.
+			EncodeToString(session.RefreshTokenValue),
+	})
+}
@@ -0,0 +1,75 @@
+package webauthncontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type attestationResponseDTO struct {
+	ClientDataJSON    Base64URL `json:"clientDataJSON" binding:"required" swaggertype:"string"`
+	AttestationObject Base64URL `json:"attestationObject" binding:"required" swaggertype:"string"`
+	Transports        []string  `json:"transports"`
+}
+
+type attestationCredentialDTO struct {
+	ID       Base64URL              `json:"id" binding:"required" swaggertype:"string"`
+	Response attestationResponseDTO `json:"response" binding:"required"`
+}
+
+type finishRegistrationRequestBody struct {
+	CeremonyID string                   `json:"ceremonyId" binding:"required,uuid"`
+	Name       string                   `json:"name" binding:"max=100"`
+	Credential attestationCredentialDTO `json:"credential" binding:"required"`
+}
+
+type finishRegistrationResponseBody WebAuthnCredentialDTO
+
+// @Summary		Finish passkey registration
+// @Description	Verify the result of navigator.credentials.create and store the passkey.
+// @Tags			webauthn
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							yes	"Bearer access token"
+// @Param			credential		body		finishRegistrationRequestBody	yes	"Ceremony ID, passkey name and the created credential"
+// @Success		201				{object}	finishRegistrationResponseBody	"Success"
+// @Failure		400				{object}	httputils.HTTPError				"Bad request or verification failed"
+// @Failure		401				{object}	httputils.HTTPError				"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError				"Internal server error"
+// @Router			/webauthn/registration/finish [post]
+func (controller *WebAuthnController) finishRegistration(c *gin.Context) {
+	var reqBody finishRegistrationRequestBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		ginutils.BindJSONError(c, err)
+		return
+	}
+
+	var validationError *domain.ValidationError
+	credential, err := controller.webAuthnService.FinishRegistration(
+		ginutils.AccessToken(c).UserID,
+		uuid.MustParse(reqBody.CeremonyID),
+		domain.WebAuthnAttestation{
+			CredentialID:      reqBody.Credential.ID,
+			ClientDataJSON:    reqBody.Credential.Response.ClientDataJSON,
+			AttestationObject: reqBody.Credential.Response.AttestationObject,
+			Transports:        reqBody.Credential.Response.Transports,
+		},
+		reqBody.Name)
+	switch {
+	case err == nil:
+	case errors.As(err, &validationError):
+		ginutils.BadRequest(c, err)
+		return
+	default:
+		slogutils.Error("finish webauthn registration", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusCreated, finishRegistrationResponseBody(newWebAuthnCredentialDTO(*credential)))
+}
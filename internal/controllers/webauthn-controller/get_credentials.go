@@ -0,0 +1,38 @@
+package webauthncontroller
+
+import (
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getCredentialsResponseBody struct {
+	Credentials []WebAuthnCredentialDTO `json:"credentials"`
+}
+
+// @Summary		List passkeys
+// @Description	List passkeys of the user, oldest first.
+// @Tags			webauthn
+// @Produce		json
+// @Param			Authorization	header		string						yes	"Bearer access token"
+// @Success		200				{object}	getCredentialsResponseBody	"Success"
+// @Failure		401				{object}	httputils.HTTPError			"Unauthorized"
+// @Failure		500				{object}	httputils.HTTPError			"Internal server error"
+// @Router			/webauthn/credentials [get]
+func (controller *WebAuthnController) getCredentials(c *gin.Context) {
+	credentials, err := controller.webAuthnService.ListCredentials(ginutils.AccessToken(c).UserID)
+	if err != nil {
+		slogutils.Error("list webauthn credentials", err)
+		ginutils.InternalError(c)
+		return
+	}
+
+	dtos := make([]WebAuthnCredentialDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		dtos = append(dtos, newWebAuthnCredentialDTO(credential))
+	}
+
+	c.JSON(http.StatusOK, getCredentialsResponseBody{Credentials: dtos})
+}
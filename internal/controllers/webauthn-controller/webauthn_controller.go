@@ -0,0 +1,67 @@
+package webauthncontroller
+
+import (
+	httputils "auth/internal/controllers/http-utils"
+	ginutils "auth/internal/controllers/http-utils/gin-utils"
+	"auth/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	credentialIDParamName = "credentialID"
+	clientIDParamName     = "clientID"
+)
+
+// WebAuthnController serves passkey ceremonies in the JSON shape of the
+// WebAuthn API with binary fields encoded as base64url. Users register
+// passkeys with access tokens, logins create sessions.
+type WebAuthnController struct {
+	authService     AuthService
+	webAuthnService WebAuthnService
+	trustedProxies  httputils.TrustedProxies
+}
+
+type AuthService interface {
+	ginutils.AccessTokenValidator
+}
+
+type WebAuthnService interface {
+	BeginRegistration(userID uuid.UUID) (*domain.WebAuthnRegistrationOptions, error)
+	FinishRegistration(
+		userID, ceremonyID uuid.UUID, attestation domain.WebAuthnAttestation, name string,
+	) (*domain.WebAuthnCredential, error)
+	BeginLogin() (*domain.WebAuthnLoginOptions, error)
+	FinishLogin(
+		ceremonyID uuid.UUID, assertion domain.WebAuthnAssertion,
+		clientID, requestIP string,
+	) (*domain.Session, error)
+	ListCredentials(userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	DeleteCredential(userID uuid.UUID, credentialID []byte) error
+}
+
+func NewWebAuthnController(
+	authService AuthService,
+	webAuthnService WebAuthnService,
+	trustedProxies httputils.TrustedProxies,
+) *WebAuthnController {
+
+	return &WebAuthnController{
+		authService:     authService,
+		webAuthnService: webAuthnService,
+		trustedProxies:  trustedProxies,
+	}
+}
+
+func (c *WebAuthnController) RegisterRoutes(engine *gin.Engine) {
+	authenticated := engine.Group("webauthn", ginutils.Authenticate(c.authService))
+	authenticated.POST("/registration/begin", c.beginRegistration)
+	authenticated.POST("/registration/finish", c.finishRegistration)
+	authenticated.GET("/credentials", c.getCredentials)
+	authenticated.DELETE("/credentials/:"+credentialIDParamName, c.deleteCredential)
+
+	loginGroup := engine.Group("webauthn/login")
+	loginGroup.POST("/begin", c.beginLogin)
+	loginGroup.POST("/finish", c.finishLogin)
+}
@@ -9,12 +9,7 @@ import (
 )
 
 func NewDatabase(cfg config.DatabaseConfig) (*sqlx.DB, error) {
-	connInfo := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode,
-	)
-
-	client, err := sqlx.Connect("postgres", connInfo)
+	client, err := sqlx.Connect("postgres", connInfo(cfg))
 	if err != nil {
 		return nil, err
 	}
@@ -26,3 +21,10 @@ func NewDatabase(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 
 	return client, nil
 }
+
+func connInfo(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode,
+	)
+}
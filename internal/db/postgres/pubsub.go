@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"auth/internal/config"
+	slogutils "auth/internal/utils/slog-utils"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+	listenerPingPeriod           = time.Second * 30
+)
+
+type Channel string
+
+const (
+	// ChannelRevokedTokens receives revoked_tokens rows as JSON.
+	// Notifications are sent by the table trigger.
+	ChannelRevokedTokens Channel = "auth_revoked_tokens"
+	// ChannelTokenWatermarks receives user_token_watermarks rows as JSON.
+	// Notifications are sent by the table trigger.
+	ChannelTokenWatermarks Channel = "auth_token_watermarks"
+	// ChannelConfigReloads receives names of reloaded configs.
+	ChannelConfigReloads Channel = "auth_config_reloads"
+)
+
+const (
+	ConfigIPAccessRules = "ip_access_rules"
+)
+
+// Subscription handles notifications of a channel. Notifications sent while
+// the listener connection is down are lost, so subscribers which keep state
+// derived from notifications should reload it in OnResync.
+type Subscription struct {
+	OnNotification func(payload string)
+	// OnDisconnect is called when the listener connection is lost.
+	OnDisconnect func()
+	// OnResync is called when the listener connection is re-established.
+	OnResync func()
+}
+
+// PubSub broadcasts messages to all service instances connected to the same
+// database using Postgres LISTEN/NOTIFY. The listener connection is
+// re-established automatically.
+type PubSub struct {
+	db       *sqlx.DB
+	listener *pq.Listener
+
+	mu            sync.RWMutex
+	subscriptions map[string][]Subscription
+}
+
+func NewPubSub(cfg config.DatabaseConfig, db *sqlx.DB) *PubSub {
+	p := &PubSub{
+		db:            db,
+		subscriptions: make(map[string][]Subscription),
+	}
+	p.listener = pq.NewListener(
+		connInfo(cfg),
+		listenerMinReconnectInterval, listenerMaxReconnectInterval,
+		p.handleListenerEvent)
+
+	go p.dispatchNotifications()
+	go func() {
+		timer := time.NewTicker(listenerPingPeriod)
+		for {
+			<-timer.C
+			if err := p.listener.Ping(); err != nil {
+				slogutils.Error("ping pubsub listener", err)
+			}
+		}
+	}()
+
+	return p
+}
+
+// Subscribe starts listening to the channel. The subscription receives only
+// notifications sent after Subscribe returns.
+func (p *PubSub) Subscribe(channel Channel, subscription Subscription) error {
+	p.mu.Lock()
+	p.subscriptions[string(channel)] = append(
+		p.subscriptions[string(channel)], subscription)
+	subscribersCount := len(p.subscriptions[string(channel)])
+	p.mu.Unlock()
+
+	if subscribersCount > 1 {
+		return nil
+	}
+	err := p.listener.Listen(string(channel))
+	if err != nil {
+		return errors.Wrap(err, "listen")
+	}
+
+	return nil
+}
+
+func (p *PubSub) Publish(channel Channel, payload string) error {
+	_, err := p.db.Exec("SELECT pg_notify($1, $2)", string(channel), payload)
+	if err != nil {
+		return errors.Wrap(err, "notify")
+	}
+
+	return nil
+}
+
+// NotifyConfigReload asks all service instances to reload the config.
+func (p *PubSub) NotifyConfigReload(configName string) error {
+	return p.Publish(ChannelConfigReloads, configName)
+}
+
+func (p *PubSub) dispatchNotifications() {
+	for notification := range p.listener.Notify {
+		// nil is sent after reconnect, subscribers are resynced
+		// from the listener event callback
+		if notification == nil {
+			continue
+		}
+
+		p.mu.RLock()
+		subscriptions := p.subscriptions[notification.Channel]
+		p.mu.RUnlock()
+		for _, subscription := range subscriptions {
+			if subscription.OnNotification != nil {
+				subscription.OnNotification(notification.Extra)
+			}
+		}
+	}
+}
+
+// handleListenerEvent is called from the listener goroutine, so long running
+// subscriber callbacks are started in separate goroutines.
+func (p *PubSub) handleListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		slog.Warn("pubsub listener disconnected", "error", err)
+		p.forEachSubscription(func(subscription Subscription) {
+			if subscription.OnDisconnect != nil {
+				subscription.OnDisconnect()
+			}
+		})
+	case pq.ListenerEventReconnected:
+		slog.Info("pubsub listener reconnected")
+		p.forEachSubscription(func(subscription Subscription) {
+			if subscription.OnResync != nil {
+				go subscription.OnResync()
+			}
+		})
+	case pq.ListenerEventConnectionAttemptFailed:
+		slog.Warn("pubsub listener connection attempt failed", "error", err)
+	}
+}
+
+func (p *PubSub) forEachSubscription(f func(subscription Subscription)) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, subscriptions := range p.subscriptions {
+		for _, subscription := range subscriptions {
+			f(subscription)
+		}
+	}
+}
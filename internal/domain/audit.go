@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventRefreshTokenLocked AuditEventType = "refresh_token_locked"
+	AuditEventIPNotAllowed       AuditEventType = "ip_not_allowed"
+	AuditEventSessionRevoked     AuditEventType = "session_revoked"
+
+	AuditEventSessionRevokedByLink     AuditEventType = "session_revoked_by_link"
+	AuditEventAllSessionsRevokedByLink AuditEventType = "all_sessions_revoked_by_link"
+	AuditEventRevokeLinkReplayed       AuditEventType = "revoke_link_replayed"
+
+	AuditEventSessionSteppedUp AuditEventType = "session_stepped_up"
+
+	AuditEventSessionEvicted      AuditEventType = "session_evicted"
+	AuditEventSessionLimitReached AuditEventType = "session_limit_reached"
+)
+
+type AuditEvent struct {
+	Type           AuditEventType
+	UserID         uuid.UUID
+	RefreshTokenID uuid.UUID
+	// SessionID is set for events of a session, it is also known when the
+	// refresh token is not, e.g. for revocations by link.
+	SessionID uuid.UUID
+	ClientID  string
+	IP        string
+	Time      time.Time
+}
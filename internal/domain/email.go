@@ -1,7 +0,0 @@
-package domain
-
-type EmailContent struct {
-	Subject     string
-	ContentType string
-	Body        string
-}
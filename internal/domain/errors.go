@@ -1,7 +1,88 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type UnauthorizedError struct {
 	Message string
 }
 
 func (err *UnauthorizedError) Error() string { return err.Message }
+
+type NotFoundError struct {
+	Message string
+}
+
+func (err *NotFoundError) Error() string { return err.Message }
+
+type ConflictError struct {
+	Message string
+}
+
+func (err *ConflictError) Error() string { return err.Message }
+
+type IPNotAllowedError struct {
+	IP       string
+	ClientID string
+}
+
+func (err *IPNotAllowedError) Error() string {
+	return fmt.Sprintf("ip address %s is not allowed", err.IP)
+}
+
+// ChallengeRequiredError is returned if the refresh must be confirmed with
+// the one-time code sent to the user.
+type ChallengeRequiredError struct {
+	ChallengeID uuid.UUID
+}
+
+func (err *ChallengeRequiredError) Error() string {
+	return "refresh must be confirmed with the code sent to the user"
+}
+
+// MFALockedError is returned if second factor verification is locked after
+// too many failed attempts.
+type MFALockedError struct {
+	LockedUntil time.Time
+}
+
+func (err *MFALockedError) Error() string {
+	return "second factor verification is locked until " +
+		err.LockedUntil.UTC().Format(time.RFC3339)
+}
+
+// ReauthenticationRequiredError is returned if the user authenticated too
+// long ago for the operation. AuthTime is zero if it is unknown.
+type ReauthenticationRequiredError struct {
+	AuthTime time.Time
+	MaxAge   time.Duration
+}
+
+func (err *ReauthenticationRequiredError) Error() string {
+	if err.AuthTime.IsZero() {
+		return "authentication time is unknown, the user must authenticate again"
+	}
+	return fmt.Sprintf("user authenticated more than %s ago", err.MaxAge)
+}
+
+// ValidationError is returned if the request data is malformed or doesn't
+// pass verification.
+type ValidationError struct {
+	Message string
+}
+
+func (err *ValidationError) Error() string { return err.Message }
+
+// SessionLimitReachedError is returned if the user already has the maximum
+// number of sessions and new sessions are rejected.
+type SessionLimitReachedError struct {
+	MaxSessions int
+}
+
+func (err *SessionLimitReachedError) Error() string {
+	return fmt.Sprintf("user already has %d sessions", err.MaxSessions)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "net/netip"
+
+type IPAccessRuleAction string
+
+const (
+	IPAccessRuleActionAllow IPAccessRuleAction = "allow"
+	IPAccessRuleActionDeny  IPAccessRuleAction = "deny"
+)
+
+// IPAccessRule allows or denies session usage from a network. Rules with
+// empty ClientID are global and apply to all clients.
+type IPAccessRule struct {
+	ClientID string
+	Prefix   netip.Prefix
+	Action   IPAccessRuleAction
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MagicLink is a passwordless login link emailed to the user. Only hashes of
+// the link token and of the nonce kept by the requesting browser are stored,
+// so a leaked database can't be used to log in.
+type MagicLink struct {
+	TokenHash []byte
+	UserID    uuid.UUID
+	ClientID  string
+	// NonceHash binds the link to the browser which requested it, a
+	// forwarded link can't be exchanged without the nonce cookie.
+	NonceHash      []byte
+	ExpirationTime time.Time
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Authentication method references of the "amr" claim, RFC 8176.
+const (
+	AMRMFA          = "mfa"
+	AMROTP          = "otp"
+	AMRRecoveryCode = "rc"
+	AMRPassword     = "pwd"
+	AMRHardwareKey  = "hwk"
+	// AMREmail is a login with a link sent by email. It is not registered by
+	// RFC 8176.
+	AMREmail = "email"
+)
+
+// ACRStepUp is the "acr" claim of sessions stepped up with a second factor.
+const ACRStepUp = "aal2"
+
+// TOTPCredential is the TOTP second factor of the user.
+type TOTPCredential struct {
+	UserID uuid.UUID
+	// SecretEncrypted is the TOTP secret encrypted with the MFA key.
+	SecretEncrypted []byte
+	// Confirmed is false until the user proves the authenticator app got
+	// the secret.
+	Confirmed bool
+	// LastUsedStep is the last accepted time step, codes of earlier and the
+	// same steps are rejected, so a code can't be replayed.
+	LastUsedStep   int64
+	FailedAttempts int
+	// LockedUntil is zero if verification is not locked.
+	LockedUntil  time.Time
+	CreationTime time.Time
+}
+
+// TOTPEnrollment is returned to the user to add the secret to an
+// authenticator app.
+type TOTPEnrollment struct {
+	// Secret is base32 encoded without padding.
+	Secret     string
+	OTPAuthURI string
+}
+
+// PendingStepUp is a step-up verified by the backend before the session is
+// created. The session created for the user with the one-time Ticket within
+// ExpirationTime gets the AMR.
+type PendingStepUp struct {
+	Ticket         uuid.UUID
+	UserID         uuid.UUID
+	AMR            []string
+	ExpirationTime time.Time
+}
+
+// SecondFactor is a TOTP code or, if the authenticator app is lost, a
+// recovery code.
+type SecondFactor struct {
+	TOTPCode     string
+	RecoveryCode string
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationEvent is a security event users are notified about. It also
+// names the template files of the event.
+type NotificationEvent string
+
+const (
+	NotificationRefreshFromNewIP   NotificationEvent = "refresh_from_new_ip"
+	NotificationRefreshTokenLocked NotificationEvent = "refresh_token_locked"
+	// NotificationSessionEvicted is about a session ended by a new one
+	// because the user has too many sessions.
+	NotificationSessionEvicted NotificationEvent = "session_evicted"
+	// NotificationRefreshChallenge carries the one-time code of a refresh
+	// challenge. It is sent by email only and is not routed.
+	NotificationRefreshChallenge NotificationEvent = "refresh_challenge"
+	// NotificationMagicLink carries a passwordless login link. It is sent by
+	// email only and is not routed.
+	NotificationMagicLink NotificationEvent = "magic_link"
+)
+
+// NotificationChannel is a way to deliver notifications.
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelWebhook NotificationChannel = "webhook"
+	NotificationChannelSMS     NotificationChannel = "sms"
+	NotificationChannelInApp   NotificationChannel = "in_app"
+)
+
+// NotificationContent is a notification rendered for a channel. Emails use
+// all fields and are sent with HTMLBody as an alternative to TextBody if it
+// is not empty, in-app notifications use Subject and TextBody, SMS and
+// webhooks use only TextBody.
+type NotificationContent struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// RevokeLinkTokenPlaceholder stands for the token of the "This wasn't me"
+// link in rendered notifications until they are sent.
+const RevokeLinkTokenPlaceholder = "REVOKE_LINK_TOKEN"
+
+// SessionDetails describes the session a notification is about.
+type SessionDetails struct {
+	SessionID uuid.UUID
+	Time      time.Time
+	IP        string
+	UserAgent string
+	// RevokeLink is the "This wasn't me" link, it is nil if the links are
+	// disabled. Notifications are rendered with RevokeLinkTokenPlaceholder
+	// and the link is signed when they are sent, so stored notifications
+	// don't contain the token.
+	RevokeLink *RevokeLink
+	// ChallengeCode is the one-time code of a refresh challenge.
+	ChallengeCode string
+	// MagicLinkToken is the token of a passwordless login link.
+	MagicLinkToken string
+}
+
+// UserLocale is used to render notifications to the user. Empty fields mean
+// defaults.
+type UserLocale struct {
+	Language string
+	TimeZone string
+}
+
+// InAppNotification is a notification in the user inbox.
+type InAppNotification struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Title        string
+	Body         string
+	CreationTime time.Time
+	// ReadTime is zero if the notification is not read.
+	ReadTime time.Time
+}
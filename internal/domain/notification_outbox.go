@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	OutboxSent    OutboxStatus = "sent"
+	// OutboxDead is the status of notifications which failed to be sent
+	// after all attempts. They are sent again only when replayed.
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxNotification is a notification to the user, stored before it is
+// sent through the channel.
+type OutboxNotification struct {
+	ID      uuid.UUID
+	UserID  uuid.UUID
+	Channel NotificationChannel
+	Content NotificationContent
+	// RevokeLink is signed into the content in place of
+	// RevokeLinkTokenPlaceholder when the notification is sent.
+	RevokeLink      *RevokeLink
+	Status          OutboxStatus
+	Attempts        int
+	NextAttemptTime time.Time
+	LastError       string
+	CreationTime    time.Time
+}
+
+func NewOutboxNotification(
+	userID uuid.UUID, channel NotificationChannel, content NotificationContent,
+) *OutboxNotification {
+
+	now := time.Now()
+	return &OutboxNotification{
+		UserID:          userID,
+		Channel:         channel,
+		Content:         content,
+		Status:          OutboxPending,
+		NextAttemptTime: now,
+		CreationTime:    now,
+	}
+}
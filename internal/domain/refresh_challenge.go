@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshChallenge is a refresh from a new IP address waiting to be
+// confirmed with a one-time code sent to the user.
+type RefreshChallenge struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	RefreshTokenID uuid.UUID
+	IP             string
+	CodeHash       []byte
+	Attempts       int
+	CreationTime   time.Time
+	ExpirationTime time.Time
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RevokedTokenKind string
+
+const (
+	// RevokedTokenKindAccessToken revokes a single access token by its "jti".
+	RevokedTokenKindAccessToken RevokedTokenKind = "jti"
+	// RevokedTokenKindSession revokes all access tokens of the session by
+	// their "sid".
+	RevokedTokenKindSession RevokedTokenKind = "sid"
+	// RevokedTokenKindUser revokes access tokens of the user by their "sub"
+	// if they were issued before the revocation time, which is the user's
+	// tokens watermark.
+	RevokedTokenKindUser RevokedTokenKind = "user"
+)
+
+// RevokedToken is a denylist entry. It is kept until ExpirationTime when
+// every token it revokes has expired anyway. Seq orders entries in the
+// revocation feed.
+type RevokedToken struct {
+	Seq            int64
+	Kind           RevokedTokenKind
+	ID             uuid.UUID
+	RevocationTime time.Time
+	ExpirationTime time.Time
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokeLinkScope is what a "This wasn't me" link revokes.
+type RevokeLinkScope string
+
+const (
+	// RevokeLinkScopeSession revokes the session the notification is about.
+	RevokeLinkScopeSession RevokeLinkScope = "session"
+	// RevokeLinkScopeAll revokes all sessions of the user by raising the
+	// user's tokens watermark.
+	RevokeLinkScopeAll RevokeLinkScope = "all"
+)
+
+// RevokeLink is a single-use "This wasn't me" link sent in security
+// notifications.
+type RevokeLink struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	SessionID      uuid.UUID
+	ExpirationTime time.Time
+}
@@ -1,7 +1,9 @@
 package authservice
 
 import (
+	"auth/internal/config"
 	"auth/internal/domain"
+	iputils "auth/internal/utils/ip-utils"
 	jwtutils "auth/internal/utils/jwt-utils"
 	slogutils "auth/internal/utils/slog-utils"
 	"fmt"
@@ -16,32 +18,158 @@ import (
 const deleteExpiredTokensPeriod = time.Minute * 5
 
 type AuthService struct {
-	refreshTokenRepository RefreshTokenRepository
-	emailService           EmailService
-	accessTokenDuration    time.Duration
-	refreshTokenDuration   time.Duration
-	jwtPrivateKey          []byte
+	refreshTokenRepository       RefreshTokenRepository
+	auditEventRepository         AuditEventRepository
+	tokenWatermarkRepository     TokenWatermarkRepository
+	revokedTokenRepository       RevokedTokenRepository
+	notificationOutboxRepository NotificationOutboxRepository
+	notifier                     Notifier
+	usedRevokeLinkRepository     UsedRevokeLinkRepository
+	refreshChallengeRepository   RefreshChallengeRepository
+	challengeCodeSender          ChallengeCodeSender
+	pendingStepUpRepository      PendingStepUpRepository
+	ipAccessPolicy               IPAccessPolicy
+	cfg                          config.AuthConfig
+	jwtPrivateKey                []byte
+	revokeLinkSecret             []byte
+	newIPChallengeClients        map[string]bool
 }
 
 //go:generate mockery --name RefreshTokenRepository --filename refresh_token_repository.go
 type RefreshTokenRepository interface {
 	Create(token *domain.RefreshToken) (id uuid.UUID, err error)
 	GetByID(id uuid.UUID) (*domain.RefreshToken, error)
-	DeleteByID(id uuid.UUID) error
+	// GetBySessionID returns the current refresh token of the session, nil
+	// if the session has ended.
+	GetBySessionID(sessionID uuid.UUID) (*domain.RefreshToken, error)
+	// GetAllActiveByUserID returns not expired tokens of the user.
+	GetAllActiveByUserID(userID uuid.UUID) ([]domain.RefreshToken, error)
+	// Rotate replaces the refresh token with a new one and stores emails to
+	// the user in the same transaction. newToken.ID must be set. It returns
+	// false if the token has already been rotated or revoked, so a refresh
+	// token can be used only once.
+	Rotate(
+		id uuid.UUID, newToken *domain.RefreshToken, notifications []*domain.OutboxNotification,
+	) (bool, error)
+	// RevokeSession deletes refresh tokens of the session and denylists
+	// access tokens of the session until revokedUntil. It returns false if
+	// the session has already ended.
+	RevokeSession(sessionID uuid.UUID, revokedUntil time.Time) (bool, error)
+	IncrementFailedAttempts(id uuid.UUID) (failedAttempts int, err error)
+	// SetAuthContext sets ACR and the auth time and adds AMR to the methods
+	// of the token, they are carried into access tokens issued with the
+	// token. It returns false if the token is not found.
+	SetAuthContext(id uuid.UUID, acr string, amr []string, authTime time.Time) (bool, error)
 	DeleteAllExpired() error
 }
 
-//go:generate mockery --name EmailService --filename email_service.go
-type EmailService interface {
-	SendSupportEmailToUser(userID uuid.UUID, emailContent domain.EmailContent) error
+//go:generate mockery --name AuditEventRepository --filename audit_event_repository.go
+type AuditEventRepository interface {
+	Create(event *domain.AuditEvent) error
+}
+
+//go:generate mockery --name TokenWatermarkRepository --filename token_watermark_repository.go
+type TokenWatermarkRepository interface {
+	GetNotBefore(userID uuid.UUID) (time.Time, error)
+	// RaiseNotBefore publishes the watermark in the revocation feed until
+	// revokedUntil, when access tokens issued before it have expired.
+	RaiseNotBefore(userID uuid.UUID, notBefore, revokedUntil time.Time) error
+}
+
+//go:generate mockery --name RevokedTokenRepository --filename revoked_token_repository.go
+type RevokedTokenRepository interface {
+	Create(token *domain.RevokedToken) error
+	IsRevoked(jti, sessionID uuid.UUID) (bool, error)
+	GetAllActive() ([]domain.RevokedToken, error)
+	GetAfter(seq int64, limit int) ([]domain.RevokedToken, error)
+	GetLastSeq() (int64, error)
+	DeleteAllExpired() error
+}
+
+//go:generate mockery --name IPAccessPolicy --filename ip_access_policy.go
+type IPAccessPolicy interface {
+	CheckIP(clientID, ip string) error
+}
+
+//go:generate mockery --name NotificationOutboxRepository --filename notification_outbox_repository.go
+type NotificationOutboxRepository interface {
+	Create(notifications []*domain.OutboxNotification) error
+}
+
+// Notifier renders notifications about the event for the channels the event
+// is routed to. They are sent once stored in the outbox.
+//
+//go:generate mockery --name Notifier --filename notifier.go
+type Notifier interface {
+	NewNotifications(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) []*domain.OutboxNotification
+}
+
+// UsedRevokeLinkRepository stores IDs of used "This wasn't me" links until
+// the links expire.
+//
+//go:generate mockery --name UsedRevokeLinkRepository --filename used_revoke_link_repository.go
+type UsedRevokeLinkRepository interface {
+	// Use marks the link used. It returns false if the link is already used.
+	Use(id uuid.UUID, expirationTime time.Time) (bool, error)
+	IsUsed(id uuid.UUID) (bool, error)
+	DeleteAllExpired() error
+}
+
+// RefreshChallengeRepository stores challenges of refreshes which must be
+// confirmed with a one-time code.
+//
+//go:generate mockery --name RefreshChallengeRepository --filename refresh_challenge_repository.go
+type RefreshChallengeRepository interface {
+	Create(challenge *domain.RefreshChallenge) error
+	GetByID(id uuid.UUID) (*domain.RefreshChallenge, error)
+	// GetOpen returns the unexpired challenge of the refresh token from the
+	// IP address or nil if there is none.
+	GetOpen(refreshTokenID uuid.UUID, ip string) (*domain.RefreshChallenge, error)
+	IncrementAttempts(id uuid.UUID) (attempts int, err error)
+	// Delete returns false if the challenge is already deleted, so a
+	// challenge can be completed only once.
+	Delete(id uuid.UUID) (bool, error)
+	DeleteAllExpired() error
+}
+
+// ChallengeCodeSender renders and sends one-time codes of refresh
+// challenges. It is the email notification channel.
+//
+//go:generate mockery --name ChallengeCodeSender --filename challenge_code_sender.go
+type ChallengeCodeSender interface {
+	Render(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) (domain.NotificationContent, error)
+	Send(userID uuid.UUID, content domain.NotificationContent) error
+}
+
+// PendingStepUpRepository stores step-ups waiting for the session created
+// with their ticket.
+//
+//go:generate mockery --name PendingStepUpRepository --filename pending_step_up_repository.go
+type PendingStepUpRepository interface {
+	Save(stepUp *domain.PendingStepUp) error
+	// Take deletes and returns the not expired step-up of the user with the
+	// ticket, nil if there is none.
+	Take(ticket, userID uuid.UUID) (*domain.PendingStepUp, error)
+	DeleteAllExpired() error
 }
 
 func NewAuthService(
+	cfg config.AuthConfig,
 	refershTokenRepository RefreshTokenRepository,
-	emailService EmailService,
-	jwtPrivateKey []byte,
-	accessTokenDuration time.Duration,
-	refreshTokenDuration time.Duration,
+	auditEventRepository AuditEventRepository,
+	tokenWatermarkRepository TokenWatermarkRepository,
+	revokedTokenRepository RevokedTokenRepository,
+	notificationOutboxRepository NotificationOutboxRepository,
+	notifier Notifier,
+	usedRevokeLinkRepository UsedRevokeLinkRepository,
+	refreshChallengeRepository RefreshChallengeRepository,
+	challengeCodeSender ChallengeCodeSender,
+	pendingStepUpRepository PendingStepUpRepository,
+	ipAccessPolicy IPAccessPolicy,
 ) *AuthService {
 
 	go func() {
@@ -52,50 +180,174 @@ func NewAuthService(
 			if err != nil {
 				slogutils.Error("delete all expired refresh tokens", err)
 			}
+			err = revokedTokenRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired revoked tokens", err)
+			}
+			err = usedRevokeLinkRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired used revoke links", err)
+			}
+			err = refreshChallengeRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired refresh challenges", err)
+			}
+			err = pendingStepUpRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired pending step-ups", err)
+			}
 		}
 	}()
 
+	newIPChallengeClients := make(map[string]bool)
+	for _, clientID := range cfg.NewIPChallengeClients {
+		newIPChallengeClients[clientID] = true
+	}
+
 	return &AuthService{
-		refreshTokenRepository: refershTokenRepository,
-		emailService:           emailService,
-		accessTokenDuration:    accessTokenDuration,
-		refreshTokenDuration:   refreshTokenDuration,
-		jwtPrivateKey:          jwtPrivateKey,
+		refreshTokenRepository:       refershTokenRepository,
+		auditEventRepository:         auditEventRepository,
+		tokenWatermarkRepository:     tokenWatermarkRepository,
+		revokedTokenRepository:       revokedTokenRepository,
+		notificationOutboxRepository: notificationOutboxRepository,
+		notifier:                     notifier,
+		usedRevokeLinkRepository:     usedRevokeLinkRepository,
+		refreshChallengeRepository:   refreshChallengeRepository,
+		challengeCodeSender:          challengeCodeSender,
+		pendingStepUpRepository:      pendingStepUpRepository,
+		ipAccessPolicy:               ipAccessPolicy,
+		cfg:                          cfg,
+		jwtPrivateKey:                []byte(cfg.JWTPrivateKey),
+		revokeLinkSecret:             []byte(cfg.RevokeLinkSecret),
+		newIPChallengeClients:        newIPChallengeClients,
 	}
 }
 
+// CreateSession creates a session of the user who has just authenticated.
+// The authentication time and methods and the lifetime profile are kept
+// through refreshes of the session. An unknown requested lifetime profile or
+// an invalid or expired step-up ticket is *domain.ValidationError. If the user has the maximum number of sessions,
+// the session limit policy either rejects the session with
+// *domain.SessionLimitReachedError or evicts other sessions.
 func (s *AuthService) CreateSession(
-	userID uuid.UUID, requestIP string,
+	userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
 ) (*domain.Session, error) {
 
+	err := s.checkIPAccess(userID, uuid.Nil, clientID, requestIP)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(userID, clientID, requestIP, options)
+}
+
+func (s *AuthService) createSession(
+	userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
+) (*domain.Session, error) {
+
+	lifetimeProfile, err := s.lifetimeProfile(clientID, options)
+	if err != nil {
+		return nil, err
+	}
+	var stepUp *domain.PendingStepUp
+	if options.StepUpTicket != uuid.Nil {
+		stepUp, err = s.pendingStepUpRepository.Take(options.StepUpTicket, userID)
+		if err != nil {
+			return nil, errors.Wrap(err, "take pending step-up")
+		}
+		if stepUp == nil {
+			return nil, &domain.ValidationError{
+				Message: "step-up ticket is invalid or expired"}
+		}
+	}
+	now := time.Now()
+	toEvict, err := s.sessionsToEvict(userID, clientID, requestIP, now)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshTokenValue, err := s.newRefreshToken(
+		userID, clientID, lifetimeProfile, now, now)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken.SessionID = uuid.New()
+	refreshToken.AuthTime = now
+	refreshToken.AMR = options.AMR
+	if stepUp != nil {
+		refreshToken.ACR = domain.ACRStepUp
+		refreshToken.AMR = mergeAMR(options.AMR, stepUp.AMR)
+	}
+	refreshToken.ID, err = s.refreshTokenRepository.Create(refreshToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "save refresh token")
+	}
+	evicted := s.evictSessions(userID, toEvict, requestIP, now)
+	s.notifySessionsEvicted(userID, evicted, refreshToken.SessionID, requestIP, now)
+
+	return s.newSession(refreshToken, refreshTokenValue, requestIP, now)
+}
+
+func (s *AuthService) newRefreshToken(
+	userID uuid.UUID, clientID, lifetimeProfile string, sessionStartTime, now time.Time,
+) (*domain.RefreshToken, []byte, error) {
+
 	refreshTokenValueBytes, err := generateRefreshTokenValueBytes()
 	if err != nil {
-		return nil, errors.Wrap(err, "generate refresh token bytes")
+		return nil, nil, errors.Wrap(err, "generate refresh token bytes")
 	}
 	refreshTokenHash, err := bcrypt.
 		GenerateFromPassword(refreshTokenValueBytes, bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.Wrap(err, "hash refresh token")
+		return nil, nil, errors.Wrap(err, "hash refresh token")
 	}
 
-	refreshTokenExpTime := time.Now().Add(s.refreshTokenDuration)
 	refreshToken := &domain.RefreshToken{
-		ValueHash:      refreshTokenHash,
-		ExpirationTime: refreshTokenExpTime,
-	}
-	refreshTokenID, err := s.refreshTokenRepository.Create(refreshToken)
-	if err != nil {
-		return nil, errors.Wrap(err, "save refresh token")
+		UserID:           userID,
+		ClientID:         clientID,
+		ValueHash:        refreshTokenHash,
+		CreationTime:     now,
+		SessionStartTime: sessionStartTime,
+		LifetimeProfile:  lifetimeProfile,
 	}
+	refreshToken.ExpirationTime = s.refreshTokenExpirationTime(refreshToken, now)
 
-	accessTokenExpTime := time.Now().Add(s.accessTokenDuration).Unix()
-	accessTokenJWT := jwt.NewWithClaims(accessTokenJWTSigningMethod,
-		jwt.MapClaims{
-			UserIDJWTClaimName:         userID.String(),
-			UserIPJWTClaimName:         requestIP,
-			ExpirationTimeJWTClaimName: accessTokenExpTime,
-			RefreshTokenIDJWTClaimName: refreshTokenID,
-		})
+	return refreshToken, refreshTokenValueBytes, nil
+}
+
+// newSession signs an access token for the stored refresh token.
+func (s *AuthService) newSession(
+	refreshToken *domain.RefreshToken, refreshTokenValue []byte,
+	requestIP string, now time.Time,
+) (*domain.Session, error) {
+
+	accessTokenDuration, _ := s.tokenDurations(refreshToken.LifetimeProfile)
+	accessTokenExpTime := now.Add(accessTokenDuration)
+	// access tokens don't outlive the session
+	if maxAge, _ := s.sessionLimits(refreshToken.ClientID); maxAge > 0 {
+		sessionEndTime := refreshToken.SessionStartTime.Add(maxAge)
+		if sessionEndTime.Before(accessTokenExpTime) {
+			accessTokenExpTime = sessionEndTime
+		}
+	}
+	claims := jwt.MapClaims{
+		TokenIDJWTClaimName:        uuid.New().String(),
+		UserIDJWTClaimName:         refreshToken.UserID.String(),
+		UserIPJWTClaimName:         requestIP,
+		IssuedAtJWTClaimName:       float64(now.UnixMicro()) / 1e6,
+		ExpirationTimeJWTClaimName: accessTokenExpTime.Unix(),
+		RefreshTokenIDJWTClaimName: refreshToken.ID,
+		SessionIDJWTClaimName:      refreshToken.SessionID,
+	}
+	if !refreshToken.AuthTime.IsZero() {
+		claims[AuthTimeJWTClaimName] = refreshToken.AuthTime.Unix()
+	}
+	if refreshToken.ACR != "" {
+		claims[ACRJWTClaimName] = refreshToken.ACR
+	}
+	if len(refreshToken.AMR) > 0 {
+		claims[AMRJWTClaimName] = refreshToken.AMR
+	}
+	accessTokenJWT := jwt.NewWithClaims(accessTokenJWTSigningMethod, claims)
 	accessTokenStr, err := accessTokenJWT.SignedString(s.jwtPrivateKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "sign access token")
@@ -103,28 +355,28 @@ func (s *AuthService) CreateSession(
 
 	return &domain.Session{
 		AccessTokenSigned: []byte(accessTokenStr),
-		RefreshTokenValue: refreshTokenValueBytes,
+		RefreshTokenValue: refreshTokenValue,
 	}, nil
 }
 
+// RefreshSession rotates the refresh token of the session. A refresh from a
+// new IP address of a client in the new IP challenge list returns
+// *domain.ChallengeRequiredError, the refresh is then completed by
+// CompleteRefreshChallenge.
 func (s *AuthService) RefreshSession(
-	session *domain.Session, requestIP string,
+	session *domain.Session, requestIP, userAgent string,
 ) (*domain.Session, error) {
 
-	accessTokenJWT, err := jwtutils.
-		ParseAndValidateJWTToken(
-			session.AccessTokenSigned,
-			s.jwtPrivateKey,
-			accessTokenJWTSigningMethod.Name)
-	if err != nil {
-		return nil, &domain.UnauthorizedError{
-			Message: fmt.Sprintf("parse access token: %s", err)}
-	}
+	return s.refreshSession(session, requestIP, userAgent, false)
+}
 
-	accessToken, err := parseAccessTokenFromJWT(accessTokenJWT)
+func (s *AuthService) refreshSession(
+	session *domain.Session, requestIP, userAgent string, challengePassed bool,
+) (*domain.Session, error) {
+
+	accessToken, err := s.parseAccessToken(session.AccessTokenSigned)
 	if err != nil {
-		return nil, &domain.UnauthorizedError{
-			Message: fmt.Sprintf("parse access token: %s", err)}
+		return nil, err
 	}
 
 	refreshToken, err := s.refreshTokenRepository.
@@ -139,32 +391,268 @@ func (s *AuthService) RefreshSession(
 			Message: "refresh token not found"}
 	}
 
+	err = s.checkSessionLimits(refreshToken, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkTokensNotBefore(
+		accessToken.UserID,
+		accessToken.IssuedAt, refreshToken.CreationTime)
+	if err != nil {
+		return nil, err
+	}
+
 	if bcrypt.CompareHashAndPassword(
 		refreshToken.ValueHash,
 		session.RefreshTokenValue,
 	) != nil {
+		err := s.registerFailedRefreshAttempt(accessToken, requestIP, userAgent)
+		if err != nil {
+			return nil, errors.Wrap(err, "register failed refresh attempt")
+		}
 		return nil, &domain.UnauthorizedError{
 			Message: "refresh token is invalid"}
 	}
 
-	err = s.refreshTokenRepository.DeleteByID(refreshToken.ID)
+	err = s.checkIPAccess(
+		accessToken.UserID, refreshToken.ID,
+		refreshToken.ClientID, requestIP)
 	if err != nil {
-		return nil, errors.Wrap(err, "delete refresh token")
+		return nil, err
 	}
 
-	if requestIP != accessToken.UserIP {
-		go func() {
-			err := s.emailService.SendSupportEmailToUser(
-				accessToken.UserID,
-				newEmailContentRefreshFromNewIPWarning(requestIP))
-			if err != nil {
-				slogutils.Error("send warning email(refresh from new ip) error", err)
-			}
-		}()
+	newIP := iputils.Normalize(requestIP) != iputils.Normalize(accessToken.UserIP)
+	if newIP && !challengePassed && s.newIPChallengeClients[refreshToken.ClientID] {
+		return nil, s.newRefreshChallenge(accessToken.UserID, refreshToken, requestIP, userAgent)
 	}
 
-	return s.CreateSession(
-		accessToken.UserID,
-		requestIP,
-	)
+	now := time.Now()
+	newRefreshToken, newRefreshTokenValue, err := s.newRefreshToken(
+		accessToken.UserID, refreshToken.ClientID, refreshToken.LifetimeProfile,
+		refreshToken.SessionStartTime, now)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken.ID = uuid.New()
+	newRefreshToken.SessionID = refreshToken.SessionID
+	newRefreshToken.AuthTime = refreshToken.AuthTime
+	newRefreshToken.ACR = refreshToken.ACR
+	newRefreshToken.AMR = refreshToken.AMR
+
+	var notifications []*domain.OutboxNotification
+	if newIP {
+		notifications = s.notifier.NewNotifications(
+			accessToken.UserID, domain.NotificationRefreshFromNewIP,
+			domain.SessionDetails{
+				SessionID: newRefreshToken.SessionID,
+				Time:      now,
+				IP:        requestIP,
+				UserAgent: userAgent,
+				RevokeLink: s.newRevokeLink(
+					accessToken.UserID, newRefreshToken.SessionID, now),
+			})
+	}
+	rotated, err := s.refreshTokenRepository.
+		Rotate(refreshToken.ID, newRefreshToken, notifications)
+	if err != nil {
+		return nil, errors.Wrap(err, "rotate refresh token")
+	}
+	if !rotated {
+		// a concurrent refresh or revocation has used the token up
+		return nil, &domain.UnauthorizedError{
+			Message: "refresh token not found"}
+	}
+
+	return s.newSession(newRefreshToken, newRefreshTokenValue, requestIP, now)
+}
+
+// checkIPAccess checks the IP address against IP access rules of the client
+// and writes an audit event if the address is rejected.
+func (s *AuthService) checkIPAccess(
+	userID uuid.UUID, refreshTokenID uuid.UUID,
+	clientID string, requestIP string,
+) error {
+
+	err := s.ipAccessPolicy.CheckIP(clientID, requestIP)
+	if err == nil {
+		return nil
+	}
+
+	auditErr := s.auditEventRepository.Create(&domain.AuditEvent{
+		Type:           domain.AuditEventIPNotAllowed,
+		UserID:         userID,
+		RefreshTokenID: refreshTokenID,
+		ClientID:       clientID,
+		IP:             requestIP,
+		Time:           time.Now(),
+	})
+	if auditErr != nil {
+		slogutils.Error("create audit event(ip not allowed) error", auditErr)
+	}
+
+	return err
+}
+
+// ValidateAccessToken checks the access token signature, expiration time,
+// the user's tokens watermark and the revoked tokens denylist.
+func (s *AuthService) ValidateAccessToken(
+	accessTokenSigned []byte,
+) (*domain.AccessToken, error) {
+
+	accessToken, err := s.parseAccessToken(accessTokenSigned)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkTokensNotBefore(accessToken.UserID, accessToken.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.revokedTokenRepository.
+		IsRevoked(accessToken.ID, accessToken.SessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "check token revocation")
+	}
+	if revoked {
+		return nil, &domain.UnauthorizedError{Message: "token is revoked"}
+	}
+
+	return accessToken, nil
+}
+
+// RequireRecentAuthentication returns *domain.ReauthenticationRequiredError
+// if the user authenticated more than maxAge ago or the authentication time
+// of the token is unknown.
+func (s *AuthService) RequireRecentAuthentication(
+	accessToken *domain.AccessToken, maxAge time.Duration,
+) error {
+
+	if accessToken.AuthTime.IsZero() || time.Since(accessToken.AuthTime) > maxAge {
+		return &domain.ReauthenticationRequiredError{
+			AuthTime: accessToken.AuthTime,
+			MaxAge:   maxAge,
+		}
+	}
+
+	return nil
+}
+
+// RaiseUserTokensNotBefore invalidates all access and refresh tokens of the
+// user issued before notBefore. The watermark is never moved back.
+func (s *AuthService) RaiseUserTokensNotBefore(
+	userID uuid.UUID, notBefore time.Time,
+) error {
+
+	err := s.tokenWatermarkRepository.
+		RaiseNotBefore(userID, notBefore, notBefore.Add(s.maxAccessTokenDuration()))
+	if err != nil {
+		return errors.Wrap(err, "raise tokens watermark")
+	}
+
+	return nil
+}
+
+func (s *AuthService) parseAccessToken(
+	accessTokenSigned []byte,
+) (*domain.AccessToken, error) {
+
+	accessTokenJWT, err := jwtutils.
+		ParseAndValidateJWTToken(
+			accessTokenSigned,
+			s.jwtPrivateKey,
+			accessTokenJWTSigningMethod.Name)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{
+			Message: fmt.Sprintf("parse access token: %s", err)}
+	}
+
+	accessToken, err := parseAccessTokenFromJWT(accessTokenJWT)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{
+			Message: fmt.Sprintf("parse access token: %s", err)}
+	}
+
+	return accessToken, nil
+}
+
+// checkTokensNotBefore returns *domain.UnauthorizedError if any of the tokens
+// was issued before the user's tokens watermark. Access tokens carry "iat"
+// with microseconds, so tokens issued earlier in the second the watermark was
+// raised in are rejected, while tokens issued right after it stay valid.
+func (s *AuthService) checkTokensNotBefore(
+	userID uuid.UUID, issueTimes ...time.Time,
+) error {
+
+	notBefore, err := s.tokenWatermarkRepository.GetNotBefore(userID)
+	if err != nil {
+		return errors.Wrap(err, "get tokens watermark")
+	}
+	for _, issueTime := range issueTimes {
+		if issueTime.Before(notBefore) {
+			return &domain.UnauthorizedError{
+				Message: "token was issued before user's tokens watermark"}
+		}
+	}
+
+	return nil
+}
+
+// registerFailedRefreshAttempt counts a refresh attempt with a wrong refresh
+// token value and revokes the refresh token once the attempts limit is reached,
+// so a known refresh token ID can't be used for online guessing.
+func (s *AuthService) registerFailedRefreshAttempt(
+	accessToken *domain.AccessToken, requestIP, userAgent string,
+) error {
+
+	failedAttempts, err := s.refreshTokenRepository.
+		IncrementFailedAttempts(accessToken.RefreshTokenID)
+	if err != nil {
+		return errors.Wrap(err, "increment failed attempts")
+	}
+	if failedAttempts < s.cfg.MaxFailedRefreshAttempts {
+		return nil
+	}
+
+	err = s.revokeSession(accessToken)
+	if err != nil {
+		return errors.Wrap(err, "revoke session")
+	}
+	if failedAttempts > s.cfg.MaxFailedRefreshAttempts {
+		// token is already locked by a concurrent attempt
+		return nil
+	}
+
+	now := time.Now()
+	err = s.auditEventRepository.Create(&domain.AuditEvent{
+		Type:           domain.AuditEventRefreshTokenLocked,
+		UserID:         accessToken.UserID,
+		RefreshTokenID: accessToken.RefreshTokenID,
+		SessionID:      accessToken.SessionID,
+		IP:             requestIP,
+		Time:           now,
+	})
+	if err != nil {
+		slogutils.Error("create audit event(refresh token locked) error", err)
+	}
+
+	notifications := s.notifier.NewNotifications(
+		accessToken.UserID, domain.NotificationRefreshTokenLocked,
+		domain.SessionDetails{
+			SessionID: accessToken.SessionID,
+			Time:      now,
+			IP:        requestIP,
+			UserAgent: userAgent,
+			RevokeLink: s.newRevokeLink(
+				accessToken.UserID, accessToken.SessionID, now),
+		})
+	if len(notifications) > 0 {
+		err = s.notificationOutboxRepository.Create(notifications)
+		if err != nil {
+			slogutils.Error("enqueue notifications(refresh token locked) error", err)
+		}
+	}
+
+	return nil
 }
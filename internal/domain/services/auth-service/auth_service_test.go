@@ -4,38 +4,71 @@ import (
 	"testing"
 	"time"
 
+	"auth/internal/config"
 	"auth/internal/domain"
 	"auth/internal/domain/services/auth-service/mocks"
 	jwtutils "auth/internal/utils/jwt-utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	jwtPrivateKey        = []byte("private-key")
-	accessTokenDuration  = time.Hour * 2
-	refreshTokenDuration = time.Hour * 12
+	jwtPrivateKey            = []byte("private-key")
+	accessTokenDuration      = time.Hour * 2
+	refreshTokenDuration     = time.Hour * 12
+	maxFailedRefreshAttempts = 3
+	revokeLinkDuration       = time.Hour * 72
+	challengeClientID        = "strict"
+	maxChallengeAttempts     = 3
 
-	userID = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
-	userIP = "127.0.0.1"
+	authConfig = config.AuthConfig{
+		AccessTokenDuration:      accessTokenDuration,
+		RefreshTokenDuration:     refreshTokenDuration,
+		JWTPrivateKey:            string(jwtPrivateKey),
+		MaxFailedRefreshAttempts: maxFailedRefreshAttempts,
+		RevokeLinkSecret:         "revoke-link-secret",
+		RevokeLinkDuration:       revokeLinkDuration,
+		NewIPChallengeClients:    []string{challengeClientID},
+		ChallengeCodeDuration:    time.Minute * 5,
+		MaxChallengeAttempts:     maxChallengeAttempts,
+	}
+
+	userID    = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
+	userIP    = "127.0.0.1"
+	userAgent = "Mozilla/5.0"
+	clientID  = "web"
+
+	notificationContent = domain.NotificationContent{Subject: "subject", TextBody: "body"}
 
 	refreshTokenID        = uuid.MustParse("3e02eeb9-de9a-4e0a-857b-1293c25bd776")
+	sessionID             = uuid.MustParse("5c0e8a4e-1f6b-4d8e-9b7a-2f3c4d5e6f70")
 	refreshTokenValue     = []byte{71, 34, 18, 186, 54, 175, 79, 64, 150, 16, 134, 201, 147, 39, 67, 45}
 	refreshTokenValueHash = MustGenerateBcryptHashFromPassword(refreshTokenValue, bcrypt.DefaultCost)
 	refreshToken          = domain.RefreshToken{
 		ID:             refreshTokenID,
+		SessionID:      sessionID,
+		UserID:         userID,
+		ClientID:       clientID,
 		ValueHash:      refreshTokenValueHash,
+		CreationTime:   accessTokenIssuedAt,
 		ExpirationTime: time.Now().Add(refreshTokenDuration)}
 
-	accessTokenJWT = jwt.NewWithClaims(accessTokenJWTSigningMethod,
+	accessTokenID       = uuid.MustParse("0b3a54e4-4f7d-4c43-a7e4-0d8b2e0f2d53")
+	accessTokenIssuedAt = time.Now().Add(-time.Minute)
+	accessTokenExpTime  = time.Now().Add(accessTokenDuration)
+	accessTokenJWT      = jwt.NewWithClaims(accessTokenJWTSigningMethod,
 		jwt.MapClaims{
+			TokenIDJWTClaimName:        accessTokenID.String(),
 			UserIDJWTClaimName:         userID.String(),
 			UserIPJWTClaimName:         userIP,
-			ExpirationTimeJWTClaimName: time.Now().Add(accessTokenDuration).Unix(),
+			IssuedAtJWTClaimName:       accessTokenIssuedAt.Unix(),
+			ExpirationTimeJWTClaimName: accessTokenExpTime.Unix(),
 			RefreshTokenIDJWTClaimName: refreshTokenID,
 		})
 	accessTokenSigned = []byte(mustSignJWTString(accessTokenJWT, jwtPrivateKey))
@@ -48,12 +81,21 @@ var (
 
 func TestCreateSession_Success(t *testing.T) {
 	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	amr := []string{domain.AMRPassword}
 	refreshTokenRepository.
-		On("Create", mock.AnythingOfType("*domain.RefreshToken")).
+		On("Create", mock.MatchedBy(func(token *domain.RefreshToken) bool {
+			return token.UserID == userID && token.ClientID == clientID &&
+				!token.AuthTime.IsZero() && assert.ObjectsAreEqual(amr, token.AMR)
+		})).
 		Return(refreshTokenID, nil)
 	startTime := time.Now().Truncate(time.Second) // truncate time since jwt claim "exp" truncates it to seconds
 
-	session, err := service.CreateSession(userID, userIP)
+	session, err := service.CreateSession(
+		userID, clientID, userIP, domain.SessionOptions{AMR: amr})
 
 	assert.NoError(t, err)
 	accessTokenJWT, err := jwtutils.ParseAndValidateJWTToken(
@@ -71,39 +113,512 @@ func TestCreateSession_Success(t *testing.T) {
 	accessExpTime, err := jwtutils.GetTimeJWTClaim(claimsMap, ExpirationTimeJWTClaimName)
 	assert.NoError(t, err)
 	assert.True(t, !accessExpTime.Before(startTime.Add(accessTokenDuration))) // use !Before instead of After because time is truncated to seconds and two values can be equal
+	issuedAt, err := jwtutils.GetTimeJWTClaim(claimsMap, IssuedAtJWTClaimName)
+	assert.NoError(t, err)
+	assert.True(t, !issuedAt.Before(startTime))
 	refreshTokenIDClaim, err := jwtutils.GetStringJWTClaim(claimsMap, RefreshTokenIDJWTClaimName)
 	assert.NoError(t, err)
 	_, err = uuid.Parse(refreshTokenIDClaim)
 	assert.NoError(t, err)
+	tokenIDClaim, err := jwtutils.GetStringJWTClaim(claimsMap, TokenIDJWTClaimName)
+	assert.NoError(t, err)
+	_, err = uuid.Parse(tokenIDClaim)
+	assert.NoError(t, err)
 
 	var parsedRefreshTokenValue uuid.UUID
 	err = (&parsedRefreshTokenValue).UnmarshalBinary(session.RefreshTokenValue)
 	assert.NoError(t, err)
+	authTime, err := jwtutils.GetTimeJWTClaim(claimsMap, AuthTimeJWTClaimName)
+	assert.NoError(t, err)
+	assert.True(t, !authTime.Before(startTime))
+	amrClaim, err := jwtutils.GetStringsJWTClaim(claimsMap, AMRJWTClaimName)
+	assert.NoError(t, err)
+	assert.Equal(t, amr, amrClaim)
+	assert.NotContains(t, claimsMap, ACRJWTClaimName)
+}
+
+func TestCreateSession_PendingStepUp(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	amr := []string{domain.AMRPassword, domain.AMROTP, domain.AMRMFA}
+	stepUpTicket := uuid.New()
+	pendingStepUpRepository := service.pendingStepUpRepository.(*mocks.PendingStepUpRepository)
+	pendingStepUpRepository.
+		On("Take", stepUpTicket, userID).
+		Return(&domain.PendingStepUp{
+			Ticket: stepUpTicket, UserID: userID, AMR: []string{domain.AMROTP, domain.AMRMFA},
+		}, nil)
+	refreshTokenRepository.
+		On("Create", mock.MatchedBy(func(token *domain.RefreshToken) bool {
+			return token.ACR == domain.ACRStepUp && assert.ObjectsAreEqual(amr, token.AMR)
+		})).
+		Return(refreshTokenID, nil)
+
+	session, err := service.CreateSession(
+		userID, clientID, userIP, domain.SessionOptions{
+			AMR:          []string{domain.AMRPassword},
+			StepUpTicket: stepUpTicket,
+		})
+	require.NoError(t, err)
+
+	accessToken, err := service.parseAccessToken(session.AccessTokenSigned)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ACRStepUp, accessToken.ACR)
+	assert.Equal(t, amr, accessToken.AMR)
+}
+
+func TestCreateSession_InvalidStepUpTicket(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	// e.g. the ticket of another user or already used by another login
+	stepUpTicket := uuid.New()
+	service.pendingStepUpRepository.(*mocks.PendingStepUpRepository).
+		On("Take", stepUpTicket, userID).
+		Return(nil, nil)
+
+	_, err := service.CreateSession(userID, clientID, userIP,
+		domain.SessionOptions{StepUpTicket: stepUpTicket})
+
+	var validationError *domain.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestCreateSession_IPNotAllowed(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(&domain.IPNotAllowedError{IP: userIP, ClientID: clientID})
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventIPNotAllowed &&
+				event.UserID == userID && event.ClientID == clientID
+		})).
+		Return(nil)
+
+	var ipNotAllowedError *domain.IPNotAllowedError
+	_, err := service.CreateSession(userID, clientID, userIP, domain.SessionOptions{})
+	assert.ErrorAs(t, err, &ipNotAllowedError)
 }
 
 func TestRefreshSession_Success(t *testing.T) {
 	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
 
 	refreshTokenRepository.
 		On("GetByID", refreshToken.ID).
 		Return(&refreshToken, nil)
 	refreshTokenRepository.
-		On("DeleteByID", refreshToken.ID).
+		On("Rotate", refreshToken.ID, mock.AnythingOfType("*domain.RefreshToken"),
+			mock.MatchedBy(func(notifications []*domain.OutboxNotification) bool {
+				return len(notifications) == 0
+			})).
+		Return(true, nil)
+
+	_, err := service.RefreshSession(session, userIP, userAgent)
+	assert.NoError(t, err)
+}
+
+func TestRefreshSession_RotatedConcurrently(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
 		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID, mock.AnythingOfType("*domain.RefreshToken"),
+			mock.AnythingOfType("[]*domain.OutboxNotification")).
+		Return(false, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.RefreshSession(session, userIP, userAgent)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRefreshSession_SteppedUp(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	amr := []string{domain.AMRRecoveryCode, domain.AMRMFA}
+	authTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	steppedUpToken := refreshToken
+	steppedUpToken.AuthTime = authTime
+	steppedUpToken.ACR = domain.ACRStepUp
+	steppedUpToken.AMR = amr
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&steppedUpToken, nil)
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID,
+			mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				return token.AuthTime.Equal(authTime) &&
+					token.ACR == domain.ACRStepUp && assert.ObjectsAreEqual(amr, token.AMR)
+			}),
+			mock.Anything).
+		Return(true, nil)
+
+	newSession, err := service.RefreshSession(session, userIP, userAgent)
+	require.NoError(t, err)
+
+	accessToken, err := service.parseAccessToken(newSession.AccessTokenSigned)
+	require.NoError(t, err)
+	assert.Equal(t, authTime, accessToken.AuthTime)
+	assert.Equal(t, domain.ACRStepUp, accessToken.ACR)
+	assert.Equal(t, amr, accessToken.AMR)
+}
+
+func TestRefreshSession_SessionLimits(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.MaxSessionAge = time.Hour * 24
+	service.cfg.ClientSessionIdleTimeouts = map[string]time.Duration{clientID: time.Hour}
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	sessionStartTime := time.Now().Add(-time.Hour * 23).Truncate(time.Second)
+	activeToken := refreshToken
+	activeToken.SessionStartTime = sessionStartTime
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&activeToken, nil)
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID,
+			mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				// the idle timeout is shorter than the refresh token duration
+				// and the session ends even earlier
+				return token.SessionStartTime.Equal(sessionStartTime) &&
+					token.ExpirationTime.Equal(sessionStartTime.Add(time.Hour*24))
+			}),
+			mock.Anything).
+		Return(true, nil)
+
+	newSession, err := service.RefreshSession(session, userIP, userAgent)
+	require.NoError(t, err)
+
+	accessToken, err := service.parseAccessToken(newSession.AccessTokenSigned)
+	require.NoError(t, err)
+	assert.Equal(t, sessionStartTime.Add(time.Hour*24), accessToken.ExpTime)
+}
+
+func TestRefreshSession_SessionLimitReached(t *testing.T) {
+	for name, token := range map[string]domain.RefreshToken{
+		"max age": {
+			ID: refreshTokenID, UserID: userID, ClientID: clientID,
+			CreationTime:     time.Now().Add(-time.Minute),
+			ExpirationTime:   time.Now().Add(time.Hour),
+			SessionStartTime: time.Now().Add(-time.Hour * 25),
+		},
+		"idle timeout": {
+			ID: refreshTokenID, UserID: userID, ClientID: clientID,
+			CreationTime:     time.Now().Add(-time.Hour * 2),
+			ExpirationTime:   time.Now().Add(time.Hour),
+			SessionStartTime: time.Now().Add(-time.Hour * 2),
+		},
+	} {
+		service, refreshTokenRepository, _ := newServiceAndMocks(t)
+		service.cfg.MaxSessionAge = time.Hour * 24
+		service.cfg.SessionIdleTimeout = time.Hour
+		refreshTokenRepository.
+			On("GetByID", refreshTokenID).
+			Return(&token, nil)
+
+		_, err := service.RefreshSession(session, userIP, userAgent)
+
+		var unauthorizedError *domain.UnauthorizedError
+		assert.ErrorAs(t, err, &unauthorizedError, name)
+	}
+}
+
+func TestCreateSession_LifetimeProfile(t *testing.T) {
+	for name, test := range map[string]struct {
+		options              domain.SessionOptions
+		profile              string
+		refreshTokenDuration time.Duration
+	}{
+		"client default": {
+			profile: "mobile", refreshTokenDuration: time.Hour * 24 * 30,
+		},
+		"remember me": {
+			options: domain.SessionOptions{RememberMe: true},
+			profile: "remember_me", refreshTokenDuration: time.Hour * 24 * 60,
+		},
+		"requested": {
+			options: domain.SessionOptions{LifetimeProfile: "cli", RememberMe: true},
+			profile: "cli", refreshTokenDuration: time.Hour * 24 * 45,
+		},
+	} {
+		service, refreshTokenRepository, _ := newServiceAndMocks(t)
+		service.cfg.ProfileAccessTokenDurations = map[string]time.Duration{"cli": time.Hour}
+		service.cfg.ProfileRefreshTokenDurations = map[string]time.Duration{
+			"mobile":      time.Hour * 24 * 30,
+			"remember_me": time.Hour * 24 * 365,
+			"cli":         time.Hour * 24 * 45,
+		}
+		service.cfg.ClientLifetimeProfiles = map[string]string{clientID: "mobile"}
+		service.cfg.RememberMeLifetimeProfile = "remember_me"
+		service.cfg.MaxRefreshTokenDuration = time.Hour * 24 * 60
+		service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+			On("CheckIP", clientID, userIP).
+			Return(nil)
+		refreshTokenRepository.
+			On("Create", mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				return token.LifetimeProfile == test.profile &&
+					token.ExpirationTime.Sub(token.CreationTime) == test.refreshTokenDuration
+			})).
+			Return(refreshTokenID, nil)
+
+		_, err := service.CreateSession(userID, clientID, userIP, test.options)
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestCreateSession_UnknownLifetimeProfile(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+
+	_, err := service.CreateSession(userID, clientID, userIP,
+		domain.SessionOptions{LifetimeProfile: "unknown"})
+
+	var validationError *domain.ValidationError
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestCreateSession_EvictsSessions(t *testing.T) {
+	now := time.Now()
+	// ordered by the last refresh like the repository returns them
+	sessions := []domain.RefreshToken{
+		{ID: uuid.New(), SessionID: uuid.New(),
+			CreationTime: now.Add(-time.Hour * 3), SessionStartTime: now.Add(-time.Hour * 5)},
+		{ID: uuid.New(), SessionID: uuid.New(),
+			CreationTime: now.Add(-time.Hour * 2), SessionStartTime: now.Add(-time.Hour * 24)},
+		{ID: uuid.New(), SessionID: uuid.New(),
+			CreationTime: now.Add(-time.Hour), SessionStartTime: now.Add(-time.Hour * 48)},
+	}
+	for policy, evicted := range map[config.SessionLimitPolicy]domain.RefreshToken{
+		config.SessionLimitEvictOldest:                 sessions[2],
+		config.SessionLimitEvictLeastRecentlyRefreshed: sessions[0],
+	} {
+		service, refreshTokenRepository, notificationOutboxRepository := newServiceAndMocks(t)
+		service.cfg.MaxSessionsPerUser = 3
+		service.cfg.SessionLimitPolicy = policy
+		service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+			On("CheckIP", clientID, userIP).
+			Return(nil)
+		service.auditEventRepository.(*mocks.AuditEventRepository).
+			On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+				return event.Type == domain.AuditEventSessionEvicted &&
+					event.RefreshTokenID == evicted.ID
+			})).
+			Return(nil)
+		refreshTokenRepository.
+			On("GetAllActiveByUserID", userID).
+			Return(append([]domain.RefreshToken(nil), sessions...), nil)
+		refreshTokenRepository.
+			On("RevokeSession", evicted.SessionID, mock.AnythingOfType("time.Time")).
+			Return(true, nil)
+		var newSessionID uuid.UUID
+		refreshTokenRepository.
+			On("Create", mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				newSessionID = token.SessionID
+				return true
+			})).
+			Return(refreshTokenID, nil)
+		notifier := service.notifier.(*mocks.Notifier)
+		notifier.
+			On("NewNotifications", userID, domain.NotificationSessionEvicted,
+				mock.AnythingOfType("domain.SessionDetails")).
+			Return([]*domain.OutboxNotification{domain.NewOutboxNotification(
+				userID, domain.NotificationChannelEmail, notificationContent)})
+		notificationOutboxRepository.
+			On("Create", mock.MatchedBy(func(notifications []*domain.OutboxNotification) bool {
+				return len(notifications) == 1
+			})).
+			Return(nil)
+
+		_, err := service.CreateSession(userID, clientID, userIP, domain.SessionOptions{})
+		require.NoError(t, err, policy)
+
+		sessionDetails := notifier.Calls[0].Arguments.Get(2).(domain.SessionDetails)
+		assert.Equal(t, evicted.SessionID, sessionDetails.SessionID, policy)
+		link := sessionDetails.RevokeLink
+		if assert.NotNil(t, link, policy) {
+			// the link ends the new session
+			assert.Equal(t, newSessionID, link.SessionID, policy)
+		}
+	}
+}
+
+func TestCreateSession_NotEvictedIfCreateFails(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.MaxSessionsPerUser = 1
+	service.cfg.SessionLimitPolicy = config.SessionLimitEvictOldest
+	service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	refreshTokenRepository.
+		On("GetAllActiveByUserID", userID).
+		Return([]domain.RefreshToken{refreshToken}, nil)
 	refreshTokenRepository.
 		On("Create", mock.AnythingOfType("*domain.RefreshToken")).
-		Return(uuid.New(), nil)
+		Return(uuid.Nil, errors.New("db is down"))
+
+	_, err := service.CreateSession(userID, clientID, userIP, domain.SessionOptions{})
+
+	assert.Error(t, err)
+	refreshTokenRepository.AssertNotCalled(t, "RevokeSession",
+		mock.Anything, mock.Anything)
+}
+
+func TestCreateSession_SessionLimitReached(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.MaxSessionsPerUser = 1
+	service.cfg.SessionLimitPolicy = config.SessionLimitReject
+	service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	service.auditEventRepository.(*mocks.AuditEventRepository).
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventSessionLimitReached && event.UserID == userID
+		})).
+		Return(nil)
+	refreshTokenRepository.
+		On("GetAllActiveByUserID", userID).
+		Return([]domain.RefreshToken{refreshToken}, nil)
+
+	_, err := service.CreateSession(userID, clientID, userIP, domain.SessionOptions{})
+
+	var sessionLimitReachedError *domain.SessionLimitReachedError
+	assert.ErrorAs(t, err, &sessionLimitReachedError)
+}
+
+func TestRefreshSession_KeepsLifetimeProfile(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.ProfileAccessTokenDurations = map[string]time.Duration{"cli": time.Hour}
+	service.cfg.ProfileRefreshTokenDurations = map[string]time.Duration{"cli": time.Hour * 24 * 90}
+	service.ipAccessPolicy.(*mocks.IPAccessPolicy).
+		On("CheckIP", clientID, userIP).
+		Return(nil)
+	service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository).
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	cliToken := refreshToken
+	cliToken.LifetimeProfile = "cli"
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&cliToken, nil)
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID,
+			mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				return token.LifetimeProfile == "cli" &&
+					token.ExpirationTime.Sub(token.CreationTime) == time.Hour*24*90
+			}),
+			mock.Anything).
+		Return(true, nil)
+	startTime := time.Now().Truncate(time.Second)
+
+	newSession, err := service.RefreshSession(session, userIP, userAgent)
+	require.NoError(t, err)
+
+	accessToken, err := service.parseAccessToken(newSession.AccessTokenSigned)
+	require.NoError(t, err)
+	assert.False(t, accessToken.ExpTime.Before(startTime.Add(time.Hour)))
+}
+
+func TestStepUpSession_Success(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	amr := []string{domain.AMROTP, domain.AMRMFA}
+	refreshTokenRepository.
+		On("SetAuthContext", refreshTokenID, domain.ACRStepUp, amr,
+			mock.AnythingOfType("time.Time")).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventSessionSteppedUp &&
+				event.RefreshTokenID == refreshTokenID
+		})).
+		Return(nil)
+
+	err := service.StepUpSession(userID, refreshTokenID, amr)
+	assert.NoError(t, err)
+}
+
+func TestStepUpSession_NotFound(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	refreshTokenRepository.
+		On("SetAuthContext", refreshTokenID, domain.ACRStepUp, mock.Anything, mock.Anything).
+		Return(false, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := service.StepUpSession(userID, refreshTokenID, []string{domain.AMROTP})
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRequireRecentAuthentication(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	maxAge := time.Minute * 10
 
-	_, err := service.RefreshSession(session, userIP)
+	err := service.RequireRecentAuthentication(
+		&domain.AccessToken{AuthTime: time.Now().Add(-time.Minute)}, maxAge)
 	assert.NoError(t, err)
+
+	var reauthenticationRequiredError *domain.ReauthenticationRequiredError
+	err = service.RequireRecentAuthentication(
+		&domain.AccessToken{AuthTime: time.Now().Add(-time.Hour)}, maxAge)
+	assert.ErrorAs(t, err, &reauthenticationRequiredError)
+	err = service.RequireRecentAuthentication(&domain.AccessToken{}, maxAge)
+	assert.ErrorAs(t, err, &reauthenticationRequiredError)
 }
 
 func TestRefreshSession_WrongRefreshToken(t *testing.T) {
 	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
 
 	refreshTokenRepository.
 		On("GetByID", refreshToken.ID).
 		Return(&refreshToken, nil)
+	refreshTokenRepository.
+		On("IncrementFailedAttempts", refreshToken.ID).
+		Return(1, nil)
 
 	var unauthorizedError *domain.UnauthorizedError
 	_, err := service.RefreshSession(
@@ -111,7 +626,59 @@ func TestRefreshSession_WrongRefreshToken(t *testing.T) {
 			AccessTokenSigned: session.AccessTokenSigned,
 			RefreshTokenValue: append(session.RefreshTokenValue, 'a'),
 		},
-		userIP)
+		userIP, userAgent)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRefreshSession_WrongRefreshTokenLockout(t *testing.T) {
+	service, refreshTokenRepository, notificationOutboxRepository := newServiceAndMocks(t)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	refreshTokenRepository.
+		On("IncrementFailedAttempts", refreshToken.ID).
+		Return(maxFailedRefreshAttempts, nil)
+	// the access token has no "sid" claim, so its session ID is the ID of
+	// its refresh token
+	refreshTokenRepository.
+		On("GetBySessionID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	refreshTokenRepository.
+		On("RevokeSession", refreshToken.ID, mock.AnythingOfType("time.Time")).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventRefreshTokenLocked &&
+				event.RefreshTokenID == refreshToken.ID
+		})).
+		Return(nil)
+	notifications := []*domain.OutboxNotification{domain.NewOutboxNotification(
+		userID, domain.NotificationChannelEmail, notificationContent)}
+	notifier := service.notifier.(*mocks.Notifier)
+	notifier.
+		On("NewNotifications", userID, domain.NotificationRefreshTokenLocked,
+			mock.MatchedBy(func(session domain.SessionDetails) bool {
+				return session.SessionID == refreshToken.ID &&
+					session.IP == userIP && session.UserAgent == userAgent
+			})).
+		Return(notifications)
+	notificationOutboxRepository.
+		On("Create", notifications).
+		Return(nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.RefreshSession(
+		&domain.Session{
+			AccessTokenSigned: session.AccessTokenSigned,
+			RefreshTokenValue: append(session.RefreshTokenValue, 'a'),
+		},
+		userIP, userAgent)
 	assert.ErrorAs(t, err, &unauthorizedError)
 }
 
@@ -125,42 +692,606 @@ func TestRefreshSession_RefreshTokenExpired(t *testing.T) {
 		Return(&refreshToken, nil)
 
 	var unauthorizedError *domain.UnauthorizedError
-	_, err := service.RefreshSession(session, userIP)
+	_, err := service.RefreshSession(session, userIP, userAgent)
 	assert.ErrorAs(t, err, &unauthorizedError)
 }
 
+func TestRefreshSession_IssuedBeforeWatermark(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Now(), nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.RefreshSession(session, userIP, userAgent)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestValidateAccessToken_Success(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(accessTokenIssuedAt.Add(-time.Minute), nil)
+	revokedTokenRepository := service.revokedTokenRepository.(*mocks.RevokedTokenRepository)
+	revokedTokenRepository.
+		On("IsRevoked", accessTokenID, refreshTokenID).
+		Return(false, nil)
+
+	accessToken, err := service.ValidateAccessToken(accessTokenSigned)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, accessToken.UserID)
+	assert.Equal(t, refreshTokenID, accessToken.RefreshTokenID)
+}
+
+func TestValidateAccessToken_IssuedInWatermarkSecond(t *testing.T) {
+	issuedAt := time.Unix(time.Now().Unix(), int64(400*time.Millisecond))
+	for _, tc := range []struct {
+		notBefore time.Time
+		valid     bool
+	}{
+		// the token was issued before the watermark was raised
+		{notBefore: issuedAt.Add(time.Millisecond * 100), valid: false},
+		// the token was issued after the watermark was raised
+		{notBefore: issuedAt.Add(-time.Millisecond * 100), valid: true},
+	} {
+		service, _, _ := newServiceAndMocks(t)
+		newSession, err := service.newSession(&refreshToken, refreshTokenValue, userIP, issuedAt)
+		require.NoError(t, err)
+		tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+		tokenWatermarkRepository.
+			On("GetNotBefore", userID).
+			Return(tc.notBefore, nil)
+
+		if tc.valid {
+			revokedTokenRepository := service.revokedTokenRepository.(*mocks.RevokedTokenRepository)
+			revokedTokenRepository.
+				On("IsRevoked", mock.AnythingOfType("uuid.UUID"), sessionID).
+				Return(false, nil)
+		}
+		accessToken, err := service.ValidateAccessToken(newSession.AccessTokenSigned)
+		if tc.valid {
+			if assert.NoError(t, err) {
+				assert.True(t, issuedAt.Equal(accessToken.IssuedAt))
+			}
+		} else {
+			var unauthorizedError *domain.UnauthorizedError
+			assert.ErrorAs(t, err, &unauthorizedError)
+		}
+	}
+}
+
+func TestValidateAccessToken_IssuedBeforeWatermark(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Now(), nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.ValidateAccessToken(accessTokenSigned)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestValidateAccessToken_Revoked(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+	revokedTokenRepository := service.revokedTokenRepository.(*mocks.RevokedTokenRepository)
+	revokedTokenRepository.
+		On("IsRevoked", accessTokenID, refreshTokenID).
+		Return(true, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.ValidateAccessToken(accessTokenSigned)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRevokeSession_Success(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+
+	refreshTokenRepository.
+		On("GetBySessionID", refreshTokenID).
+		Return(nil, nil)
+	refreshTokenRepository.
+		On("RevokeSession", refreshTokenID, mock.MatchedBy(func(revokedUntil time.Time) bool {
+			return revokedUntil.Unix() == accessTokenExpTime.Unix()
+		})).
+		Return(false, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventSessionRevoked &&
+				event.RefreshTokenID == refreshTokenID
+		})).
+		Return(nil)
+
+	err := service.RevokeSession(accessTokenSigned, userIP)
+	assert.NoError(t, err)
+}
+
+func TestRevokeSession_AccessTokenIssuedBeforeRefresh(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.ProfileAccessTokenDurations = map[string]time.Duration{"cli": time.Hour * 8}
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	// the session was refreshed after the access token was issued
+	oldRefreshTokenID := uuid.New()
+	oldAccessTokenSigned := []byte(mustSignJWTString(jwt.NewWithClaims(accessTokenJWTSigningMethod,
+		jwt.MapClaims{
+			TokenIDJWTClaimName:        uuid.New().String(),
+			UserIDJWTClaimName:         userID.String(),
+			UserIPJWTClaimName:         userIP,
+			IssuedAtJWTClaimName:       accessTokenIssuedAt.Unix(),
+			ExpirationTimeJWTClaimName: accessTokenExpTime.Unix(),
+			RefreshTokenIDJWTClaimName: oldRefreshTokenID,
+			SessionIDJWTClaimName:      sessionID,
+		}), jwtPrivateKey))
+	currentToken := refreshToken
+	currentToken.LifetimeProfile = "cli"
+
+	refreshTokenRepository.
+		On("GetBySessionID", sessionID).
+		Return(&currentToken, nil)
+	refreshTokenRepository.
+		On("RevokeSession", sessionID, mock.MatchedBy(func(revokedUntil time.Time) bool {
+			// access tokens issued by later refreshes live longer
+			return revokedUntil.After(time.Now().Add(time.Hour*8 - time.Minute))
+		})).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventSessionRevoked
+		})).
+		Return(nil)
+
+	err := service.RevokeSession(oldAccessTokenSigned, userIP)
+	assert.NoError(t, err)
+}
+
+func TestRevokeAccessToken_Success(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	revokedTokenRepository := service.revokedTokenRepository.(*mocks.RevokedTokenRepository)
+
+	revokedTokenRepository.
+		On("Create", mock.MatchedBy(func(token *domain.RevokedToken) bool {
+			return token.Kind == domain.RevokedTokenKindAccessToken &&
+				token.ID == accessTokenID
+		})).
+		Return(nil)
+
+	err := service.RevokeAccessToken(accessTokenSigned)
+	assert.NoError(t, err)
+}
+
+func TestRefreshSession_IPNotAllowed(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP).
+		Return(&domain.IPNotAllowedError{IP: userIP, ClientID: clientID})
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventIPNotAllowed &&
+				event.RefreshTokenID == refreshToken.ID
+		})).
+		Return(nil)
+
+	var ipNotAllowedError *domain.IPNotAllowedError
+	_, err := service.RefreshSession(session, userIP, userAgent)
+	assert.ErrorAs(t, err, &ipNotAllowedError)
+}
+
 func TestRefreshSession_NewIP(t *testing.T) {
-	service, refreshTokenRepository, emailService := newServiceAndMocks(t)
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, userIP+"1").
+		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
 
 	refreshTokenRepository.
 		On("GetByID", refreshToken.ID).
 		Return(&refreshToken, nil)
 	refreshTokenRepository.
-		On("Create", mock.AnythingOfType("*domain.RefreshToken")).
-		Return(uuid.New(), nil)
+		On("Rotate", refreshToken.ID,
+			mock.MatchedBy(func(token *domain.RefreshToken) bool {
+				return token.ID != refreshToken.ID && token.SessionID == sessionID
+			}),
+			mock.MatchedBy(func(notifications []*domain.OutboxNotification) bool {
+				return len(notifications) == 1 && notifications[0].UserID == userID &&
+					notifications[0].Content == notificationContent
+			})).
+		Return(true, nil)
+	notifier := service.notifier.(*mocks.Notifier)
+	notifier.
+		On("NewNotifications", userID, domain.NotificationRefreshFromNewIP,
+			mock.AnythingOfType("domain.SessionDetails")).
+		Return([]*domain.OutboxNotification{domain.NewOutboxNotification(
+			userID, domain.NotificationChannelEmail, notificationContent)})
+
+	_, err := service.RefreshSession(session, userIP+"1", userAgent)
+	assert.NoError(t, err)
+	sessionDetails := notifier.Calls[0].Arguments.Get(2).(domain.SessionDetails)
+	assert.Equal(t, sessionID, sessionDetails.SessionID)
+	assert.Equal(t, userIP+"1", sessionDetails.IP)
+	assert.Equal(t, userAgent, sessionDetails.UserAgent)
+	link := sessionDetails.RevokeLink
+	if assert.NotNil(t, link) {
+		assert.Equal(t, userID, link.UserID)
+		assert.Equal(t, sessionID, link.SessionID)
+	}
+}
+
+func TestRefreshSession_SameIPInDifferentForm(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	ipAccessPolicy.
+		On("CheckIP", clientID, "::ffff:"+userIP).
+		Return(nil)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&refreshToken, nil)
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID, mock.AnythingOfType("*domain.RefreshToken"),
+			mock.MatchedBy(func(notifications []*domain.OutboxNotification) bool {
+				return len(notifications) == 0
+			})).
+		Return(true, nil)
+
+	_, err := service.RefreshSession(session, "::ffff:"+userIP, userAgent)
+	assert.NoError(t, err)
+}
+
+func TestListSessions_Success(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	refreshTokenRepository.
+		On("GetAllActiveByUserID", userID).
+		Return([]domain.RefreshToken{refreshToken}, nil)
+
+	sessions, err := service.ListSessions(userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.RefreshToken{refreshToken}, sessions)
+}
+
+func TestRevokeByLink_Session(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	service.cfg.ProfileAccessTokenDurations = map[string]time.Duration{"cli": time.Hour * 8}
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	usedRevokeLinkRepository := service.usedRevokeLinkRepository.(*mocks.UsedRevokeLinkRepository)
+	token := signRevokeLink(t, service, userID, sessionID, time.Now())
+	link, err := service.parseRevokeLink(token)
+	assert.NoError(t, err)
+
+	usedRevokeLinkRepository.
+		On("IsUsed", link.ID).
+		Return(false, nil)
+	// the session was refreshed after the link was sent
+	rotatedToken := refreshToken
+	rotatedToken.ID = uuid.New()
+	rotatedToken.LifetimeProfile = "cli"
+	refreshTokenRepository.
+		On("GetBySessionID", sessionID).
+		Return(&rotatedToken, nil)
+	refreshTokenRepository.
+		On("RevokeSession", sessionID, mock.MatchedBy(func(revokedUntil time.Time) bool {
+			return revokedUntil.After(time.Now().Add(time.Hour*8 - time.Minute))
+		})).
+		Return(true, nil)
+	usedRevokeLinkRepository.
+		On("Use", link.ID, link.ExpirationTime).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventSessionRevokedByLink &&
+				event.UserID == userID && event.SessionID == sessionID &&
+				event.IP == userIP
+		})).
+		Return(nil)
+
+	err = service.RevokeByLink(token, domain.RevokeLinkScopeSession, userIP)
+	assert.NoError(t, err)
+}
+
+func TestRevokeByLink_All(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	usedRevokeLinkRepository := service.usedRevokeLinkRepository.(*mocks.UsedRevokeLinkRepository)
+	token := signRevokeLink(t, service, userID, refreshTokenID, time.Now())
+
+	usedRevokeLinkRepository.
+		On("IsUsed", mock.AnythingOfType("uuid.UUID")).
+		Return(false, nil)
+	service.cfg.ProfileAccessTokenDurations = map[string]time.Duration{"cli": time.Hour * 8}
+	tokenWatermarkRepository.
+		On("RaiseNotBefore", userID,
+			mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			// access tokens issued before the watermark have expired by then
+			notBefore, revokedUntil := args.Get(1).(time.Time), args.Get(2).(time.Time)
+			assert.Equal(t, time.Hour*8, revokedUntil.Sub(notBefore))
+		}).
+		Return(nil)
+	usedRevokeLinkRepository.
+		On("Use", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("time.Time")).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventAllSessionsRevokedByLink &&
+				event.UserID == userID
+		})).
+		Return(nil)
+
+	err := service.RevokeByLink(token, domain.RevokeLinkScopeAll, userIP)
+	assert.NoError(t, err)
+}
+
+func TestRevokeByLink_Replayed(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	auditEventRepository := service.auditEventRepository.(*mocks.AuditEventRepository)
+	usedRevokeLinkRepository := service.usedRevokeLinkRepository.(*mocks.UsedRevokeLinkRepository)
+	token := signRevokeLink(t, service, userID, refreshTokenID, time.Now())
+
+	usedRevokeLinkRepository.
+		On("IsUsed", mock.AnythingOfType("uuid.UUID")).
+		Return(true, nil)
+	auditEventRepository.
+		On("Create", mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventRevokeLinkReplayed &&
+				event.UserID == userID && event.IP == userIP
+		})).
+		Return(nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := service.RevokeByLink(token, domain.RevokeLinkScopeAll, userIP)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRevokeByLink_Invalid(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	expiredToken := signRevokeLink(t, service,
+		userID, refreshTokenID, time.Now().Add(-revokeLinkDuration-time.Minute))
+	// access tokens are signed with another key
+	foreignToken := mustSignJWTString(jwt.NewWithClaims(revokeLinkJWTSigningMethod,
+		jwt.MapClaims{
+			TokenIDJWTClaimName:        uuid.New().String(),
+			UserIDJWTClaimName:         userID.String(),
+			SessionIDJWTClaimName:      refreshTokenID.String(),
+			ExpirationTimeJWTClaimName: time.Now().Add(time.Hour).Unix(),
+		}), jwtPrivateKey)
+
+	for _, token := range []string{expiredToken, foreignToken, string(accessTokenSigned)} {
+		var unauthorizedError *domain.UnauthorizedError
+		err := service.RevokeByLink(token, domain.RevokeLinkScopeAll, userIP)
+		assert.ErrorAs(t, err, &unauthorizedError)
+	}
+}
+
+func TestRefreshSession_ChallengeRequired(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	refreshChallengeRepository := service.refreshChallengeRepository.(*mocks.RefreshChallengeRepository)
+	challengeCodeSender := service.challengeCodeSender.(*mocks.ChallengeCodeSender)
+	strictRefreshToken := refreshToken
+	strictRefreshToken.ClientID = challengeClientID
+
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&strictRefreshToken, nil)
+	ipAccessPolicy.
+		On("CheckIP", challengeClientID, userIP+"1").
+		Return(nil)
+	refreshChallengeRepository.
+		On("GetOpen", refreshToken.ID, userIP+"1").
+		Return((*domain.RefreshChallenge)(nil), nil)
+	var challenge *domain.RefreshChallenge
+	refreshChallengeRepository.
+		On("Create", mock.MatchedBy(func(c *domain.RefreshChallenge) bool {
+			challenge = c
+			return c.UserID == userID && c.RefreshTokenID == refreshToken.ID &&
+				c.IP == userIP+"1" && c.Attempts == 0
+		})).
+		Return(nil)
+	challengeCodeSender.
+		On("Render", userID, domain.NotificationRefreshChallenge,
+			mock.AnythingOfType("domain.SessionDetails")).
+		Return(notificationContent, nil)
+	challengeCodeSender.
+		On("Send", userID, notificationContent).
+		Return(nil)
+
+	var challengeRequiredError *domain.ChallengeRequiredError
+	_, err := service.RefreshSession(session, userIP+"1", userAgent)
+
+	require.ErrorAs(t, err, &challengeRequiredError)
+	assert.Equal(t, challenge.ID, challengeRequiredError.ChallengeID)
+	code := challengeCodeSender.Calls[0].Arguments.Get(2).(domain.SessionDetails).ChallengeCode
+	assert.Regexp(t, `^\d{6}$`, code)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(challenge.CodeHash, []byte(code)))
+}
+
+func TestRefreshSession_ChallengeReusesOpenChallenge(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	refreshChallengeRepository := service.refreshChallengeRepository.(*mocks.RefreshChallengeRepository)
+	strictRefreshToken := refreshToken
+	strictRefreshToken.ClientID = challengeClientID
+	openChallenge := newRefreshChallenge("123456", userIP+"1")
+
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
 	refreshTokenRepository.
-		On("DeleteByID", refreshToken.ID).
+		On("GetByID", refreshToken.ID).
+		Return(&strictRefreshToken, nil)
+	ipAccessPolicy.
+		On("CheckIP", challengeClientID, userIP+"1").
 		Return(nil)
-	emailService.
-		On("SendSupportEmailToUser", userID, mock.Anything).
+	refreshChallengeRepository.
+		On("GetOpen", refreshToken.ID, userIP+"1").
+		Return(openChallenge, nil)
+
+	// no new challenge is created and no code is sent
+	var challengeRequiredError *domain.ChallengeRequiredError
+	_, err := service.RefreshSession(session, userIP+"1", userAgent)
+
+	require.ErrorAs(t, err, &challengeRequiredError)
+	assert.Equal(t, openChallenge.ID, challengeRequiredError.ChallengeID)
+}
+
+func TestCompleteRefreshChallenge_Success(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	ipAccessPolicy := service.ipAccessPolicy.(*mocks.IPAccessPolicy)
+	tokenWatermarkRepository := service.tokenWatermarkRepository.(*mocks.TokenWatermarkRepository)
+	refreshChallengeRepository := service.refreshChallengeRepository.(*mocks.RefreshChallengeRepository)
+	notifier := service.notifier.(*mocks.Notifier)
+	strictRefreshToken := refreshToken
+	strictRefreshToken.ClientID = challengeClientID
+	challenge := newRefreshChallenge("123456", userIP+"1")
+
+	refreshChallengeRepository.
+		On("GetByID", challenge.ID).
+		Return(challenge, nil)
+	refreshChallengeRepository.
+		On("IncrementAttempts", challenge.ID).
+		Return(1, nil)
+	refreshChallengeRepository.
+		On("Delete", challenge.ID).
+		Return(true, nil)
+	tokenWatermarkRepository.
+		On("GetNotBefore", userID).
+		Return(time.Time{}, nil)
+	refreshTokenRepository.
+		On("GetByID", refreshToken.ID).
+		Return(&strictRefreshToken, nil)
+	ipAccessPolicy.
+		On("CheckIP", challengeClientID, userIP+"1").
 		Return(nil)
+	notifier.
+		On("NewNotifications", userID, domain.NotificationRefreshFromNewIP,
+			mock.AnythingOfType("domain.SessionDetails")).
+		Return([]*domain.OutboxNotification(nil))
+	refreshTokenRepository.
+		On("Rotate", refreshToken.ID, mock.AnythingOfType("*domain.RefreshToken"),
+			[]*domain.OutboxNotification(nil)).
+		Return(true, nil)
 
-	_, err := service.RefreshSession(session, userIP+"1")
+	_, err := service.CompleteRefreshChallenge(
+		session, challenge.ID, "123456", userIP+"1", userAgent)
 	assert.NoError(t, err)
 }
 
-func newServiceAndMocks(t *testing.T) (*AuthService, *mocks.RefreshTokenRepository, *mocks.EmailService) {
+func TestCompleteRefreshChallenge_WrongCode(t *testing.T) {
+	service, refreshTokenRepository, _ := newServiceAndMocks(t)
+	refreshChallengeRepository := service.refreshChallengeRepository.(*mocks.RefreshChallengeRepository)
+	challenge := newRefreshChallenge("123456", userIP+"1")
+
+	refreshChallengeRepository.
+		On("GetByID", challenge.ID).
+		Return(challenge, nil)
+	refreshChallengeRepository.
+		On("IncrementAttempts", challenge.ID).
+		Return(maxChallengeAttempts, nil)
+	// the last attempt deletes the challenge
+	refreshChallengeRepository.
+		On("Delete", challenge.ID).
+		Return(true, nil)
+	// and counts as a failed refresh attempt
+	refreshTokenRepository.
+		On("IncrementFailedAttempts", refreshTokenID).
+		Return(1, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.CompleteRefreshChallenge(
+		session, challenge.ID, "654321", userIP+"1", userAgent)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestCompleteRefreshChallenge_OtherIP(t *testing.T) {
+	service, _, _ := newServiceAndMocks(t)
+	refreshChallengeRepository := service.refreshChallengeRepository.(*mocks.RefreshChallengeRepository)
+	challenge := newRefreshChallenge("123456", userIP+"1")
+
+	refreshChallengeRepository.
+		On("GetByID", challenge.ID).
+		Return(challenge, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.CompleteRefreshChallenge(
+		session, challenge.ID, "123456", userIP+"2", userAgent)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func newRefreshChallenge(code, ip string) *domain.RefreshChallenge {
+	return &domain.RefreshChallenge{
+		ID:             uuid.New(),
+		UserID:         userID,
+		RefreshTokenID: refreshToken.ID,
+		IP:             ip,
+		CodeHash:       MustGenerateBcryptHashFromPassword([]byte(code), bcrypt.MinCost),
+		CreationTime:   time.Now(),
+		ExpirationTime: time.Now().Add(time.Minute),
+	}
+}
+
+func newServiceAndMocks(t *testing.T) (*AuthService, *mocks.RefreshTokenRepository, *mocks.NotificationOutboxRepository) {
 	refreshTokenRepository := mocks.NewRefreshTokenRepository(t)
-	emailService := mocks.NewEmailService(t)
+	auditEventRepository := mocks.NewAuditEventRepository(t)
+	tokenWatermarkRepository := mocks.NewTokenWatermarkRepository(t)
+	revokedTokenRepository := mocks.NewRevokedTokenRepository(t)
+	notificationOutboxRepository := mocks.NewNotificationOutboxRepository(t)
+	notifier := mocks.NewNotifier(t)
+	usedRevokeLinkRepository := mocks.NewUsedRevokeLinkRepository(t)
+	refreshChallengeRepository := mocks.NewRefreshChallengeRepository(t)
+	challengeCodeSender := mocks.NewChallengeCodeSender(t)
+	pendingStepUpRepository := mocks.NewPendingStepUpRepository(t)
+	ipAccessPolicy := mocks.NewIPAccessPolicy(t)
 	service := NewAuthService(
+		authConfig,
 		refreshTokenRepository,
-		emailService,
-		jwtPrivateKey,
-		accessTokenDuration,
-		refreshTokenDuration,
+		auditEventRepository,
+		tokenWatermarkRepository,
+		revokedTokenRepository,
+		notificationOutboxRepository,
+		notifier,
+		usedRevokeLinkRepository,
+		refreshChallengeRepository,
+		challengeCodeSender,
+		pendingStepUpRepository,
+		ipAccessPolicy,
 	)
 
-	return service, refreshTokenRepository, emailService
+	return service, refreshTokenRepository, notificationOutboxRepository
 }
 
 func MustGenerateBcryptHashFromPassword(password []byte, cost int) []byte {
@@ -171,6 +1302,16 @@ func MustGenerateBcryptHashFromPassword(password []byte, cost int) []byte {
 	return hash
 }
 
+func signRevokeLink(
+	t *testing.T, service *AuthService, userID, sessionID uuid.UUID, now time.Time,
+) string {
+
+	signer := NewRevokeLinkSigner(service.cfg.RevokeLinkSecret)
+	token, err := signer.SignRevokeLink(service.newRevokeLink(userID, sessionID, now))
+	require.NoError(t, err)
+	return token
+}
+
 func mustSignJWTString(jwt *jwt.Token, privateKey []byte) string {
 	signedJWTStr, err := jwt.SignedString(privateKey)
 	if err != nil {
@@ -0,0 +1,72 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	"time"
+)
+
+// lifetimeProfile selects the lifetime profile of a new session of the
+// client. It returns *domain.ValidationError if the requested profile is not
+// configured.
+func (s *AuthService) lifetimeProfile(
+	clientID string, options domain.SessionOptions,
+) (string, error) {
+
+	switch {
+	case options.LifetimeProfile != "":
+		if !s.lifetimeProfileExists(options.LifetimeProfile) {
+			return "", &domain.ValidationError{
+				Message: "lifetime profile " + options.LifetimeProfile + " is not configured"}
+		}
+		return options.LifetimeProfile, nil
+	case options.RememberMe && s.cfg.RememberMeLifetimeProfile != "":
+		return s.cfg.RememberMeLifetimeProfile, nil
+	default:
+		return s.cfg.ClientLifetimeProfiles[clientID], nil
+	}
+}
+
+func (s *AuthService) lifetimeProfileExists(profile string) bool {
+	_, accessTokenDurationSet := s.cfg.ProfileAccessTokenDurations[profile]
+	_, refreshTokenDurationSet := s.cfg.ProfileRefreshTokenDurations[profile]
+
+	return accessTokenDurationSet || refreshTokenDurationSet
+}
+
+// tokenDurations returns durations of tokens of the profile capped by the
+// maximums. Durations the profile doesn't set, including the ones of
+// profiles removed from the config after sessions were created with them,
+// are the global ones.
+func (s *AuthService) tokenDurations(
+	profile string,
+) (accessTokenDuration, refreshTokenDuration time.Duration) {
+
+	accessTokenDuration = s.cfg.AccessTokenDuration
+	if duration, ok := s.cfg.ProfileAccessTokenDurations[profile]; ok {
+		accessTokenDuration = duration
+	}
+	refreshTokenDuration = s.cfg.RefreshTokenDuration
+	if duration, ok := s.cfg.ProfileRefreshTokenDurations[profile]; ok {
+		refreshTokenDuration = duration
+	}
+	if s.cfg.MaxAccessTokenDuration > 0 {
+		accessTokenDuration = min(accessTokenDuration, s.cfg.MaxAccessTokenDuration)
+	}
+	if s.cfg.MaxRefreshTokenDuration > 0 {
+		refreshTokenDuration = min(refreshTokenDuration, s.cfg.MaxRefreshTokenDuration)
+	}
+
+	return accessTokenDuration, refreshTokenDuration
+}
+
+// maxAccessTokenDuration returns the longest access token duration of all
+// lifetime profiles.
+func (s *AuthService) maxAccessTokenDuration() time.Duration {
+	maxDuration, _ := s.tokenDurations("")
+	for profile := range s.cfg.ProfileAccessTokenDurations {
+		accessTokenDuration, _ := s.tokenDurations(profile)
+		maxDuration = max(maxDuration, accessTokenDuration)
+	}
+
+	return maxDuration
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	time "time"
+
 	domain "auth/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
@@ -52,18 +54,27 @@ func (_m *RefreshTokenRepository) DeleteAllExpired() error {
 	return r0
 }
 
-// DeleteByID provides a mock function with given fields: id
-func (_m *RefreshTokenRepository) DeleteByID(id uuid.UUID) error {
-	ret := _m.Called(id)
+// GetAllActiveByUserID provides a mock function with given fields: userID
+func (_m *RefreshTokenRepository) GetAllActiveByUserID(userID uuid.UUID) ([]domain.RefreshToken, error) {
+	ret := _m.Called(userID)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
-		r0 = rf(id)
+	var r0 []domain.RefreshToken
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []domain.RefreshToken); ok {
+		r0 = rf(userID)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RefreshToken)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetByID provides a mock function with given fields: id
@@ -89,6 +100,113 @@ func (_m *RefreshTokenRepository) GetByID(id uuid.UUID) (*domain.RefreshToken, e
 	return r0, r1
 }
 
+// GetBySessionID provides a mock function with given fields: sessionID
+func (_m *RefreshTokenRepository) GetBySessionID(sessionID uuid.UUID) (*domain.RefreshToken, error) {
+	ret := _m.Called(sessionID)
+
+	var r0 *domain.RefreshToken
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *domain.RefreshToken); ok {
+		r0 = rf(sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RefreshToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementFailedAttempts provides a mock function with given fields: id
+func (_m *RefreshTokenRepository) IncrementFailedAttempts(id uuid.UUID) (int, error) {
+	ret := _m.Called(id)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(uuid.UUID) int); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: sessionID, revokedUntil
+func (_m *RefreshTokenRepository) RevokeSession(sessionID uuid.UUID, revokedUntil time.Time) (bool, error) {
+	ret := _m.Called(sessionID, revokedUntil)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(uuid.UUID, time.Time) bool); ok {
+		r0 = rf(sessionID, revokedUntil)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID, time.Time) error); ok {
+		r1 = rf(sessionID, revokedUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rotate provides a mock function with given fields: id, newToken, notifications
+func (_m *RefreshTokenRepository) Rotate(id uuid.UUID, newToken *domain.RefreshToken, notifications []*domain.OutboxNotification) (bool, error) {
+	ret := _m.Called(id, newToken, notifications)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(uuid.UUID, *domain.RefreshToken, []*domain.OutboxNotification) bool); ok {
+		r0 = rf(id, newToken, notifications)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID, *domain.RefreshToken, []*domain.OutboxNotification) error); ok {
+		r1 = rf(id, newToken, notifications)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetAuthContext provides a mock function with given fields: id, acr, amr, authTime
+func (_m *RefreshTokenRepository) SetAuthContext(id uuid.UUID, acr string, amr []string, authTime time.Time) (bool, error) {
+	ret := _m.Called(id, acr, amr, authTime)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string, []string, time.Time) bool); ok {
+		r0 = rf(id, acr, amr, authTime)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string, []string, time.Time) error); ok {
+		r1 = rf(id, acr, amr, authTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewRefreshTokenRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,160 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	iputils "auth/internal/utils/ip-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const challengeCodeDigits = 6
+
+// CompleteRefreshChallenge completes the refresh which returned
+// *domain.ChallengeRequiredError once the code sent to the user is given.
+// The challenge is bound to the session and the IP address and is deleted
+// after the attempts limit, which counts as a failed refresh attempt of the
+// refresh token, so new challenges can't be used to continue guessing.
+func (s *AuthService) CompleteRefreshChallenge(
+	session *domain.Session, challengeID uuid.UUID, code string,
+	requestIP, userAgent string,
+) (*domain.Session, error) {
+
+	accessToken, err := s.parseAccessToken(session.AccessTokenSigned)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.refreshChallengeRepository.GetByID(challengeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get refresh challenge")
+	}
+	if challenge == nil ||
+		challenge.RefreshTokenID != accessToken.RefreshTokenID ||
+		iputils.Normalize(challenge.IP) != iputils.Normalize(requestIP) {
+		return nil, &domain.UnauthorizedError{Message: "challenge not found"}
+	}
+	if time.Now().After(challenge.ExpirationTime) {
+		return nil, &domain.UnauthorizedError{Message: "challenge is expired"}
+	}
+
+	// attempts are counted before the check,
+	// so concurrent attempts can't exceed the limit
+	attempts, err := s.refreshChallengeRepository.IncrementAttempts(challenge.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "increment challenge attempts")
+	}
+	if attempts > s.cfg.MaxChallengeAttempts {
+		s.deleteRefreshChallenge(challenge.ID)
+		return nil, &domain.UnauthorizedError{Message: "challenge attempts limit is reached"}
+	}
+	if bcrypt.CompareHashAndPassword(challenge.CodeHash, []byte(code)) != nil {
+		if attempts == s.cfg.MaxChallengeAttempts {
+			s.deleteRefreshChallenge(challenge.ID)
+			err = s.registerFailedRefreshAttempt(accessToken, requestIP, userAgent)
+			if err != nil {
+				return nil, errors.Wrap(err, "register failed refresh attempt")
+			}
+		}
+		return nil, &domain.UnauthorizedError{Message: "code is invalid"}
+	}
+
+	deleted, err := s.refreshChallengeRepository.Delete(challenge.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "delete refresh challenge")
+	}
+	if !deleted {
+		// completed by a concurrent request
+		return nil, &domain.UnauthorizedError{Message: "challenge not found"}
+	}
+
+	return s.refreshSession(session, requestIP, userAgent, true)
+}
+
+// newRefreshChallenge stores a challenge for the refresh, emails its code to
+// the user and returns *domain.ChallengeRequiredError. The open challenge of
+// the refresh token from the IP address is returned instead if there is one,
+// so repeated refreshes don't reset the attempts limit.
+func (s *AuthService) newRefreshChallenge(
+	userID uuid.UUID, refreshToken *domain.RefreshToken, requestIP, userAgent string,
+) error {
+
+	openChallenge, err := s.refreshChallengeRepository.GetOpen(refreshToken.ID, requestIP)
+	if err != nil {
+		return errors.Wrap(err, "get open refresh challenge")
+	}
+	if openChallenge != nil {
+		return &domain.ChallengeRequiredError{ChallengeID: openChallenge.ID}
+	}
+
+	code, err := generateChallengeCode()
+	if err != nil {
+		return errors.Wrap(err, "generate challenge code")
+	}
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Wrap(err, "hash challenge code")
+	}
+
+	now := time.Now()
+	challenge := &domain.RefreshChallenge{
+		ID:             uuid.New(),
+		UserID:         userID,
+		RefreshTokenID: refreshToken.ID,
+		IP:             requestIP,
+		CodeHash:       codeHash,
+		CreationTime:   now,
+		ExpirationTime: now.Add(s.cfg.ChallengeCodeDuration),
+	}
+	err = s.refreshChallengeRepository.Create(challenge)
+	if err != nil {
+		return errors.Wrap(err, "save refresh challenge")
+	}
+
+	// the code is sent right away rather than through the outbox,
+	// since it expires in minutes
+	content, err := s.challengeCodeSender.Render(
+		userID, domain.NotificationRefreshChallenge,
+		domain.SessionDetails{
+			SessionID:     refreshToken.SessionID,
+			Time:          now,
+			IP:            requestIP,
+			UserAgent:     userAgent,
+			ChallengeCode: code,
+		})
+	if err != nil {
+		return errors.Wrap(err, "render challenge code")
+	}
+	err = s.challengeCodeSender.Send(userID, content)
+	if err != nil {
+		return errors.Wrap(err, "send challenge code")
+	}
+
+	return &domain.ChallengeRequiredError{ChallengeID: challenge.ID}
+}
+
+func (s *AuthService) deleteRefreshChallenge(id uuid.UUID) {
+	_, err := s.refreshChallengeRepository.Delete(id)
+	if err != nil {
+		slogutils.Error("delete refresh challenge", err, "challengeID", id)
+	}
+}
+
+func generateChallengeCode() (string, error) {
+	max := big.NewInt(1)
+	for range challengeCodeDigits {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", challengeCodeDigits, n), nil
+}
@@ -0,0 +1,118 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// RevokeSession ends the session the access token was issued in and
+// denylists every access token of the session.
+func (s *AuthService) RevokeSession(accessTokenSigned []byte, requestIP string) error {
+	accessToken, err := s.parseAccessToken(accessTokenSigned)
+	if err != nil {
+		return err
+	}
+
+	err = s.revokeSession(accessToken)
+	if err != nil {
+		return err
+	}
+
+	err = s.auditEventRepository.Create(&domain.AuditEvent{
+		Type:           domain.AuditEventSessionRevoked,
+		UserID:         accessToken.UserID,
+		RefreshTokenID: accessToken.RefreshTokenID,
+		SessionID:      accessToken.SessionID,
+		IP:             requestIP,
+		Time:           time.Now(),
+	})
+	if err != nil {
+		slogutils.Error("create audit event(session revoked) error", err)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken denylists a single access token by its ID. The session
+// the token belongs to stays active.
+func (s *AuthService) RevokeAccessToken(accessTokenSigned []byte) error {
+	accessToken, err := s.parseAccessToken(accessTokenSigned)
+	if err != nil {
+		return err
+	}
+	if accessToken.ID == uuid.Nil {
+		return &domain.UnauthorizedError{Message: "access token has no ID"}
+	}
+
+	err = s.revokedTokenRepository.Create(&domain.RevokedToken{
+		Kind:           domain.RevokedTokenKindAccessToken,
+		ID:             accessToken.ID,
+		RevocationTime: time.Now(),
+		ExpirationTime: accessToken.ExpTime,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create revoked token")
+	}
+
+	return nil
+}
+
+// GetRevocationsSnapshot returns all not yet expired revocations and the
+// feed cursor to continue from.
+func (s *AuthService) GetRevocationsSnapshot() ([]domain.RevokedToken, int64, error) {
+	// cursor is taken first, so revocations made in between
+	// are returned again by the feed rather than skipped
+	cursor, err := s.revokedTokenRepository.GetLastSeq()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "get last revocation seq")
+	}
+	revokedTokens, err := s.revokedTokenRepository.GetAllActive()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "get active revoked tokens")
+	}
+
+	return revokedTokens, cursor, nil
+}
+
+// GetRevocationsAfter returns up to limit revocations made after the cursor.
+// Revocations which have expired since the cursor was taken may be missing.
+func (s *AuthService) GetRevocationsAfter(
+	cursor int64, limit int,
+) ([]domain.RevokedToken, error) {
+
+	revokedTokens, err := s.revokedTokenRepository.GetAfter(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "get revoked tokens")
+	}
+
+	return revokedTokens, nil
+}
+
+// revokeSession revokes the session by its ID, since access tokens issued
+// before the last refresh of the session carry older refresh token IDs.
+func (s *AuthService) revokeSession(accessToken *domain.AccessToken) error {
+	refreshToken, err := s.refreshTokenRepository.GetBySessionID(accessToken.SessionID)
+	if err != nil {
+		return errors.Wrap(err, "get session refresh token")
+	}
+	// access tokens of the session expire not later than this one or one
+	// access token duration of the session profile from now
+	revokedUntil := accessToken.ExpTime
+	if refreshToken != nil {
+		accessTokenDuration, _ := s.tokenDurations(refreshToken.LifetimeProfile)
+		if lastExpTime := time.Now().Add(accessTokenDuration); lastExpTime.After(revokedUntil) {
+			revokedUntil = lastExpTime
+		}
+	}
+
+	_, err = s.refreshTokenRepository.RevokeSession(accessToken.SessionID, revokedUntil)
+	if err != nil {
+		return errors.Wrap(err, "revoke session")
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	jwtutils "auth/internal/utils/jwt-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var revokeLinkJWTSigningMethod = jwt.SigningMethodHS256
+
+// RevokeLinkSigner signs "This wasn't me" links. Notifications are stored
+// with the link claims only and signed when they are sent, so anyone who can
+// read stored notifications can't end the user's sessions.
+type RevokeLinkSigner struct {
+	revokeLinkSecret []byte
+}
+
+func NewRevokeLinkSigner(revokeLinkSecret string) *RevokeLinkSigner {
+	return &RevokeLinkSigner{
+		revokeLinkSecret: []byte(revokeLinkSecret),
+	}
+}
+
+// SignRevokeLink returns the token of the link.
+func (s *RevokeLinkSigner) SignRevokeLink(link *domain.RevokeLink) (string, error) {
+	token, err := jwt.NewWithClaims(revokeLinkJWTSigningMethod,
+		jwt.MapClaims{
+			TokenIDJWTClaimName:        link.ID.String(),
+			UserIDJWTClaimName:         link.UserID.String(),
+			SessionIDJWTClaimName:      link.SessionID.String(),
+			ExpirationTimeJWTClaimName: link.ExpirationTime.Unix(),
+		}).SignedString(s.revokeLinkSecret)
+	if err != nil {
+		return "", errors.Wrap(err, "sign revoke link")
+	}
+
+	return token, nil
+}
+
+// CheckRevokeLink returns the link if it is valid and not used yet, so the
+// confirmation page can be shown. It doesn't use the link, since mail
+// scanners open links from emails.
+func (s *AuthService) CheckRevokeLink(token string) (*domain.RevokeLink, error) {
+	link, err := s.parseRevokeLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.usedRevokeLinkRepository.IsUsed(link.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "check revoke link use")
+	}
+	if used {
+		return nil, &domain.UnauthorizedError{Message: "revoke link is already used"}
+	}
+
+	return link, nil
+}
+
+// RevokeByLink revokes the session of the "This wasn't me" link or all
+// sessions of the user and uses the link up.
+func (s *AuthService) RevokeByLink(
+	token string, scope domain.RevokeLinkScope, requestIP string,
+) error {
+
+	link, err := s.parseRevokeLink(token)
+	if err != nil {
+		return err
+	}
+	used, err := s.usedRevokeLinkRepository.IsUsed(link.ID)
+	if err != nil {
+		return errors.Wrap(err, "check revoke link use")
+	}
+	if used {
+		err = s.auditEventRepository.Create(&domain.AuditEvent{
+			Type:      domain.AuditEventRevokeLinkReplayed,
+			UserID:    link.UserID,
+			SessionID: link.SessionID,
+			IP:        requestIP,
+			Time:      time.Now(),
+		})
+		if err != nil {
+			slogutils.Error("create audit event(revoke link replayed) error", err)
+		}
+		return &domain.UnauthorizedError{Message: "revoke link is already used"}
+	}
+
+	now := time.Now()
+	var auditEventType domain.AuditEventType
+	switch scope {
+	case domain.RevokeLinkScopeSession:
+		auditEventType = domain.AuditEventSessionRevokedByLink
+		refreshToken, err := s.refreshTokenRepository.GetBySessionID(link.SessionID)
+		if err != nil {
+			return errors.Wrap(err, "get session refresh token")
+		}
+		if refreshToken == nil {
+			// the session has already ended
+			break
+		}
+		// access tokens issued with the refresh token expire not later
+		// than one access token duration of the session profile from now
+		accessTokenDuration, _ := s.tokenDurations(refreshToken.LifetimeProfile)
+		_, err = s.refreshTokenRepository.
+			RevokeSession(link.SessionID, now.Add(accessTokenDuration))
+		if err != nil {
+			return errors.Wrap(err, "revoke session")
+		}
+	case domain.RevokeLinkScopeAll:
+		err = s.RaiseUserTokensNotBefore(link.UserID, now)
+		if err != nil {
+			return err
+		}
+		auditEventType = domain.AuditEventAllSessionsRevokedByLink
+	default:
+		return errors.Errorf("unknown revoke link scope %q", scope)
+	}
+
+	// the link is used up after the revocation, so it can be opened again
+	// if the revocation fails. Concurrent uses both revoke, which is
+	// harmless since revocations are idempotent.
+	_, err = s.usedRevokeLinkRepository.Use(link.ID, link.ExpirationTime)
+	if err != nil {
+		slogutils.Error("use revoke link", err, "linkID", link.ID)
+	}
+
+	err = s.auditEventRepository.Create(&domain.AuditEvent{
+		Type:      auditEventType,
+		UserID:    link.UserID,
+		SessionID: link.SessionID,
+		IP:        requestIP,
+		Time:      now,
+	})
+	if err != nil {
+		slogutils.Error("create audit event(revoked by link) error", err)
+	}
+
+	return nil
+}
+
+// newRevokeLink creates a "This wasn't me" link for the session. It returns
+// nil if the links are disabled. The link is signed by RevokeLinkSigner when
+// the notification is sent.
+func (s *AuthService) newRevokeLink(
+	userID, sessionID uuid.UUID, now time.Time,
+) *domain.RevokeLink {
+
+	if len(s.revokeLinkSecret) == 0 {
+		return nil
+	}
+
+	return &domain.RevokeLink{
+		ID:             uuid.New(),
+		UserID:         userID,
+		SessionID:      sessionID,
+		ExpirationTime: now.Add(s.cfg.RevokeLinkDuration),
+	}
+}
+
+func (s *AuthService) parseRevokeLink(token string) (*domain.RevokeLink, error) {
+	if len(s.revokeLinkSecret) == 0 {
+		return nil, &domain.UnauthorizedError{Message: "revoke links are disabled"}
+	}
+
+	linkJWT, err := jwtutils.ParseAndValidateJWTToken(
+		[]byte(token), s.revokeLinkSecret, revokeLinkJWTSigningMethod.Name)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{
+			Message: fmt.Sprintf("parse revoke link: %s", err)}
+	}
+	claims, ok := linkJWT.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, &domain.UnauthorizedError{Message: "parse revoke link: claim missing"}
+	}
+
+	var link domain.RevokeLink
+	for claimName, id := range map[string]*uuid.UUID{
+		TokenIDJWTClaimName:   &link.ID,
+		UserIDJWTClaimName:    &link.UserID,
+		SessionIDJWTClaimName: &link.SessionID,
+	} {
+		value, err := jwtutils.GetStringJWTClaim(claims, claimName)
+		if err == nil {
+			*id, err = uuid.Parse(value)
+		}
+		if err != nil {
+			return nil, &domain.UnauthorizedError{
+				Message: fmt.Sprintf("parse revoke link %s claim: %s", claimName, err)}
+		}
+	}
+	link.ExpirationTime, err = jwtutils.GetTimeJWTClaim(claims, ExpirationTimeJWTClaimName)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{
+			Message: fmt.Sprintf("parse revoke link %s claim: %s",
+				ExpirationTimeJWTClaimName, err)}
+	}
+
+	return &link, nil
+}
@@ -0,0 +1,125 @@
+package authservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// sessionsToEvict applies the session limit policy if the user already has
+// the maximum number of sessions. It returns *domain.SessionLimitReachedError
+// if new sessions are rejected, otherwise it returns the sessions the policy
+// evicts. They are revoked by evictSessions once the new session is created,
+// so a failed login doesn't end other sessions. Concurrent logins may exceed
+// the cap until the next login of the user.
+func (s *AuthService) sessionsToEvict(
+	userID uuid.UUID, clientID, requestIP string, now time.Time,
+) ([]domain.RefreshToken, error) {
+
+	if s.cfg.MaxSessionsPerUser <= 0 {
+		return nil, nil
+	}
+	sessions, err := s.refreshTokenRepository.GetAllActiveByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user refresh tokens")
+	}
+	excess := len(sessions) - s.cfg.MaxSessionsPerUser + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+
+	switch s.cfg.SessionLimitPolicy {
+	case config.SessionLimitReject:
+		err = s.auditEventRepository.Create(&domain.AuditEvent{
+			Type:     domain.AuditEventSessionLimitReached,
+			UserID:   userID,
+			ClientID: clientID,
+			IP:       requestIP,
+			Time:     now,
+		})
+		if err != nil {
+			slogutils.Error("create audit event(session limit reached) error", err)
+		}
+		return nil, &domain.SessionLimitReachedError{MaxSessions: s.cfg.MaxSessionsPerUser}
+	case config.SessionLimitEvictOldest:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].SessionStartTime.Before(sessions[j].SessionStartTime)
+		})
+	case config.SessionLimitEvictLeastRecentlyRefreshed:
+		// a refresh token is created by the last refresh of the session
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].CreationTime.Before(sessions[j].CreationTime)
+		})
+	default:
+		return nil, errors.Errorf("unknown session limit policy %q", s.cfg.SessionLimitPolicy)
+	}
+
+	return sessions[:excess], nil
+}
+
+// evictSessions revokes the sessions evicted by the new session and returns
+// the revoked ones. The sessions which failed to be revoked are left until
+// the next login of the user.
+func (s *AuthService) evictSessions(
+	userID uuid.UUID, sessions []domain.RefreshToken, requestIP string, now time.Time,
+) []domain.RefreshToken {
+
+	var evicted []domain.RefreshToken
+	for _, session := range sessions {
+		// the last access token of the session was issued before now
+		accessTokenDuration, _ := s.tokenDurations(session.LifetimeProfile)
+		_, err := s.refreshTokenRepository.
+			RevokeSession(session.SessionID, now.Add(accessTokenDuration))
+		if err != nil {
+			slogutils.Error("revoke evicted session error", err)
+			continue
+		}
+		evicted = append(evicted, session)
+		err = s.auditEventRepository.Create(&domain.AuditEvent{
+			Type:           domain.AuditEventSessionEvicted,
+			UserID:         userID,
+			RefreshTokenID: session.ID,
+			SessionID:      session.SessionID,
+			ClientID:       session.ClientID,
+			IP:             requestIP,
+			Time:           now,
+		})
+		if err != nil {
+			slogutils.Error("create audit event(session evicted) error", err)
+		}
+	}
+
+	return evicted
+}
+
+// notifySessionsEvicted notifies the user about sessions evicted by the new
+// session. The "This wasn't me" link of the notifications ends the new
+// session.
+func (s *AuthService) notifySessionsEvicted(
+	userID uuid.UUID, evicted []domain.RefreshToken, newSessionID uuid.UUID,
+	requestIP string, now time.Time,
+) {
+
+	var notifications []*domain.OutboxNotification
+	for _, session := range evicted {
+		notifications = append(notifications, s.notifier.NewNotifications(
+			userID, domain.NotificationSessionEvicted,
+			domain.SessionDetails{
+				SessionID:  session.SessionID,
+				Time:       now,
+				IP:         requestIP,
+				RevokeLink: s.newRevokeLink(userID, newSessionID, now),
+			})...)
+	}
+	if len(notifications) > 0 {
+		err := s.notificationOutboxRepository.Create(notifications)
+		if err != nil {
+			slogutils.Error("enqueue notifications(session evicted) error", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	"time"
+)
+
+// sessionLimits returns the maximum age and the idle timeout of sessions of
+// the client, 0 means no limit.
+func (s *AuthService) sessionLimits(clientID string) (maxAge, idleTimeout time.Duration) {
+	maxAge = s.cfg.MaxSessionAge
+	if clientMaxAge, ok := s.cfg.ClientMaxSessionAges[clientID]; ok {
+		maxAge = clientMaxAge
+	}
+	idleTimeout = s.cfg.SessionIdleTimeout
+	if clientIdleTimeout, ok := s.cfg.ClientSessionIdleTimeouts[clientID]; ok {
+		idleTimeout = clientIdleTimeout
+	}
+
+	return maxAge, idleTimeout
+}
+
+// refreshTokenExpirationTime is the earliest of the refresh token duration
+// of the session lifetime profile, the idle timeout and the end of the
+// session.
+func (s *AuthService) refreshTokenExpirationTime(
+	refreshToken *domain.RefreshToken, now time.Time,
+) time.Time {
+
+	_, refreshTokenDuration := s.tokenDurations(refreshToken.LifetimeProfile)
+	expirationTime := now.Add(refreshTokenDuration)
+	maxAge, idleTimeout := s.sessionLimits(refreshToken.ClientID)
+	if idleTimeout > 0 && now.Add(idleTimeout).Before(expirationTime) {
+		expirationTime = now.Add(idleTimeout)
+	}
+	sessionEndTime := refreshToken.SessionStartTime.Add(maxAge)
+	if maxAge > 0 && sessionEndTime.Before(expirationTime) {
+		expirationTime = sessionEndTime
+	}
+
+	return expirationTime
+}
+
+// checkSessionLimits returns *domain.UnauthorizedError if the session of the
+// refresh token is too old or was idle for too long. The expiration time of
+// the token already enforces the limits, they are checked again since they
+// could be lowered after the token was issued.
+func (s *AuthService) checkSessionLimits(
+	refreshToken *domain.RefreshToken, now time.Time,
+) error {
+
+	maxAge, idleTimeout := s.sessionLimits(refreshToken.ClientID)
+	if maxAge > 0 && now.After(refreshToken.SessionStartTime.Add(maxAge)) {
+		return &domain.UnauthorizedError{Message: "session reached its maximum age"}
+	}
+	// a refresh token is created by the last refresh of the session
+	if idleTimeout > 0 && now.After(refreshToken.CreationTime.Add(idleTimeout)) {
+		return &domain.UnauthorizedError{Message: "session was idle for too long"}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ListSessions returns active sessions of the user. A session is
+// represented by its current refresh token.
+func (s *AuthService) ListSessions(userID uuid.UUID) ([]domain.RefreshToken, error) {
+	refreshTokens, err := s.refreshTokenRepository.GetAllActiveByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user refresh tokens")
+	}
+
+	return refreshTokens, nil
+}
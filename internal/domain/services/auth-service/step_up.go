@@ -0,0 +1,78 @@
+package authservice
+
+import (
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// StepUpSession raises the session to domain.ACRStepUp after the user
+// passed a second factor, which also renews the authentication time. Access
+// tokens issued by the next refresh of the session carry the new "acr",
+// "amr" and "auth_time" claims.
+func (s *AuthService) StepUpSession(
+	userID, refreshTokenID uuid.UUID, amr []string,
+) error {
+
+	now := time.Now()
+	found, err := s.refreshTokenRepository.
+		SetAuthContext(refreshTokenID, domain.ACRStepUp, amr, now)
+	if err != nil {
+		return errors.Wrap(err, "set refresh token auth context")
+	}
+	if !found {
+		return &domain.UnauthorizedError{Message: "refresh token not found"}
+	}
+
+	err = s.auditEventRepository.Create(&domain.AuditEvent{
+		Type:           domain.AuditEventSessionSteppedUp,
+		UserID:         userID,
+		RefreshTokenID: refreshTokenID,
+		Time:           now,
+	})
+	if err != nil {
+		slogutils.Error("create audit event(session stepped up) error", err)
+	}
+
+	return nil
+}
+
+// StepUpUser returns a one-time ticket which makes the session created for
+// the user with it before expirationTime stepped up, for second factors
+// verified before CreateSession. Binding the step-up to the ticket keeps
+// other logins of the user from taking it.
+func (s *AuthService) StepUpUser(
+	userID uuid.UUID, amr []string, expirationTime time.Time,
+) (uuid.UUID, error) {
+
+	stepUp := &domain.PendingStepUp{
+		Ticket:         uuid.New(),
+		UserID:         userID,
+		AMR:            amr,
+		ExpirationTime: expirationTime,
+	}
+	err := s.pendingStepUpRepository.Save(stepUp)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "save pending step-up")
+	}
+
+	return stepUp.Ticket, nil
+}
+
+// mergeAMR returns methods of both lists without duplicates in the order
+// they are first listed.
+func mergeAMR(amr, other []string) []string {
+	merged := make([]string, 0, len(amr)+len(other))
+	seen := make(map[string]bool)
+	for _, method := range append(append([]string{}, amr...), other...) {
+		if !seen[method] {
+			seen[method] = true
+			merged = append(merged, method)
+		}
+	}
+
+	return merged
+}
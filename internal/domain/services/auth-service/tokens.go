@@ -18,7 +18,13 @@ const (
 	UserIDJWTClaimName         = "sub"
 	UserIPJWTClaimName         = "sub_ip"
 	RefreshTokenIDJWTClaimName = "refresh_token_id"
+	SessionIDJWTClaimName      = "sid"
 	ExpirationTimeJWTClaimName = "exp"
+	IssuedAtJWTClaimName       = "iat"
+	TokenIDJWTClaimName        = "jti"
+	AuthTimeJWTClaimName       = "auth_time"
+	ACRJWTClaimName            = "acr"
+	AMRJWTClaimName            = "amr"
 )
 
 func parseAccessTokenFromJWT(token *jwt.Token) (*domain.AccessToken, error) {
@@ -44,10 +50,65 @@ func parseAccessTokenFromJWT(token *jwt.Token) (*domain.AccessToken, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", RefreshTokenIDJWTClaimName))
 		}
+		// tokens issued before the claim was introduced don't have it, the
+		// ID of their session is the ID of the refresh token it had then
+		accessToken.SessionID = accessToken.RefreshTokenID
+		if _, ok := claimsMap[SessionIDJWTClaimName]; ok {
+			sessionID, err := jwtutils.GetStringJWTClaim(claimsMap, SessionIDJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", SessionIDJWTClaimName))
+			}
+			accessToken.SessionID, err = uuid.Parse(sessionID)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", SessionIDJWTClaimName))
+			}
+		}
 		accessToken.ExpTime, err = jwtutils.GetTimeJWTClaim(claimsMap, ExpirationTimeJWTClaimName)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", ExpirationTimeJWTClaimName))
 		}
+		// tokens issued before the claims were introduced don't have "jti",
+		// they can be revoked only together with their refresh token
+		if _, ok := claimsMap[TokenIDJWTClaimName]; ok {
+			tokenID, err := jwtutils.GetStringJWTClaim(claimsMap, TokenIDJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", TokenIDJWTClaimName))
+			}
+			accessToken.ID, err = uuid.Parse(tokenID)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", TokenIDJWTClaimName))
+			}
+		}
+		// tokens issued before the claim was introduced don't have it,
+		// they are treated as issued at the beginning of time
+		if _, ok := claimsMap[IssuedAtJWTClaimName]; ok {
+			accessToken.IssuedAt, err = jwtutils.GetTimeJWTClaim(claimsMap, IssuedAtJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", IssuedAtJWTClaimName))
+			}
+		}
+		// tokens of sessions created before the claim was introduced don't
+		// have it, their authentication time is unknown
+		if _, ok := claimsMap[AuthTimeJWTClaimName]; ok {
+			accessToken.AuthTime, err = jwtutils.GetTimeJWTClaim(claimsMap, AuthTimeJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", AuthTimeJWTClaimName))
+			}
+		}
+		// "acr" is set only on stepped up sessions, "amr" if the methods
+		// are known
+		if _, ok := claimsMap[ACRJWTClaimName]; ok {
+			accessToken.ACR, err = jwtutils.GetStringJWTClaim(claimsMap, ACRJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", ACRJWTClaimName))
+			}
+		}
+		if _, ok := claimsMap[AMRJWTClaimName]; ok {
+			accessToken.AMR, err = jwtutils.GetStringsJWTClaim(claimsMap, AMRJWTClaimName)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("parse %s claim", AMRJWTClaimName))
+			}
+		}
 
 		return &accessToken, nil
 	}
@@ -37,27 +37,28 @@ func NewEmailService(
 }
 
 func (s *emailService) SendSupportEmailToUser(
-	userID uuid.UUID, emailContent domain.EmailContent,
+	userID uuid.UUID, emailContent domain.NotificationContent,
 ) error {
 	to, err := s.UserEmailRepository.GetUserEmail(userID)
 	if err != nil {
 		return errors.Wrap(err, "get user email")
 	}
 
-	return s.SendEmails(
-		s.Emails.SupportEmail, []string{to},
-		emailContent.Subject, emailContent.ContentType,
-		emailContent.Body)
+	return s.SendEmails(s.Emails.SupportEmail, []string{to}, emailContent)
 }
 
+// SendEmails sends the content as multipart/alternative if it has an HTML
+// body and as plain text otherwise.
 func (s *emailService) SendEmails(
-	from string, to []string,
-	subject, contentType, body string,
+	from string, to []string, emailContent domain.NotificationContent,
 ) error {
 	message := gomail.NewMessage()
 	message.SetHeader("From", from)
 	message.SetHeader("To", to...)
-	message.SetHeader("Subject", subject)
-	message.SetBody(contentType, body)
+	message.SetHeader("Subject", emailContent.Subject)
+	message.SetBody("text/plain", emailContent.TextBody)
+	if emailContent.HTMLBody != "" {
+		message.AddAlternative("text/html", emailContent.HTMLBody)
+	}
 	return s.SMTPDialer.DialAndSend(message)
 }
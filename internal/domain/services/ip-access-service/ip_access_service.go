@@ -0,0 +1,155 @@
+package ipaccessservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	iputils "auth/internal/utils/ip-utils"
+	slogutils "auth/internal/utils/slog-utils"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IPAccessService decides whether sessions can be created or refreshed from an
+// IP address. Global rules come from config and from the database, per-client
+// rules come from the database. Database rules are reloaded periodically and
+// on demand.
+type IPAccessService struct {
+	configRules            []domain.IPAccessRule
+	ipAccessRuleRepository IPAccessRuleRepository
+
+	mu     sync.RWMutex
+	global ipAccessList
+	client map[string]ipAccessList
+}
+
+//go:generate mockery --name IPAccessRuleRepository --filename ip_access_rule_repository.go
+type IPAccessRuleRepository interface {
+	GetAll() ([]domain.IPAccessRule, error)
+}
+
+type ipAccessList struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+func NewIPAccessService(
+	cfg config.IPAccessConfig,
+	ipAccessRuleRepository IPAccessRuleRepository,
+) (*IPAccessService, error) {
+
+	configRules, err := newConfigRules(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse config rules")
+	}
+	s := &IPAccessService{
+		configRules:            configRules,
+		ipAccessRuleRepository: ipAccessRuleRepository,
+	}
+	if err := s.Reload(); err != nil {
+		return nil, errors.Wrap(err, "load rules")
+	}
+
+	if cfg.ReloadPeriod <= 0 {
+		return s, nil
+	}
+	go func() {
+		timer := time.NewTicker(cfg.ReloadPeriod)
+		for {
+			<-timer.C
+			if err := s.Reload(); err != nil {
+				slogutils.Error("reload ip access rules", err)
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Reload replaces database rules with their current version.
+func (s *IPAccessService) Reload() error {
+	dbRules, err := s.ipAccessRuleRepository.GetAll()
+	if err != nil {
+		return errors.Wrap(err, "get rules")
+	}
+
+	global := ipAccessList{}
+	client := make(map[string]ipAccessList)
+	for _, rule := range append(dbRules, s.configRules...) {
+		list := global
+		if rule.ClientID != "" {
+			list = client[rule.ClientID]
+		}
+		switch rule.Action {
+		case domain.IPAccessRuleActionAllow:
+			list.allow = append(list.allow, rule.Prefix)
+		case domain.IPAccessRuleActionDeny:
+			list.deny = append(list.deny, rule.Prefix)
+		}
+		if rule.ClientID != "" {
+			client[rule.ClientID] = list
+		} else {
+			global = list
+		}
+	}
+
+	s.mu.Lock()
+	s.global, s.client = global, client
+	s.mu.Unlock()
+
+	return nil
+}
+
+// CheckIP returns *domain.IPNotAllowedError if the client can't be used from
+// the IP address. Denylists take precedence over allowlists, non-empty
+// allowlist denies every address it doesn't contain.
+func (s *IPAccessService) CheckIP(clientID, ip string) error {
+	addr, err := iputils.ParseAddr(ip)
+	if err != nil {
+		return &domain.IPNotAllowedError{IP: ip, ClientID: clientID}
+	}
+
+	s.mu.RLock()
+	lists := []ipAccessList{s.global, s.client[clientID]}
+	s.mu.RUnlock()
+
+	for _, list := range lists {
+		if !list.allows(addr) {
+			return &domain.IPNotAllowedError{IP: ip, ClientID: clientID}
+		}
+	}
+
+	return nil
+}
+
+func (l ipAccessList) allows(addr netip.Addr) bool {
+	if iputils.ContainsAddr(l.deny, addr) {
+		return false
+	}
+	return len(l.allow) == 0 || iputils.ContainsAddr(l.allow, addr)
+}
+
+func newConfigRules(cfg config.IPAccessConfig) ([]domain.IPAccessRule, error) {
+	allowlist, err := iputils.ParsePrefixes(cfg.GlobalAllowlist)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse global allowlist")
+	}
+	denylist, err := iputils.ParsePrefixes(cfg.GlobalDenylist)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse global denylist")
+	}
+
+	var rules []domain.IPAccessRule
+	for _, prefix := range allowlist {
+		rules = append(rules, domain.IPAccessRule{
+			Prefix: prefix, Action: domain.IPAccessRuleActionAllow})
+	}
+	for _, prefix := range denylist {
+		rules = append(rules, domain.IPAccessRule{
+			Prefix: prefix, Action: domain.IPAccessRuleActionDeny})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,90 @@
+package ipaccessservice
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/ip-access-service/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ipAccessConfig = config.IPAccessConfig{
+		GlobalDenylist: []string{"203.0.113.0/24"},
+		ReloadPeriod:   time.Hour,
+	}
+
+	dbRules = []domain.IPAccessRule{
+		{
+			ClientID: "internal",
+			Prefix:   netip.MustParsePrefix("10.0.0.0/8"),
+			Action:   domain.IPAccessRuleActionAllow,
+		},
+		{
+			ClientID: "internal",
+			Prefix:   netip.MustParsePrefix("10.6.6.0/24"),
+			Action:   domain.IPAccessRuleActionDeny,
+		},
+	}
+)
+
+func TestCheckIP_GlobalDenylist(t *testing.T) {
+	service := newService(t, dbRules)
+
+	var ipNotAllowedError *domain.IPNotAllowedError
+	assert.ErrorAs(t, service.CheckIP("", "203.0.113.5"), &ipNotAllowedError)
+	assert.ErrorAs(t, service.CheckIP("web", "203.0.113.5"), &ipNotAllowedError)
+	assert.NoError(t, service.CheckIP("web", "198.51.100.1"))
+}
+
+func TestCheckIP_ClientLists(t *testing.T) {
+	service := newService(t, dbRules)
+
+	var ipNotAllowedError *domain.IPNotAllowedError
+	assert.NoError(t, service.CheckIP("internal", "10.1.2.3"))
+	assert.NoError(t, service.CheckIP("internal", "::ffff:10.1.2.3"))
+	assert.ErrorAs(t, service.CheckIP("internal", "10.6.6.6"), &ipNotAllowedError)
+	assert.ErrorAs(t, service.CheckIP("internal", "198.51.100.1"), &ipNotAllowedError)
+	assert.ErrorAs(t, service.CheckIP("internal", "not-an-ip"), &ipNotAllowedError)
+}
+
+func TestReload(t *testing.T) {
+	service := newService(t, nil)
+	assert.NoError(t, service.CheckIP("internal", "198.51.100.1"))
+
+	repository := service.ipAccessRuleRepository.(*mocks.IPAccessRuleRepository)
+	repository.ExpectedCalls = nil
+	repository.On("GetAll").Return(dbRules, nil)
+	assert.NoError(t, service.Reload())
+
+	var ipNotAllowedError *domain.IPNotAllowedError
+	assert.ErrorAs(t, service.CheckIP("internal", "198.51.100.1"), &ipNotAllowedError)
+}
+
+func TestNewIPAccessService_PeriodicReloadDisabled(t *testing.T) {
+	repository := mocks.NewIPAccessRuleRepository(t)
+	repository.On("GetAll").Return(dbRules, nil).Once()
+	cfg := ipAccessConfig
+	cfg.ReloadPeriod = 0
+
+	service, err := NewIPAccessService(cfg, repository)
+
+	assert.NoError(t, err)
+	assert.NoError(t, service.CheckIP("internal", "10.1.2.3"))
+}
+
+func newService(t *testing.T, rules []domain.IPAccessRule) *IPAccessService {
+	repository := mocks.NewIPAccessRuleRepository(t)
+	repository.On("GetAll").Return(rules, nil)
+
+	service, err := NewIPAccessService(ipAccessConfig, repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service
+}
@@ -0,0 +1,229 @@
+package magiclinkservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	tokenSize                = 32
+	nonceSize                = 32
+	deleteExpiredLinksPeriod = time.Minute * 5
+)
+
+// MagicLinkService logs users in without passwords with single-use links
+// sent to their email. A link is bound to the browser which requested it by
+// a nonce, so a forwarded or intercepted link is useless on its own.
+type MagicLinkService struct {
+	cfg                 config.MagicLinkConfig
+	magicLinkRepository MagicLinkRepository
+	userRepository      UserRepository
+	magicLinkSender     MagicLinkSender
+	authService         AuthService
+
+	// sending tracks links being sent in the background
+	sending sync.WaitGroup
+}
+
+//go:generate mockery --name MagicLinkRepository --filename magic_link_repository.go
+type MagicLinkRepository interface {
+	Create(link *domain.MagicLink) error
+	// Take deletes and returns the link, nil if it is not found, so a link
+	// can be exchanged only once.
+	Take(tokenHash []byte) (*domain.MagicLink, error)
+	CountActiveByUserID(userID uuid.UUID) (int, error)
+	DeleteAllExpired() error
+}
+
+//go:generate mockery --name UserRepository --filename user_repository.go
+type UserRepository interface {
+	// GetUserIDByEmail returns uuid.Nil if there is no user with the email.
+	GetUserIDByEmail(email string) (uuid.UUID, error)
+}
+
+// MagicLinkSender renders and sends login links to the email of the user.
+//
+//go:generate mockery --name MagicLinkSender --filename magic_link_sender.go
+type MagicLinkSender interface {
+	Render(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) (domain.NotificationContent, error)
+	Send(userID uuid.UUID, content domain.NotificationContent) error
+}
+
+//go:generate mockery --name AuthService --filename auth_service.go
+type AuthService interface {
+	CreateSession(
+		userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
+	) (*domain.Session, error)
+}
+
+func NewMagicLinkService(
+	cfg config.MagicLinkConfig,
+	magicLinkRepository MagicLinkRepository,
+	userRepository UserRepository,
+	magicLinkSender MagicLinkSender,
+	authService AuthService,
+) *MagicLinkService {
+
+	go func() {
+		timer := time.NewTicker(deleteExpiredLinksPeriod)
+		for {
+			<-timer.C
+			err := magicLinkRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired magic links", err)
+			}
+		}
+	}()
+
+	return &MagicLinkService{
+		cfg:                 cfg,
+		magicLinkRepository: magicLinkRepository,
+		userRepository:      userRepository,
+		magicLinkSender:     magicLinkSender,
+		authService:         authService,
+	}
+}
+
+// RequestLink emails a login link to the user with the email and returns the
+// nonce the requesting browser must present to exchange the link. A nonce is
+// returned even if no link is sent and the link is sent in the background,
+// so neither responses nor their timing reveal which emails are registered.
+func (s *MagicLinkService) RequestLink(
+	email, clientID, requestIP, userAgent string,
+) (string, error) {
+
+	nonce, err := randomString(nonceSize)
+	if err != nil {
+		return "", errors.Wrap(err, "generate nonce")
+	}
+
+	userID, err := s.userRepository.GetUserIDByEmail(email)
+	if err != nil {
+		return "", errors.Wrap(err, "get user id by email")
+	}
+	if userID == uuid.Nil {
+		return nonce, nil
+	}
+
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		err := s.sendLink(userID, clientID, nonce, requestIP, userAgent)
+		if err != nil {
+			slogutils.Error("send magic link", err, "userID", userID)
+		}
+	}()
+
+	return nonce, nil
+}
+
+// sendLink creates a link bound to the nonce and emails it to the user
+// unless the user has the maximum number of active links.
+func (s *MagicLinkService) sendLink(
+	userID uuid.UUID, clientID, nonce, requestIP, userAgent string,
+) error {
+
+	activeLinks, err := s.magicLinkRepository.CountActiveByUserID(userID)
+	if err != nil {
+		return errors.Wrap(err, "count active magic links")
+	}
+	if activeLinks >= s.cfg.MaxActiveLinks {
+		slog.Warn("too many active magic links", "userID", userID)
+		return nil
+	}
+
+	token, err := randomString(tokenSize)
+	if err != nil {
+		return errors.Wrap(err, "generate token")
+	}
+	now := time.Now()
+	err = s.magicLinkRepository.Create(&domain.MagicLink{
+		TokenHash:      hash(token),
+		UserID:         userID,
+		ClientID:       clientID,
+		NonceHash:      hash(nonce),
+		ExpirationTime: now.Add(s.cfg.Duration),
+	})
+	if err != nil {
+		return errors.Wrap(err, "create magic link")
+	}
+
+	// the link is sent right away rather than through the outbox, since it
+	// expires in minutes
+	content, err := s.magicLinkSender.Render(
+		userID, domain.NotificationMagicLink,
+		domain.SessionDetails{
+			Time:           now,
+			IP:             requestIP,
+			UserAgent:      userAgent,
+			MagicLinkToken: token,
+		})
+	if err != nil {
+		return errors.Wrap(err, "render magic link")
+	}
+	err = s.magicLinkSender.Send(userID, content)
+	if err != nil {
+		return errors.Wrap(err, "send magic link")
+	}
+
+	return nil
+}
+
+// ExchangeLink creates a session of the user the link was sent to for the
+// client the link was requested by. The link is used up even if the nonce
+// doesn't match, since it could be intercepted. Errors of invalid links are
+// *domain.UnauthorizedError.
+func (s *MagicLinkService) ExchangeLink(
+	token, nonce, requestIP string,
+) (*domain.Session, error) {
+
+	link, err := s.magicLinkRepository.Take(hash(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "take magic link")
+	}
+	if link == nil || time.Now().After(link.ExpirationTime) {
+		return nil, &domain.UnauthorizedError{Message: "link not found"}
+	}
+	if subtle.ConstantTimeCompare(hash(nonce), link.NonceHash) != 1 {
+		slog.Warn("magic link opened in another browser",
+			"userID", link.UserID, "ip", requestIP)
+		return nil, &domain.UnauthorizedError{
+			Message: "link was requested from another browser"}
+	}
+
+	session, err := s.authService.CreateSession(
+		link.UserID, link.ClientID, requestIP,
+		domain.SessionOptions{AMR: []string{domain.AMREmail}})
+	if err != nil {
+		return nil, errors.Wrap(err, "create session")
+	}
+
+	return session, nil
+}
+
+func randomString(size int) (string, error) {
+	value := make([]byte, size)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+func hash(value string) []byte {
+	sum := sha256.Sum256([]byte(value))
+	return sum[:]
+}
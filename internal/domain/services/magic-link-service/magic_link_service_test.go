@@ -0,0 +1,178 @@
+package magiclinkservice
+
+import (
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/magic-link-service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	magicLinkConfig = config.MagicLinkConfig{
+		Enabled:        true,
+		Duration:       time.Minute * 15,
+		MaxActiveLinks: 3,
+	}
+
+	userID    = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
+	userEmail = "user@example.com"
+	clientID  = "marketing"
+	userIP    = "127.0.0.1"
+	userAgent = "Mozilla/5.0"
+)
+
+func TestRequestAndExchangeLink(t *testing.T) {
+	service := newService(t)
+	token, link, nonce := requestLink(t, service)
+
+	assert.Equal(t, userID, link.UserID)
+	assert.Equal(t, clientID, link.ClientID)
+	assert.Equal(t, hash(token), link.TokenHash)
+	assert.Equal(t, hash(nonce), link.NonceHash)
+	assert.NotEqual(t, token, nonce)
+
+	service.magicLinkRepository.(*mocks.MagicLinkRepository).
+		On("Take", hash(token)).
+		Return(link, nil)
+	expectedSession := &domain.Session{AccessTokenSigned: []byte("access-token")}
+	service.authService.(*mocks.AuthService).
+		On("CreateSession", userID, clientID, userIP,
+			domain.SessionOptions{AMR: []string{domain.AMREmail}}).
+		Return(expectedSession, nil)
+
+	session, err := service.ExchangeLink(token, nonce, userIP)
+
+	require.NoError(t, err)
+	assert.Equal(t, expectedSession, session)
+}
+
+func TestExchangeLink_AnotherBrowser(t *testing.T) {
+	service := newService(t)
+	token, link, _ := requestLink(t, service)
+	service.magicLinkRepository.(*mocks.MagicLinkRepository).
+		On("Take", hash(token)).
+		Return(link, nil)
+
+	for _, nonce := range []string{"", "another-nonce"} {
+		_, err := service.ExchangeLink(token, nonce, userIP)
+
+		var unauthorizedError *domain.UnauthorizedError
+		assert.ErrorAs(t, err, &unauthorizedError)
+	}
+}
+
+func TestExchangeLink_Expired(t *testing.T) {
+	service := newService(t)
+	token, link, nonce := requestLink(t, service)
+	link.ExpirationTime = time.Now().Add(-time.Second)
+	service.magicLinkRepository.(*mocks.MagicLinkRepository).
+		On("Take", hash(token)).
+		Return(link, nil)
+
+	_, err := service.ExchangeLink(token, nonce, userIP)
+
+	var unauthorizedError *domain.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestExchangeLink_Used(t *testing.T) {
+	service := newService(t)
+	service.magicLinkRepository.(*mocks.MagicLinkRepository).
+		On("Take", hash("token")).
+		Return(nil, nil)
+
+	_, err := service.ExchangeLink("token", "nonce", userIP)
+
+	var unauthorizedError *domain.UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestRequestLink_UnknownEmail(t *testing.T) {
+	service := newService(t)
+	service.userRepository.(*mocks.UserRepository).
+		On("GetUserIDByEmail", "unknown@example.com").
+		Return(uuid.Nil, nil)
+
+	nonce, err := service.RequestLink("unknown@example.com", clientID, userIP, userAgent)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, nonce)
+}
+
+func TestRequestLink_TooManyActiveLinks(t *testing.T) {
+	service := newService(t)
+	service.userRepository.(*mocks.UserRepository).
+		On("GetUserIDByEmail", userEmail).
+		Return(userID, nil)
+	service.magicLinkRepository.(*mocks.MagicLinkRepository).
+		On("CountActiveByUserID", userID).
+		Return(magicLinkConfig.MaxActiveLinks, nil)
+
+	nonce, err := service.RequestLink(userEmail, clientID, userIP, userAgent)
+	service.sending.Wait()
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, nonce)
+}
+
+// requestLink requests a link and returns the emailed token, the stored link
+// and the nonce.
+func requestLink(
+	t *testing.T, service *MagicLinkService,
+) (string, *domain.MagicLink, string) {
+
+	service.userRepository.(*mocks.UserRepository).
+		On("GetUserIDByEmail", userEmail).
+		Return(userID, nil)
+	magicLinkRepository := service.magicLinkRepository.(*mocks.MagicLinkRepository)
+	magicLinkRepository.
+		On("CountActiveByUserID", userID).
+		Return(0, nil)
+	var link *domain.MagicLink
+	magicLinkRepository.
+		On("Create", mock.AnythingOfType("*domain.MagicLink")).
+		Run(func(args mock.Arguments) {
+			link = args.Get(0).(*domain.MagicLink)
+		}).
+		Return(nil)
+	var token string
+	content := domain.NotificationContent{Subject: "Your sign-in link"}
+	magicLinkSender := service.magicLinkSender.(*mocks.MagicLinkSender)
+	magicLinkSender.
+		On("Render", userID, domain.NotificationMagicLink,
+			mock.MatchedBy(func(session domain.SessionDetails) bool {
+				return session.IP == userIP && session.UserAgent == userAgent
+			})).
+		Run(func(args mock.Arguments) {
+			token = args.Get(2).(domain.SessionDetails).MagicLinkToken
+		}).
+		Return(content, nil)
+	magicLinkSender.
+		On("Send", userID, content).
+		Return(nil)
+
+	nonce, err := service.RequestLink(userEmail, clientID, userIP, userAgent)
+	require.NoError(t, err)
+	service.sending.Wait()
+	require.NotNil(t, link)
+	require.NotEmpty(t, token)
+
+	return token, link, nonce
+}
+
+func newService(t *testing.T) *MagicLinkService {
+	return NewMagicLinkService(
+		magicLinkConfig,
+		mocks.NewMagicLinkRepository(t),
+		mocks.NewUserRepository(t),
+		mocks.NewMagicLinkSender(t),
+		mocks.NewAuthService(t),
+	)
+}
@@ -0,0 +1,312 @@
+package mfaservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	"crypto/cipher"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// MFAService owns the second factors of users: TOTP authenticator apps and
+// one-time recovery codes. A verified second factor steps up the session, so
+// the next access token of the session carries the "acr" and "amr" claims.
+type MFAService struct {
+	cfg                      config.MFAConfig
+	secretCipher             cipher.AEAD
+	totpCredentialRepository TOTPCredentialRepository
+	recoveryCodeRepository   RecoveryCodeRepository
+	userRepository           UserRepository
+	stepUpper                StepUpper
+}
+
+//go:generate mockery --name TOTPCredentialRepository --filename totp_credential_repository.go
+type TOTPCredentialRepository interface {
+	// Save replaces the not confirmed credential of the user.
+	Save(credential *domain.TOTPCredential) error
+	// GetByUserID returns nil if the user has no credential.
+	GetByUserID(userID uuid.UUID) (*domain.TOTPCredential, error)
+	Confirm(userID uuid.UUID, step int64) error
+	// UseStep records the accepted time step and resets failed attempts. It
+	// returns false if the same or a later step is already used.
+	UseStep(userID uuid.UUID, step int64) (bool, error)
+	IncrementFailedAttempts(userID uuid.UUID) (failedAttempts int, err error)
+	// Lock locks verification until lockedUntil and resets failed attempts.
+	Lock(userID uuid.UUID, lockedUntil time.Time) error
+}
+
+//go:generate mockery --name RecoveryCodeRepository --filename recovery_code_repository.go
+type RecoveryCodeRepository interface {
+	// Replace replaces all recovery codes of the user.
+	Replace(userID uuid.UUID, codeHashes [][]byte) error
+	// Use deletes the code. It returns false if the user has no such code.
+	Use(userID uuid.UUID, codeHash []byte) (bool, error)
+}
+
+//go:generate mockery --name UserRepository --filename user_repository.go
+type UserRepository interface {
+	GetUserEmail(userID uuid.UUID) (string, error)
+}
+
+// StepUpper steps up sessions. It is the auth service.
+//
+//go:generate mockery --name StepUpper --filename step_upper.go
+type StepUpper interface {
+	StepUpSession(userID, refreshTokenID uuid.UUID, amr []string) error
+	StepUpUser(userID uuid.UUID, amr []string, expirationTime time.Time) (uuid.UUID, error)
+}
+
+func NewMFAService(
+	cfg config.MFAConfig,
+	totpCredentialRepository TOTPCredentialRepository,
+	recoveryCodeRepository RecoveryCodeRepository,
+	userRepository UserRepository,
+	stepUpper StepUpper,
+) (*MFAService, error) {
+
+	if cfg.TOTPSecretKey == "" {
+		return nil, errors.New("totp secret key is empty")
+	}
+	secretCipher, err := newSecretCipher(cfg.TOTPSecretKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "create totp secret cipher")
+	}
+
+	return &MFAService{
+		cfg:                      cfg,
+		secretCipher:             secretCipher,
+		totpCredentialRepository: totpCredentialRepository,
+		recoveryCodeRepository:   recoveryCodeRepository,
+		userRepository:           userRepository,
+		stepUpper:                stepUpper,
+	}, nil
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. The secret is not
+// used for step-up until ConfirmTOTP. Enrolling again replaces a not
+// confirmed secret and returns *domain.ConflictError for a confirmed one.
+func (s *MFAService) EnrollTOTP(userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	credential, err := s.totpCredentialRepository.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get totp credential")
+	}
+	if credential != nil && credential.Confirmed {
+		return nil, &domain.ConflictError{Message: "totp is already enrolled"}
+	}
+
+	email, err := s.userRepository.GetUserEmail(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user email")
+	}
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "generate totp secret")
+	}
+	secretEncrypted, err := encryptSecret(s.secretCipher, secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypt totp secret")
+	}
+	err = s.totpCredentialRepository.Save(&domain.TOTPCredential{
+		UserID:          userID,
+		SecretEncrypted: secretEncrypted,
+		CreationTime:    time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "save totp credential")
+	}
+
+	return &domain.TOTPEnrollment{
+		Secret:     totpSecretEncoding.EncodeToString(secret),
+		OTPAuthURI: otpAuthURI(s.cfg.Issuer, email, secret),
+	}, nil
+}
+
+// ConfirmTOTP confirms the enrollment with a code of the authenticator app
+// and returns new recovery codes. The codes are shown to the user once.
+func (s *MFAService) ConfirmTOTP(userID uuid.UUID, code string) ([]string, error) {
+	credential, err := s.totpCredentialRepository.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get totp credential")
+	}
+	if credential == nil {
+		return nil, &domain.NotFoundError{Message: "totp is not enrolled"}
+	}
+	if credential.Confirmed {
+		return nil, &domain.ConflictError{Message: "totp is already confirmed"}
+	}
+
+	step, err := s.verifyTOTPCode(credential, code)
+	if err != nil {
+		return nil, err
+	}
+	err = s.totpCredentialRepository.Confirm(userID, step)
+	if err != nil {
+		return nil, errors.Wrap(err, "confirm totp credential")
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+// StepUpSession verifies the second factor and steps up the session of the
+// access token. The next refresh of the session returns an access token
+// with the "acr" and "amr" claims.
+func (s *MFAService) StepUpSession(
+	accessToken *domain.AccessToken, factor domain.SecondFactor,
+) error {
+
+	amr, err := s.verifySecondFactor(accessToken.UserID, factor)
+	if err != nil {
+		return err
+	}
+
+	err = s.stepUpper.StepUpSession(accessToken.UserID, accessToken.RefreshTokenID, amr)
+	if err != nil {
+		return errors.Wrap(err, "step up session")
+	}
+
+	return nil
+}
+
+// StepUpUser verifies the second factor for the backend which creates
+// sessions. It returns the one-time ticket that steps up the session created
+// for the user with it in PendingStepUpDuration.
+func (s *MFAService) StepUpUser(
+	userID uuid.UUID, factor domain.SecondFactor,
+) (uuid.UUID, error) {
+
+	amr, err := s.verifySecondFactor(userID, factor)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	ticket, err := s.stepUpper.StepUpUser(
+		userID, amr, time.Now().Add(s.cfg.PendingStepUpDuration))
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "step up user")
+	}
+
+	return ticket, nil
+}
+
+// RegenerateRecoveryCodes replaces recovery codes of the user once a TOTP
+// code is given.
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, totpCode string) ([]string, error) {
+	_, err := s.verifySecondFactor(userID, domain.SecondFactor{TOTPCode: totpCode})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(userID)
+}
+
+// verifySecondFactor checks the TOTP or recovery code against the confirmed
+// TOTP credential of the user and returns the authentication methods for
+// the "amr" claim. Failed attempts of both kinds of codes count towards the
+// lockout.
+func (s *MFAService) verifySecondFactor(
+	userID uuid.UUID, factor domain.SecondFactor,
+) ([]string, error) {
+
+	credential, err := s.totpCredentialRepository.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get totp credential")
+	}
+	if credential == nil || !credential.Confirmed {
+		return nil, &domain.NotFoundError{Message: "totp is not enrolled"}
+	}
+
+	switch {
+	case factor.TOTPCode != "":
+		step, err := s.verifyTOTPCode(credential, factor.TOTPCode)
+		if err != nil {
+			return nil, err
+		}
+		used, err := s.totpCredentialRepository.UseStep(userID, step)
+		if err != nil {
+			return nil, errors.Wrap(err, "use totp step")
+		}
+		if !used {
+			// accepted by a concurrent request
+			return nil, &domain.UnauthorizedError{Message: "code is already used"}
+		}
+		return []string{domain.AMROTP, domain.AMRMFA}, nil
+	case factor.RecoveryCode != "":
+		if err := checkNotLocked(credential); err != nil {
+			return nil, err
+		}
+		used, err := s.recoveryCodeRepository.Use(userID, hashRecoveryCode(factor.RecoveryCode))
+		if err != nil {
+			return nil, errors.Wrap(err, "use recovery code")
+		}
+		if !used {
+			return nil, s.registerFailedAttempt(userID, "recovery code is invalid")
+		}
+		return []string{domain.AMRRecoveryCode, domain.AMRMFA}, nil
+	default:
+		return nil, &domain.UnauthorizedError{Message: "second factor is required"}
+	}
+}
+
+// verifyTOTPCode returns the time step of the valid code.
+func (s *MFAService) verifyTOTPCode(
+	credential *domain.TOTPCredential, code string,
+) (int64, error) {
+
+	if err := checkNotLocked(credential); err != nil {
+		return 0, err
+	}
+	secret, err := decryptSecret(s.secretCipher, credential.SecretEncrypted)
+	if err != nil {
+		return 0, errors.Wrap(err, "decrypt totp secret")
+	}
+	step, ok := verifyTOTPCode(
+		secret, code, time.Now(), s.cfg.TOTPSkew, credential.LastUsedStep)
+	if !ok {
+		return 0, s.registerFailedAttempt(credential.UserID, "code is invalid")
+	}
+
+	return step, nil
+}
+
+// registerFailedAttempt counts a failed attempt and locks verification once
+// the attempts limit is reached. It returns the error for the attempt.
+func (s *MFAService) registerFailedAttempt(userID uuid.UUID, message string) error {
+	failedAttempts, err := s.totpCredentialRepository.IncrementFailedAttempts(userID)
+	if err != nil {
+		return errors.Wrap(err, "increment failed attempts")
+	}
+	if failedAttempts < s.cfg.MaxFailedAttempts {
+		return &domain.UnauthorizedError{Message: message}
+	}
+
+	lockedUntil := time.Now().Add(s.cfg.LockoutDuration)
+	err = s.totpCredentialRepository.Lock(userID, lockedUntil)
+	if err != nil {
+		return errors.Wrap(err, "lock totp credential")
+	}
+
+	return &domain.MFALockedError{LockedUntil: lockedUntil}
+}
+
+func (s *MFAService) replaceRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes(s.cfg.RecoveryCodesCount)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate recovery codes")
+	}
+	err = s.recoveryCodeRepository.Replace(userID, hashes)
+	if err != nil {
+		return nil, errors.Wrap(err, "replace recovery codes")
+	}
+
+	return codes, nil
+}
+
+func checkNotLocked(credential *domain.TOTPCredential) error {
+	if time.Now().Before(credential.LockedUntil) {
+		return &domain.MFALockedError{LockedUntil: credential.LockedUntil}
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package mfaservice
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/mfa-service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mfaConfig = config.MFAConfig{
+		TOTPSecretKey:         "totp-secret-key",
+		Issuer:                "Company",
+		TOTPSkew:              1,
+		MaxFailedAttempts:     3,
+		LockoutDuration:       time.Minute * 15,
+		RecoveryCodesCount:    10,
+		PendingStepUpDuration: time.Minute * 5,
+	}
+
+	userID         = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
+	refreshTokenID = uuid.MustParse("3e02eeb9-de9a-4e0a-857b-1293c25bd776")
+	userEmail      = "user@example.com"
+	totpSecret     = []byte("12345678901234567890")
+)
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// SHA1 test vectors of RFC 6238 truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unixTime, code := range vectors {
+		assert.Equal(t, code, totpCode(totpSecret, totpStep(time.Unix(unixTime, 0))))
+	}
+}
+
+func TestVerifyTOTPCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	matchedStep, ok := verifyTOTPCode(totpSecret, totpCode(totpSecret, step-1), now, 1, 0)
+	assert.True(t, ok, "previous step is within skew")
+	assert.Equal(t, step-1, matchedStep)
+
+	_, ok = verifyTOTPCode(totpSecret, totpCode(totpSecret, step+2), now, 1, 0)
+	assert.False(t, ok, "step is out of skew")
+
+	_, ok = verifyTOTPCode(totpSecret, totpCode(totpSecret, step), now, 1, step)
+	assert.False(t, ok, "step is already used")
+
+	_, ok = verifyTOTPCode(totpSecret, "12345", now, 1, 0)
+	assert.False(t, ok)
+}
+
+func TestEnrollTOTP_Success(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	userRepository := service.userRepository.(*mocks.UserRepository)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(nil, nil)
+	userRepository.
+		On("GetUserEmail", userID).
+		Return(userEmail, nil)
+	var saved *domain.TOTPCredential
+	totpCredentialRepository.
+		On("Save", mock.AnythingOfType("*domain.TOTPCredential")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*domain.TOTPCredential) }).
+		Return(nil)
+
+	enrollment, err := service.EnrollTOTP(userID)
+	require.NoError(t, err)
+
+	secret, err := totpSecretEncoding.DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+	assert.Len(t, secret, totpSecretSize)
+	assert.False(t, saved.Confirmed)
+	decrypted, err := decryptSecret(service.secretCipher, saved.SecretEncrypted)
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypted)
+
+	uri, err := url.Parse(enrollment.OTPAuthURI)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Company:user@example.com", uri.Path)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Company", uri.Query().Get("issuer"))
+}
+
+func TestEnrollTOTP_AlreadyConfirmed(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, true, 0), nil)
+
+	var conflictError *domain.ConflictError
+	_, err := service.EnrollTOTP(userID)
+	assert.ErrorAs(t, err, &conflictError)
+}
+
+func TestConfirmTOTP_Success(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	recoveryCodeRepository := service.recoveryCodeRepository.(*mocks.RecoveryCodeRepository)
+	step := totpStep(time.Now())
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, false, 0), nil)
+	totpCredentialRepository.
+		On("Confirm", userID, step).
+		Return(nil)
+	var savedHashes [][]byte
+	recoveryCodeRepository.
+		On("Replace", userID, mock.Anything).
+		Run(func(args mock.Arguments) { savedHashes = args.Get(1).([][]byte) }).
+		Return(nil)
+
+	recoveryCodes, err := service.ConfirmTOTP(userID, totpCode(totpSecret, step))
+
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, mfaConfig.RecoveryCodesCount)
+	assert.Len(t, savedHashes, mfaConfig.RecoveryCodesCount)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, recoveryCodes[0])
+	assert.Equal(t, hashRecoveryCode(recoveryCodes[0]), savedHashes[0])
+}
+
+func TestStepUpSession_TOTP(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	stepUpper := service.stepUpper.(*mocks.StepUpper)
+	step := totpStep(time.Now())
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, true, step-1), nil)
+	totpCredentialRepository.
+		On("UseStep", userID, step).
+		Return(true, nil)
+	stepUpper.
+		On("StepUpSession", userID, refreshTokenID, []string{domain.AMROTP, domain.AMRMFA}).
+		Return(nil)
+
+	err := service.StepUpSession(
+		&domain.AccessToken{UserID: userID, RefreshTokenID: refreshTokenID},
+		domain.SecondFactor{TOTPCode: totpCode(totpSecret, step)})
+	assert.NoError(t, err)
+}
+
+func TestStepUpSession_ReplayedCode(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	step := totpStep(time.Now())
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, true, step), nil)
+	totpCredentialRepository.
+		On("IncrementFailedAttempts", userID).
+		Return(1, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	err := service.StepUpSession(
+		&domain.AccessToken{UserID: userID, RefreshTokenID: refreshTokenID},
+		domain.SecondFactor{TOTPCode: totpCode(totpSecret, step)})
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestStepUpSession_Lockout(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, true, 0), nil)
+	totpCredentialRepository.
+		On("IncrementFailedAttempts", userID).
+		Return(mfaConfig.MaxFailedAttempts, nil)
+	totpCredentialRepository.
+		On("Lock", userID, mock.MatchedBy(func(lockedUntil time.Time) bool {
+			return time.Until(lockedUntil) > mfaConfig.LockoutDuration-time.Minute
+		})).
+		Return(nil)
+
+	var mfaLockedError *domain.MFALockedError
+	err := service.StepUpSession(
+		&domain.AccessToken{UserID: userID, RefreshTokenID: refreshTokenID},
+		domain.SecondFactor{TOTPCode: "000000"})
+	assert.ErrorAs(t, err, &mfaLockedError)
+}
+
+func TestStepUpUser_Locked(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	credential := newCredential(t, service, true, 0)
+	credential.LockedUntil = time.Now().Add(time.Minute)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(credential, nil)
+
+	var mfaLockedError *domain.MFALockedError
+	_, err := service.StepUpUser(userID, domain.SecondFactor{RecoveryCode: "abcde-fghij"})
+	assert.ErrorAs(t, err, &mfaLockedError)
+}
+
+func TestStepUpUser_RecoveryCode(t *testing.T) {
+	service := newService(t)
+	stepUpTicket := uuid.New()
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	recoveryCodeRepository := service.recoveryCodeRepository.(*mocks.RecoveryCodeRepository)
+	stepUpper := service.stepUpper.(*mocks.StepUpper)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, true, 0), nil)
+	recoveryCodeRepository.
+		On("Use", userID, hashRecoveryCode("abcdefghij")).
+		Return(true, nil)
+	stepUpper.
+		On("StepUpUser", userID, []string{domain.AMRRecoveryCode, domain.AMRMFA},
+			mock.MatchedBy(func(expirationTime time.Time) bool {
+				return time.Until(expirationTime) > mfaConfig.PendingStepUpDuration-time.Minute
+			})).
+		Return(stepUpTicket, nil)
+
+	ticket, err := service.StepUpUser(userID, domain.SecondFactor{RecoveryCode: "ABCDE-FGHIJ"})
+	assert.NoError(t, err)
+	assert.Equal(t, stepUpTicket, ticket)
+}
+
+func TestStepUpUser_NotEnrolled(t *testing.T) {
+	service := newService(t)
+	totpCredentialRepository := service.totpCredentialRepository.(*mocks.TOTPCredentialRepository)
+	totpCredentialRepository.
+		On("GetByUserID", userID).
+		Return(newCredential(t, service, false, 0), nil)
+
+	var notFoundError *domain.NotFoundError
+	_, err := service.StepUpUser(userID, domain.SecondFactor{TOTPCode: "000000"})
+	assert.ErrorAs(t, err, &notFoundError)
+}
+
+func newService(t *testing.T) *MFAService {
+	service, err := NewMFAService(
+		mfaConfig,
+		mocks.NewTOTPCredentialRepository(t),
+		mocks.NewRecoveryCodeRepository(t),
+		mocks.NewUserRepository(t),
+		mocks.NewStepUpper(t),
+	)
+	require.NoError(t, err)
+
+	return service
+}
+
+func newCredential(
+	t *testing.T, service *MFAService, confirmed bool, lastUsedStep int64,
+) *domain.TOTPCredential {
+
+	secretEncrypted, err := encryptSecret(service.secretCipher, totpSecret)
+	require.NoError(t, err)
+
+	return &domain.TOTPCredential{
+		UserID:          userID,
+		SecretEncrypted: secretEncrypted,
+		Confirmed:       confirmed,
+		LastUsedStep:    lastUsedStep,
+		CreationTime:    time.Now(),
+	}
+}
@@ -0,0 +1,86 @@
+package mfaservice
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	recoveryCodeSize = 10
+	// recovery codes are shown as two groups of 5 characters
+	recoveryCodeGroupSize = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newSecretCipher derives an AES-256 key from the configured key, so any
+// string can be used as the key.
+func newSecretCipher(key string) (cipher.AEAD, error) {
+	derivedKey := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derivedKey[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "create aes cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "create gcm")
+	}
+
+	return aead, nil
+}
+
+// encryptSecret returns the nonce followed by the sealed secret.
+func encryptSecret(aead cipher.AEAD, secret []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "read random bytes")
+	}
+
+	return aead.Seal(nonce, nonce, secret, nil), nil
+}
+
+func decryptSecret(aead cipher.AEAD, encrypted []byte) ([]byte, error) {
+	if len(encrypted) < aead.NonceSize() {
+		return nil, errors.New("encrypted secret is too short")
+	}
+	nonce, sealed := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "open sealed secret")
+	}
+
+	return secret, nil
+}
+
+// generateRecoveryCodes returns codes shown to the user and their hashes.
+func generateRecoveryCodes(count int) ([]string, [][]byte, error) {
+	codes := make([]string, 0, count)
+	hashes := make([][]byte, 0, count)
+	for range count {
+		codeBytes := make([]byte, recoveryCodeSize*5/8)
+		if _, err := rand.Read(codeBytes); err != nil {
+			return nil, nil, errors.Wrap(err, "read random bytes")
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(codeBytes))
+		codes = append(codes,
+			code[:recoveryCodeGroupSize]+"-"+code[recoveryCodeGroupSize:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes the code ignoring case, spaces and dashes. Codes
+// have 50 bits of entropy, so a fast hash is enough.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+
+	return hash[:]
+}
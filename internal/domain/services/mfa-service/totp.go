@@ -0,0 +1,89 @@
+package mfaservice
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TOTP parameters of RFC 6238 which all authenticator apps support.
+const (
+	totpSecretSize = 20
+	totpPeriod     = 30
+	totpDigits     = 6
+)
+
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTOTPSecret() ([]byte, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "read random bytes")
+	}
+
+	return secret, nil
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// totpCode computes the HOTP code of RFC 4226 for the time step.
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// verifyTOTPCode returns the time step the code matches within skew steps of
+// now. Steps up to lastUsedStep are skipped, so an accepted code can't be
+// used again.
+func verifyTOTPCode(
+	secret []byte, code string, now time.Time, skew int, lastUsedStep int64,
+) (int64, bool) {
+
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	currentStep := totpStep(now)
+	for step := currentStep - int64(skew); step <= currentStep+int64(skew); step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// otpAuthURI returns the key URI understood by authenticator apps, usually
+// shown as a QR code.
+func otpAuthURI(issuer, accountName string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", totpSecretEncoding.EncodeToString(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+accountName) +
+		"?" + query.Encode()
+}
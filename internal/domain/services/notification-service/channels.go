@@ -0,0 +1,106 @@
+package notificationservice
+
+import (
+	"auth/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockery --name TemplateRenderer --filename template_renderer.go
+type TemplateRenderer interface {
+	Render(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) (domain.NotificationContent, error)
+	RenderSMS(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) (string, error)
+}
+
+//go:generate mockery --name EmailService --filename email_service.go
+type EmailService interface {
+	SendSupportEmailToUser(userID uuid.UUID, content domain.NotificationContent) error
+}
+
+// EmailChannel sends notifications over SMTP.
+type EmailChannel struct {
+	templateRenderer TemplateRenderer
+	emailService     EmailService
+}
+
+func NewEmailChannel(templateRenderer TemplateRenderer, emailService EmailService) *EmailChannel {
+	return &EmailChannel{
+		templateRenderer: templateRenderer,
+		emailService:     emailService,
+	}
+}
+
+func (c *EmailChannel) Name() domain.NotificationChannel {
+	return domain.NotificationChannelEmail
+}
+
+func (c *EmailChannel) Render(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) (domain.NotificationContent, error) {
+
+	return c.templateRenderer.Render(userID, event, session)
+}
+
+func (c *EmailChannel) Send(userID uuid.UUID, content domain.NotificationContent) error {
+	return errors.Wrap(
+		c.emailService.SendSupportEmailToUser(userID, content), "send email")
+}
+
+// InAppChannel puts notifications to the user inbox. They have the subject
+// and the text body of emails without the "This wasn't me" link, since the
+// inbox is stored and the user is signed in to read it anyway.
+type InAppChannel struct {
+	templateRenderer            TemplateRenderer
+	inAppNotificationRepository InAppNotificationRepository
+}
+
+func NewInAppChannel(
+	templateRenderer TemplateRenderer,
+	inAppNotificationRepository InAppNotificationRepository,
+) *InAppChannel {
+
+	return &InAppChannel{
+		templateRenderer:            templateRenderer,
+		inAppNotificationRepository: inAppNotificationRepository,
+	}
+}
+
+func (c *InAppChannel) Name() domain.NotificationChannel {
+	return domain.NotificationChannelInApp
+}
+
+func (c *InAppChannel) Render(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) (domain.NotificationContent, error) {
+
+	session.RevokeLink = nil
+	content, err := c.templateRenderer.Render(userID, event, session)
+	if err != nil {
+		return domain.NotificationContent{}, err
+	}
+
+	return domain.NotificationContent{
+		Subject:  content.Subject,
+		TextBody: content.TextBody,
+	}, nil
+}
+
+func (c *InAppChannel) Send(userID uuid.UUID, content domain.NotificationContent) error {
+	err := c.inAppNotificationRepository.Create(&domain.InAppNotification{
+		UserID:       userID,
+		Title:        content.Subject,
+		Body:         content.TextBody,
+		CreationTime: time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "create in-app notification")
+	}
+
+	return nil
+}
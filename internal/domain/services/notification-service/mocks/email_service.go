@@ -15,13 +15,13 @@ type EmailService struct {
 	mock.Mock
 }
 
-// SendSupportEmailToUser provides a mock function with given fields: userID, emailContent
-func (_m *EmailService) SendSupportEmailToUser(userID uuid.UUID, emailContent domain.EmailContent) error {
-	ret := _m.Called(userID, emailContent)
+// SendSupportEmailToUser provides a mock function with given fields: userID, content
+func (_m *EmailService) SendSupportEmailToUser(userID uuid.UUID, content domain.NotificationContent) error {
+	ret := _m.Called(userID, content)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uuid.UUID, domain.EmailContent) error); ok {
-		r0 = rf(userID, emailContent)
+	if rf, ok := ret.Get(0).(func(uuid.UUID, domain.NotificationContent) error); ok {
+		r0 = rf(userID, content)
 	} else {
 		r0 = ret.Error(0)
 	}
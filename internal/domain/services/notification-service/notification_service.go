@@ -0,0 +1,353 @@
+package notificationservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// claimLease is how long a claimed notification is hidden from other
+	// workers. It must exceed the time needed to send a notification.
+	claimLease = time.Minute * 5
+
+	sentNotificationsRetention    = time.Hour * 24 * 7
+	deleteSentNotificationsPeriod = time.Hour
+	maxLastErrorLength            = 1000
+)
+
+// ErrNoRecipient is returned by Channel.Render if the user can't be reached
+// through the channel, like a user without a phone number for SMS.
+var ErrNoRecipient = errors.New("user has no recipient address for the channel")
+
+// NotificationService routes notifications about events to channels and
+// stores them in the outbox, from which a pool of workers sends them.
+// Failed notifications are retried with exponential backoff and moved to the
+// dead-letter state after the attempts limit.
+type NotificationService struct {
+	notificationOutboxRepository NotificationOutboxRepository
+	inAppNotificationRepository  InAppNotificationRepository
+	revokeLinkSigner             RevokeLinkSigner
+	channels                     map[domain.NotificationChannel]Channel
+	routes                       map[domain.NotificationEvent][]Channel
+	cfg                          config.NotificationsConfig
+}
+
+//go:generate mockery --name NotificationOutboxRepository --filename notification_outbox_repository.go
+type NotificationOutboxRepository interface {
+	ClaimDue(limit int, lease time.Duration) ([]domain.OutboxNotification, error)
+	MarkSent(id uuid.UUID) error
+	MarkFailed(id uuid.UUID, lastError string, nextAttemptTime time.Time, dead bool) error
+	GetDead(limit, offset int) ([]domain.OutboxNotification, error)
+	Replay(id uuid.UUID) (bool, error)
+	DeleteSentBefore(t time.Time) error
+}
+
+//go:generate mockery --name InAppNotificationRepository --filename in_app_notification_repository.go
+type InAppNotificationRepository interface {
+	Create(notification *domain.InAppNotification) error
+	GetByUserID(userID uuid.UUID, limit, offset int) ([]domain.InAppNotification, error)
+	MarkRead(userID, id uuid.UUID) (bool, error)
+}
+
+// RevokeLinkSigner signs "This wasn't me" links of notifications when they
+// are sent.
+//
+//go:generate mockery --name RevokeLinkSigner --filename revoke_link_signer.go
+type RevokeLinkSigner interface {
+	SignRevokeLink(link *domain.RevokeLink) (string, error)
+}
+
+// Channel delivers notifications to users.
+//
+//go:generate mockery --name Channel --filename channel.go
+type Channel interface {
+	Name() domain.NotificationChannel
+	// Render is called when the notification is stored in the outbox, so it
+	// is sent as it was at the time of the event.
+	Render(
+		userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+	) (domain.NotificationContent, error)
+	Send(userID uuid.UUID, content domain.NotificationContent) error
+}
+
+// NewNotificationService checks that cfg.Routes refer only to the given
+// channels and starts the outbox workers.
+func NewNotificationService(
+	cfg config.NotificationsConfig,
+	notificationOutboxRepository NotificationOutboxRepository,
+	inAppNotificationRepository InAppNotificationRepository,
+	revokeLinkSigner RevokeLinkSigner,
+	channels ...Channel,
+) (*NotificationService, error) {
+
+	channelsByName := make(map[domain.NotificationChannel]Channel)
+	for _, channel := range channels {
+		channelsByName[channel.Name()] = channel
+	}
+	routes, err := parseRoutes(cfg.Routes, channelsByName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &NotificationService{
+		notificationOutboxRepository: notificationOutboxRepository,
+		inAppNotificationRepository:  inAppNotificationRepository,
+		revokeLinkSigner:             revokeLinkSigner,
+		channels:                     channelsByName,
+		routes:                       routes,
+		cfg:                          cfg,
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.PollPeriod)
+		for {
+			<-ticker.C
+			s.SendDue()
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(deleteSentNotificationsPeriod)
+		for {
+			<-ticker.C
+			err := notificationOutboxRepository.DeleteSentBefore(
+				time.Now().Add(-sentNotificationsRetention))
+			if err != nil {
+				slogutils.Error("delete sent outbox notifications", err)
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+func parseRoutes(
+	routes map[string]string, channels map[domain.NotificationChannel]Channel,
+) (map[domain.NotificationEvent][]Channel, error) {
+
+	parsed := make(map[domain.NotificationEvent][]Channel)
+	for event, channelNames := range routes {
+		for _, name := range strings.Split(channelNames, "|") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			channel, ok := channels[domain.NotificationChannel(name)]
+			if !ok {
+				return nil, errors.Errorf(
+					"route of %q refers to unknown or disabled channel %q", event, name)
+			}
+			parsed[domain.NotificationEvent(event)] = append(
+				parsed[domain.NotificationEvent(event)], channel)
+		}
+	}
+
+	return parsed, nil
+}
+
+// NewNotifications renders the event for every channel it is routed to.
+// The caller stores the notifications in the outbox, possibly in the same
+// transaction with the event. Notifications are not critical, so channels
+// failing to render are logged and skipped.
+func (s *NotificationService) NewNotifications(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) []*domain.OutboxNotification {
+
+	var notifications []*domain.OutboxNotification
+	for _, channel := range s.routes[event] {
+		content, err := channel.Render(userID, event, session)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrNoRecipient):
+			slog.Debug("user has no recipient address for the channel",
+				"userID", userID, "channel", channel.Name())
+			continue
+		default:
+			slogutils.Error("render notification", err,
+				"event", event, "channel", channel.Name())
+			continue
+		}
+		notification := domain.NewOutboxNotification(userID, channel.Name(), content)
+		if hasRevokeLink(content) {
+			notification.RevokeLink = session.RevokeLink
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications
+}
+
+// SendDue sends notifications due for sending until there are none left.
+func (s *NotificationService) SendDue() {
+	for {
+		notifications, err := s.notificationOutboxRepository.ClaimDue(s.cfg.Workers, claimLease)
+		if err != nil {
+			slogutils.Error("claim outbox notifications", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, notification := range notifications {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.send(notification)
+			}()
+		}
+		wg.Wait()
+
+		if len(notifications) < s.cfg.Workers {
+			return
+		}
+	}
+}
+
+func (s *NotificationService) send(notification domain.OutboxNotification) {
+	var sendErr error
+	channel, ok := s.channels[notification.Channel]
+	if ok {
+		var content domain.NotificationContent
+		content, sendErr = s.signRevokeLink(notification)
+		if sendErr == nil {
+			sendErr = channel.Send(notification.UserID, content)
+		}
+	} else {
+		// the channel was disabled after the notification was stored, it
+		// may be enabled again before the notification is dead
+		sendErr = errors.Errorf("channel %q is disabled", notification.Channel)
+	}
+	if sendErr == nil {
+		if err := s.notificationOutboxRepository.MarkSent(notification.ID); err != nil {
+			slogutils.Error("mark outbox notification sent", err,
+				"notificationID", notification.ID)
+		}
+		return
+	}
+
+	attempts := notification.Attempts + 1
+	dead := attempts >= s.cfg.MaxAttempts
+	if dead {
+		slogutils.Error("outbox notification is dead", sendErr,
+			"notificationID", notification.ID, "channel", notification.Channel)
+	}
+	lastError := sendErr.Error()
+	if len(lastError) > maxLastErrorLength {
+		lastError = lastError[:maxLastErrorLength]
+	}
+	// dead notifications keep the time of the last attempt
+	nextAttemptTime := time.Now()
+	if !dead {
+		nextAttemptTime = nextAttemptTime.Add(s.backoff(attempts))
+	}
+	err := s.notificationOutboxRepository.MarkFailed(
+		notification.ID, lastError, nextAttemptTime, dead)
+	if err != nil {
+		slogutils.Error("mark outbox notification failed", err,
+			"notificationID", notification.ID)
+	}
+}
+
+// signRevokeLink returns the notification content with the signed revoke
+// link token in place of the placeholder.
+func (s *NotificationService) signRevokeLink(
+	notification domain.OutboxNotification,
+) (domain.NotificationContent, error) {
+
+	content := notification.Content
+	if notification.RevokeLink == nil {
+		return content, nil
+	}
+	token, err := s.revokeLinkSigner.SignRevokeLink(notification.RevokeLink)
+	if err != nil {
+		return domain.NotificationContent{}, err
+	}
+	replacer := strings.NewReplacer(domain.RevokeLinkTokenPlaceholder, token)
+	content.Subject = replacer.Replace(content.Subject)
+	content.TextBody = replacer.Replace(content.TextBody)
+	content.HTMLBody = replacer.Replace(content.HTMLBody)
+
+	return content, nil
+}
+
+func hasRevokeLink(content domain.NotificationContent) bool {
+	for _, part := range []string{content.Subject, content.TextBody, content.HTMLBody} {
+		if strings.Contains(part, domain.RevokeLinkTokenPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns delay before the next attempt: MinBackoff doubled for
+// every failed attempt, capped by MaxBackoff, with up to 10% jitter.
+func (s *NotificationService) backoff(attempts int) time.Duration {
+	backoff := s.cfg.MaxBackoff
+	if attempts-1 < 32 {
+		backoff = min(s.cfg.MinBackoff<<(attempts-1), s.cfg.MaxBackoff)
+		if backoff <= 0 {
+			backoff = s.cfg.MaxBackoff
+		}
+	}
+	return backoff + time.Duration(rand.Int64N(int64(backoff/10)+1))
+}
+
+// GetDeadNotifications returns notifications which failed to be sent, most
+// recent first.
+func (s *NotificationService) GetDeadNotifications(
+	limit, offset int,
+) ([]domain.OutboxNotification, error) {
+
+	notifications, err := s.notificationOutboxRepository.GetDead(limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "get dead outbox notifications")
+	}
+
+	return notifications, nil
+}
+
+// ReplayDeadNotification schedules a dead notification for sending with
+// attempts reset.
+func (s *NotificationService) ReplayDeadNotification(id uuid.UUID) error {
+	replayed, err := s.notificationOutboxRepository.Replay(id)
+	if err != nil {
+		return errors.Wrap(err, "replay outbox notification")
+	}
+	if !replayed {
+		return &domain.NotFoundError{Message: "dead notification not found"}
+	}
+
+	return nil
+}
+
+// GetInAppNotifications returns the user inbox, most recent first.
+func (s *NotificationService) GetInAppNotifications(
+	userID uuid.UUID, limit, offset int,
+) ([]domain.InAppNotification, error) {
+
+	notifications, err := s.inAppNotificationRepository.GetByUserID(userID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "get in-app notifications")
+	}
+
+	return notifications, nil
+}
+
+func (s *NotificationService) MarkInAppNotificationRead(userID, id uuid.UUID) error {
+	marked, err := s.inAppNotificationRepository.MarkRead(userID, id)
+	if err != nil {
+		return errors.Wrap(err, "mark in-app notification read")
+	}
+	if !marked {
+		return &domain.NotFoundError{Message: "notification not found"}
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package notificationservice
+
+import (
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/notification-service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	notificationsConfig = config.NotificationsConfig{
+		Workers:     2,
+		PollPeriod:  time.Hour,
+		MaxAttempts: 3,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+	}
+
+	outboxNotification = domain.OutboxNotification{
+		ID:      uuid.New(),
+		UserID:  uuid.New(),
+		Channel: domain.NotificationChannelEmail,
+		Content: domain.NotificationContent{
+			Subject:  "subject",
+			TextBody: "body",
+		},
+		Status: domain.OutboxPending,
+	}
+
+	sessionDetails = domain.SessionDetails{
+		SessionID: uuid.New(),
+		Time:      time.Now(),
+		IP:        "203.0.113.1",
+		UserAgent: "curl/8.0",
+	}
+)
+
+func TestSendDue_Sent(t *testing.T) {
+	service, email, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+
+	repository.
+		On("ClaimDue", notificationsConfig.Workers, claimLease).
+		Return([]domain.OutboxNotification{outboxNotification}, nil)
+	email.
+		On("Send", outboxNotification.UserID, outboxNotification.Content).
+		Return(nil)
+	repository.
+		On("MarkSent", outboxNotification.ID).
+		Return(nil)
+
+	service.SendDue()
+}
+
+func TestSendDue_Retry(t *testing.T) {
+	service, email, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+
+	repository.
+		On("ClaimDue", notificationsConfig.Workers, claimLease).
+		Return([]domain.OutboxNotification{outboxNotification}, nil)
+	email.
+		On("Send", outboxNotification.UserID, outboxNotification.Content).
+		Return(errors.New("smtp is down"))
+	repository.
+		On("MarkFailed", outboxNotification.ID, "smtp is down",
+			mock.MatchedBy(func(nextAttemptTime time.Time) bool {
+				return time.Until(nextAttemptTime) > notificationsConfig.MinBackoff/2
+			}),
+			false).
+		Return(nil)
+
+	service.SendDue()
+}
+
+func TestSendDue_Dead(t *testing.T) {
+	service, email, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+
+	notification := outboxNotification
+	notification.Attempts = notificationsConfig.MaxAttempts - 1
+	repository.
+		On("ClaimDue", notificationsConfig.Workers, claimLease).
+		Return([]domain.OutboxNotification{notification}, nil)
+	email.
+		On("Send", notification.UserID, notification.Content).
+		Return(errors.New("mailbox does not exist"))
+	repository.
+		On("MarkFailed", notification.ID, "mailbox does not exist",
+			mock.AnythingOfType("time.Time"), true).
+		Return(nil)
+
+	service.SendDue()
+}
+
+func TestSendDue_RevokeLink(t *testing.T) {
+	service, email, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+	signer := service.revokeLinkSigner.(*mocks.RevokeLinkSigner)
+
+	notification := outboxNotification
+	notification.Content.TextBody = "revoke: https://example.com/revoke?token=" +
+		domain.RevokeLinkTokenPlaceholder
+	notification.RevokeLink = &domain.RevokeLink{ID: uuid.New(), UserID: notification.UserID}
+	repository.
+		On("ClaimDue", notificationsConfig.Workers, claimLease).
+		Return([]domain.OutboxNotification{notification}, nil)
+	signer.
+		On("SignRevokeLink", notification.RevokeLink).
+		Return("signed-token", nil)
+	email.
+		On("Send", notification.UserID, domain.NotificationContent{
+			Subject:  notification.Content.Subject,
+			TextBody: "revoke: https://example.com/revoke?token=signed-token",
+		}).
+		Return(nil)
+	repository.
+		On("MarkSent", notification.ID).
+		Return(nil)
+
+	service.SendDue()
+}
+
+func TestSendDue_DisabledChannel(t *testing.T) {
+	service, _, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+
+	notification := outboxNotification
+	notification.Channel = domain.NotificationChannelSMS
+	repository.
+		On("ClaimDue", notificationsConfig.Workers, claimLease).
+		Return([]domain.OutboxNotification{notification}, nil)
+	repository.
+		On("MarkFailed", notification.ID, `channel "sms" is disabled`,
+			mock.AnythingOfType("time.Time"), false).
+		Return(nil)
+
+	service.SendDue()
+}
+
+func TestNewNotifications(t *testing.T) {
+	service, email, inApp := newService(t)
+	userID := uuid.New()
+
+	email.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, sessionDetails).
+		Return(outboxNotification.Content, nil)
+	inApp.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, sessionDetails).
+		Return(domain.NotificationContent{}, ErrNoRecipient)
+
+	notifications := service.NewNotifications(
+		userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+
+	if assert.Len(t, notifications, 1) {
+		assert.Equal(t, userID, notifications[0].UserID)
+		assert.Equal(t, domain.NotificationChannelEmail, notifications[0].Channel)
+		assert.Equal(t, outboxNotification.Content, notifications[0].Content)
+		assert.Equal(t, domain.OutboxPending, notifications[0].Status)
+	}
+}
+
+func TestNewNotifications_RevokeLink(t *testing.T) {
+	service, email, inApp := newService(t)
+	userID := uuid.New()
+	session := sessionDetails
+	session.RevokeLink = &domain.RevokeLink{ID: uuid.New(), UserID: userID}
+
+	emailContent := domain.NotificationContent{
+		Subject:  "subject",
+		TextBody: "revoke: https://example.com/revoke?token=" + domain.RevokeLinkTokenPlaceholder,
+	}
+	email.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, session).
+		Return(emailContent, nil)
+	inApp.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, session).
+		Return(outboxNotification.Content, nil)
+
+	notifications := service.NewNotifications(
+		userID, domain.NotificationRefreshFromNewIP, session)
+
+	if assert.Len(t, notifications, 2) {
+		assert.Equal(t, session.RevokeLink, notifications[0].RevokeLink)
+		// the in-app content has no link, so there is nothing to sign
+		assert.Nil(t, notifications[1].RevokeLink)
+	}
+}
+
+func TestNewNotifications_RenderError(t *testing.T) {
+	service, email, inApp := newService(t)
+	userID := uuid.New()
+
+	email.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, sessionDetails).
+		Return(domain.NotificationContent{}, errors.New("template not found"))
+	inApp.
+		On("Render", userID, domain.NotificationRefreshFromNewIP, sessionDetails).
+		Return(outboxNotification.Content, nil)
+
+	notifications := service.NewNotifications(
+		userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+
+	if assert.Len(t, notifications, 1) {
+		assert.Equal(t, domain.NotificationChannelInApp, notifications[0].Channel)
+	}
+}
+
+func TestParseRoutes_UnknownChannel(t *testing.T) {
+	_, err := parseRoutes(
+		map[string]string{string(domain.NotificationRefreshFromNewIP): "email|sms"},
+		map[domain.NotificationChannel]Channel{
+			domain.NotificationChannelEmail: newChannel(t, domain.NotificationChannelEmail),
+		})
+
+	assert.Error(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	service, _, _ := newService(t)
+
+	for attempts, expected := range map[int]time.Duration{
+		1:   time.Second,
+		2:   time.Second * 2,
+		4:   time.Second * 8,
+		7:   time.Minute,
+		100: time.Minute,
+	} {
+		backoff := service.backoff(attempts)
+		assert.GreaterOrEqual(t, backoff, expected)
+		assert.LessOrEqual(t, backoff, expected+expected/10)
+	}
+}
+
+func TestReplayDeadNotification_NotFound(t *testing.T) {
+	service, _, _ := newService(t)
+	repository := service.notificationOutboxRepository.(*mocks.NotificationOutboxRepository)
+	repository.
+		On("Replay", outboxNotification.ID).
+		Return(false, nil)
+
+	var notFoundError *domain.NotFoundError
+	assert.ErrorAs(t, service.ReplayDeadNotification(outboxNotification.ID), &notFoundError)
+}
+
+func TestMarkInAppNotificationRead_NotFound(t *testing.T) {
+	service, _, _ := newService(t)
+	repository := service.inAppNotificationRepository.(*mocks.InAppNotificationRepository)
+	userID, id := uuid.New(), uuid.New()
+	repository.
+		On("MarkRead", userID, id).
+		Return(false, nil)
+
+	var notFoundError *domain.NotFoundError
+	assert.ErrorAs(t, service.MarkInAppNotificationRead(userID, id), &notFoundError)
+}
+
+// newService creates service without background workers with email and
+// in-app channels, both routed for domain.NotificationRefreshFromNewIP.
+func newService(t *testing.T) (*NotificationService, *mocks.Channel, *mocks.Channel) {
+	email := newChannel(t, domain.NotificationChannelEmail)
+	inApp := newChannel(t, domain.NotificationChannelInApp)
+
+	return &NotificationService{
+		notificationOutboxRepository: mocks.NewNotificationOutboxRepository(t),
+		inAppNotificationRepository:  mocks.NewInAppNotificationRepository(t),
+		revokeLinkSigner:             mocks.NewRevokeLinkSigner(t),
+		channels: map[domain.NotificationChannel]Channel{
+			domain.NotificationChannelEmail: email,
+			domain.NotificationChannelInApp: inApp,
+		},
+		routes: map[domain.NotificationEvent][]Channel{
+			domain.NotificationRefreshFromNewIP: {email, inApp},
+		},
+		cfg: notificationsConfig,
+	}, email, inApp
+}
+
+func newChannel(t *testing.T, name domain.NotificationChannel) *mocks.Channel {
+	channel := mocks.NewChannel(t)
+	channel.On("Name").Return(name).Maybe()
+
+	return channel
+}
@@ -0,0 +1,100 @@
+package notificationservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockery --name UserPhoneRepository --filename user_phone_repository.go
+type UserPhoneRepository interface {
+	// GetUserPhone returns an empty string if the user has no phone number.
+	GetUserPhone(userID uuid.UUID) (string, error)
+}
+
+// SMSChannel sends notifications through an SMS provider HTTP API. Messages
+// are posted as {"from", "to", "text"} JSON with the bearer auth token.
+type SMSChannel struct {
+	cfg                 config.SMSProviderConfig
+	templateRenderer    TemplateRenderer
+	userPhoneRepository UserPhoneRepository
+	httpClient          *http.Client
+}
+
+type smsMessage struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+func NewSMSChannel(
+	cfg config.SMSProviderConfig,
+	templateRenderer TemplateRenderer,
+	userPhoneRepository UserPhoneRepository,
+) *SMSChannel {
+
+	return &SMSChannel{
+		cfg:                 cfg,
+		templateRenderer:    templateRenderer,
+		userPhoneRepository: userPhoneRepository,
+		httpClient:          &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (c *SMSChannel) Name() domain.NotificationChannel {
+	return domain.NotificationChannelSMS
+}
+
+func (c *SMSChannel) Render(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) (domain.NotificationContent, error) {
+
+	phone, err := c.userPhoneRepository.GetUserPhone(userID)
+	if err != nil {
+		return domain.NotificationContent{}, errors.Wrap(err, "get user phone")
+	}
+	if phone == "" {
+		return domain.NotificationContent{}, ErrNoRecipient
+	}
+	text, err := c.templateRenderer.RenderSMS(userID, event, session)
+	if err != nil {
+		return domain.NotificationContent{}, err
+	}
+
+	return domain.NotificationContent{TextBody: text}, nil
+}
+
+// Send sends the SMS to the current phone number of the user.
+func (c *SMSChannel) Send(userID uuid.UUID, content domain.NotificationContent) error {
+	phone, err := c.userPhoneRepository.GetUserPhone(userID)
+	if err != nil {
+		return errors.Wrap(err, "get user phone")
+	}
+	if phone == "" {
+		return ErrNoRecipient
+	}
+
+	body, err := json.Marshal(smsMessage{
+		From: c.cfg.From,
+		To:   phone,
+		Text: content.TextBody,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal sms message")
+	}
+	req, err := http.NewRequest(http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create sms request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.AuthToken)
+	}
+
+	return errors.Wrap(doRequest(c.httpClient, req), "post sms")
+}
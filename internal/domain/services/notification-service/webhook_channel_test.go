@@ -0,0 +1,57 @@
+package notificationservice
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookChannel_Signature(t *testing.T) {
+	secret := "secret"
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeaderName)
+	}))
+	defer server.Close()
+	channel := NewWebhookChannel(config.NotificationWebhookConfig{
+		URL:     server.URL,
+		Secret:  secret,
+		Timeout: time.Second,
+	})
+	userID := uuid.New()
+
+	content, err := channel.Render(userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+	assert.NoError(t, err)
+	assert.NoError(t, channel.Send(userID, content))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	assert.Equal(t, content.TextBody, string(body))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func TestWebhookChannel_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	channel := NewWebhookChannel(config.NotificationWebhookConfig{
+		URL:     server.URL,
+		Timeout: time.Second,
+	})
+
+	assert.Error(t, channel.Send(uuid.New(), domain.NotificationContent{TextBody: "{}"}))
+}
@@ -0,0 +1,353 @@
+package templateservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	// time zones of users don't depend on the system tz database
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// subjectTemplate must be defined in every text template.
+const subjectTemplate = "subject"
+
+// TemplateService renders notifications in the language and time zone of
+// the user. Templates of an event are loaded from <language>/<event>.txt
+// (text/template, defines the subject), <language>/<event>.html
+// (html/template, optional) and <language>/sms/<event>.txt (text/template)
+// files, so a language is added by adding a directory to the override
+// templates directory.
+type TemplateService struct {
+	languages        map[string]*languageTemplates
+	defaultLanguage  string
+	defaultTimeZone  *time.Location
+	revokeSessionURL *url.URL
+	magicLinkURL     *url.URL
+	userRepository   UserRepository
+	ipLocator        IPLocator
+}
+
+type languageTemplates struct {
+	text map[domain.NotificationEvent]*texttemplate.Template
+	html map[domain.NotificationEvent]*htmltemplate.Template
+	sms  map[domain.NotificationEvent]*texttemplate.Template
+}
+
+//go:generate mockery --name UserRepository --filename user_repository.go
+type UserRepository interface {
+	GetUserLocale(userID uuid.UUID) (domain.UserLocale, error)
+}
+
+//go:generate mockery --name IPLocator --filename ip_locator.go
+type IPLocator interface {
+	// Locate returns a human readable location of the IP address in the
+	// language or an empty string if the location is unknown.
+	Locate(ip, language string) (string, error)
+}
+
+// TemplateData is passed to templates.
+type TemplateData struct {
+	// Time is in the user time zone.
+	Time      time.Time
+	IP        string
+	Location  string
+	UserAgent string
+	RevokeURL string
+	// Code is the one-time code of a refresh challenge.
+	Code string
+	// LoginURL is the passwordless login link.
+	LoginURL string
+}
+
+// NewTemplateService loads embedded templates and templates from
+// cfg.Dir. ipLocator is optional.
+func NewTemplateService(
+	cfg config.NotificationTemplatesConfig,
+	userRepository UserRepository,
+	ipLocator IPLocator,
+) (*TemplateService, error) {
+
+	defaultTimeZone, err := time.LoadLocation(cfg.DefaultTimeZone)
+	if err != nil {
+		return nil, errors.Wrap(err, "load default time zone")
+	}
+
+	embedded, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, errors.Wrap(err, "open embedded templates")
+	}
+	fileSystems := []fs.FS{embedded}
+	if cfg.Dir != "" {
+		fileSystems = append(fileSystems, os.DirFS(cfg.Dir))
+	}
+	var revokeSessionURL *url.URL
+	if cfg.RevokeSessionURL != "" {
+		revokeSessionURL, err = url.Parse(cfg.RevokeSessionURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse revoke session url")
+		}
+	}
+	var magicLinkURL *url.URL
+	if cfg.MagicLinkURL != "" {
+		magicLinkURL, err = url.Parse(cfg.MagicLinkURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse magic link url")
+		}
+	}
+
+	languages, err := loadTemplates(fileSystems)
+	if err != nil {
+		return nil, err
+	}
+	defaultLanguage := normalizeLanguage(cfg.DefaultLanguage)
+	if languages[defaultLanguage] == nil {
+		return nil, errors.Errorf("no templates for default language %q", defaultLanguage)
+	}
+
+	return &TemplateService{
+		languages:        languages,
+		defaultLanguage:  defaultLanguage,
+		defaultTimeZone:  defaultTimeZone,
+		revokeSessionURL: revokeSessionURL,
+		magicLinkURL:     magicLinkURL,
+		userRepository:   userRepository,
+		ipLocator:        ipLocator,
+	}, nil
+}
+
+// Render renders the subject, text and HTML body of the notification about
+// the session to the user. The user language falls back to its base language
+// and then to the default one.
+func (s *TemplateService) Render(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) (domain.NotificationContent, error) {
+
+	templates, data, err := s.prepare(userID, session,
+		func(templates *languageTemplates) bool {
+			return templates.text[event] != nil
+		})
+	if err != nil {
+		return domain.NotificationContent{}, err
+	}
+	if templates == nil {
+		return domain.NotificationContent{}, errors.Errorf("template %q not found", event)
+	}
+
+	var content domain.NotificationContent
+	var buf bytes.Buffer
+	textTemplate := templates.text[event]
+	if err := textTemplate.ExecuteTemplate(&buf, subjectTemplate, data); err != nil {
+		return domain.NotificationContent{}, errors.Wrap(err, "render subject")
+	}
+	content.Subject = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := textTemplate.Execute(&buf, data); err != nil {
+		return domain.NotificationContent{}, errors.Wrap(err, "render text body")
+	}
+	content.TextBody = buf.String()
+	if htmlTemplate := templates.html[event]; htmlTemplate != nil {
+		buf.Reset()
+		if err := htmlTemplate.Execute(&buf, data); err != nil {
+			return domain.NotificationContent{}, errors.Wrap(err, "render html body")
+		}
+		content.HTMLBody = buf.String()
+	}
+
+	return content, nil
+}
+
+// RenderSMS renders the SMS text of the notification about the session to
+// the user, with the same language fallback as Render.
+func (s *TemplateService) RenderSMS(
+	userID uuid.UUID, event domain.NotificationEvent, session domain.SessionDetails,
+) (string, error) {
+
+	templates, data, err := s.prepare(userID, session,
+		func(templates *languageTemplates) bool {
+			return templates.sms[event] != nil
+		})
+	if err != nil {
+		return "", err
+	}
+	if templates == nil {
+		return "", errors.Errorf("sms template %q not found", event)
+	}
+
+	var buf bytes.Buffer
+	if err := templates.sms[event].Execute(&buf, data); err != nil {
+		return "", errors.Wrap(err, "render sms")
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// prepare finds templates in the user language and builds their data.
+// Templates are nil if no language has the needed ones.
+func (s *TemplateService) prepare(
+	userID uuid.UUID, session domain.SessionDetails,
+	hasTemplate func(templates *languageTemplates) bool,
+) (*languageTemplates, TemplateData, error) {
+
+	locale, err := s.userRepository.GetUserLocale(userID)
+	if err != nil {
+		return nil, TemplateData{}, errors.Wrap(err, "get user locale")
+	}
+	language, templates := s.lookup(locale.Language, hasTemplate)
+	if templates == nil {
+		return nil, TemplateData{}, nil
+	}
+
+	data := TemplateData{
+		Time:      session.Time.In(s.timeZone(locale.TimeZone)),
+		IP:        session.IP,
+		UserAgent: session.UserAgent,
+		Code:      session.ChallengeCode,
+	}
+	if s.ipLocator != nil {
+		data.Location, err = s.ipLocator.Locate(session.IP, language)
+		if err != nil {
+			slogutils.Error("locate ip", err, "ip", session.IP)
+		}
+	}
+	// the link is signed into the notification when it is sent
+	if s.revokeSessionURL != nil && session.RevokeLink != nil {
+		data.RevokeURL = withToken(s.revokeSessionURL, domain.RevokeLinkTokenPlaceholder)
+	}
+	if s.magicLinkURL != nil && session.MagicLinkToken != "" {
+		data.LoginURL = withToken(s.magicLinkURL, session.MagicLinkToken)
+	}
+
+	return templates, data, nil
+}
+
+// withToken adds the token query parameter to the URL.
+func withToken(u *url.URL, token string) string {
+	tokenURL := *u
+	query := tokenURL.Query()
+	query.Set("token", token)
+	tokenURL.RawQuery = query.Encode()
+
+	return tokenURL.String()
+}
+
+func (s *TemplateService) lookup(
+	language string, hasTemplate func(templates *languageTemplates) bool,
+) (string, *languageTemplates) {
+
+	language = normalizeLanguage(language)
+	base, _, _ := strings.Cut(language, "-")
+	for _, candidate := range []string{language, base, s.defaultLanguage} {
+		templates := s.languages[candidate]
+		if templates != nil && hasTemplate(templates) {
+			return candidate, templates
+		}
+	}
+
+	return "", nil
+}
+
+func (s *TemplateService) timeZone(name string) *time.Location {
+	if name == "" {
+		return s.defaultTimeZone
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		slogutils.Error("load user time zone", err, "timeZone", name)
+		return s.defaultTimeZone
+	}
+
+	return location
+}
+
+// loadTemplates parses templates of all languages. Files of later file
+// systems override files with the same path of earlier ones.
+func loadTemplates(fileSystems []fs.FS) (map[string]*languageTemplates, error) {
+	files := make(map[string]fs.FS)
+	for _, fsys := range fileSystems {
+		for _, pattern := range []string{"*/*", "*/sms/*"} {
+			paths, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return nil, errors.Wrap(err, "list templates")
+			}
+			for _, p := range paths {
+				files[p] = fsys
+			}
+		}
+	}
+
+	languages := make(map[string]*languageTemplates)
+	for p, fsys := range files {
+		dir, file := path.Split(p)
+		ext := path.Ext(file)
+		if ext != ".txt" && ext != ".html" {
+			continue
+		}
+		dir, isSMS := strings.CutSuffix(dir, "sms/")
+		if isSMS && ext != ".txt" {
+			continue
+		}
+		language := normalizeLanguage(strings.TrimSuffix(dir, "/"))
+		event := domain.NotificationEvent(strings.TrimSuffix(file, ext))
+
+		source, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read template %s", p)
+		}
+		templates := languages[language]
+		if templates == nil {
+			templates = &languageTemplates{
+				text: make(map[domain.NotificationEvent]*texttemplate.Template),
+				html: make(map[domain.NotificationEvent]*htmltemplate.Template),
+				sms:  make(map[domain.NotificationEvent]*texttemplate.Template),
+			}
+			languages[language] = templates
+		}
+		switch {
+		case isSMS:
+			t, err := texttemplate.New(file).Parse(string(source))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse template %s", p)
+			}
+			templates.sms[event] = t
+		case ext == ".txt":
+			t, err := texttemplate.New(file).Parse(string(source))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse template %s", p)
+			}
+			if t.Lookup(subjectTemplate) == nil {
+				return nil, errors.Errorf("template %s doesn't define %q", p, subjectTemplate)
+			}
+			templates.text[event] = t
+		case ext == ".html":
+			t, err := htmltemplate.New(file).Parse(string(source))
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse template %s", p)
+			}
+			templates.html[event] = t
+		}
+	}
+
+	return languages, nil
+}
+
+// normalizeLanguage converts language tags like "en_US" to "en-us".
+func normalizeLanguage(language string) string {
+	return strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+}
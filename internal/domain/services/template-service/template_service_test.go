@@ -0,0 +1,204 @@
+package templateservice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/template-service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	templatesConfig = config.NotificationTemplatesConfig{
+		DefaultLanguage:  "ru",
+		DefaultTimeZone:  "UTC",
+		RevokeSessionURL: "https://example.com/sessions/revoke?from=email",
+		MagicLinkURL:     "https://example.com/login/magic-link",
+	}
+
+	userID         = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
+	sessionDetails = domain.SessionDetails{
+		SessionID:  uuid.MustParse("3e02eeb9-de9a-4e0a-857b-1293c25bd776"),
+		Time:       time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		IP:         "203.0.113.5",
+		UserAgent:  "Mozilla/5.0",
+		RevokeLink: &domain.RevokeLink{ID: uuid.MustParse("b6a3a8a4-4f5c-4d0e-9c55-2d4e8c1f7a10")},
+	}
+)
+
+func TestRender_UserLocale(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{
+		Language: "en-US",
+		TimeZone: "Europe/Berlin",
+	})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("Berlin, Germany", nil)
+
+	content, err := service.Render(userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Sign-in from a new IP address", content.Subject)
+	assert.Contains(t, content.TextBody, "Mar 1, 2024 1:30 PM CET")
+	assert.Contains(t, content.TextBody, "Location: Berlin, Germany")
+	assert.Contains(t, content.TextBody, "Device: Mozilla/5.0")
+	assert.Contains(t, content.TextBody,
+		"https://example.com/sessions/revoke?from=email&token=REVOKE_LINK_TOKEN")
+	assert.Contains(t, content.HTMLBody, `<html lang="en">`)
+	assert.Contains(t, content.HTMLBody, "from=email&amp;token=REVOKE_LINK_TOKEN")
+}
+
+func TestRender_DefaultLocale(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "fr"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "ru").Return("", nil)
+
+	content, err := service.Render(userID, domain.NotificationRefreshTokenLocked, sessionDetails)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Сессия завершена из-за подозрительной активности", content.Subject)
+	assert.Contains(t, content.TextBody, "01.03.2024 12:30 UTC")
+	assert.NotContains(t, content.TextBody, "Местоположение")
+}
+
+func TestRender_NoRevokeLink(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "en"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("", nil)
+	session := sessionDetails
+	session.RevokeLink = nil
+
+	content, err := service.Render(userID, domain.NotificationRefreshFromNewIP, session)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, content.TextBody, "https://example.com")
+	assert.NotContains(t, content.HTMLBody, "https://example.com")
+}
+
+func TestRender_ChallengeCode(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "en"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("", nil)
+	session := sessionDetails
+	session.ChallengeCode = "042137"
+
+	content, err := service.Render(userID, domain.NotificationRefreshChallenge, session)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Your sign-in code: 042137", content.Subject)
+	assert.Contains(t, content.TextBody, "Code: 042137")
+	assert.Contains(t, content.HTMLBody, "<b>042137</b>")
+	assert.NotContains(t, content.TextBody, "https://example.com")
+}
+
+func TestRender_MagicLink(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "en"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("", nil)
+	session := sessionDetails
+	session.RevokeLink = nil
+	session.MagicLinkToken = "link-token"
+
+	content, err := service.Render(userID, domain.NotificationMagicLink, session)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Your sign-in link", content.Subject)
+	assert.Contains(t, content.TextBody,
+		"https://example.com/login/magic-link?token=link-token")
+	assert.Contains(t, content.HTMLBody,
+		`href="https://example.com/login/magic-link?token=link-token"`)
+}
+
+func TestRender_SessionEvicted(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "en"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("", nil)
+
+	content, err := service.Render(userID, domain.NotificationSessionEvicted, sessionDetails)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Session ended by a sign-in on another device", content.Subject)
+	assert.Contains(t, content.TextBody,
+		"end the new session: https://example.com/sessions/revoke?from=email&token=REVOKE_LINK_TOKEN")
+	assert.Contains(t, content.HTMLBody, `<html lang="en">`)
+}
+
+func TestRenderSMS(t *testing.T) {
+	service := newService(t, templatesConfig, domain.UserLocale{Language: "en"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "en").Return("", nil)
+
+	text, err := service.RenderSMS(userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New sign-in from IP 203.0.113.5 at Mar 1 12:30 PM. "+
+		"If this wasn't you, end the session in your account settings.", text)
+}
+
+func TestRender_OverrideDir(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "de", "refresh_from_new_ip.txt"),
+		`{{define "subject"}}Neue Anmeldung{{end}}IP: {{.IP}}`)
+	mustWriteFile(t, filepath.Join(dir, "ru", "refresh_from_new_ip.txt"),
+		`{{define "subject"}}Новый вход{{end}}IP: {{.IP}}`)
+	cfg := templatesConfig
+	cfg.Dir = dir
+
+	service := newService(t, cfg, domain.UserLocale{Language: "de"})
+	ipLocator := service.ipLocator.(*mocks.IPLocator)
+	ipLocator.On("Locate", sessionDetails.IP, "de").Return("", nil)
+	content, err := service.Render(userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+	assert.NoError(t, err)
+	assert.Equal(t, "Neue Anmeldung", content.Subject)
+	assert.Equal(t, "IP: 203.0.113.5", content.TextBody)
+	assert.Empty(t, content.HTMLBody)
+
+	userRepository := service.userRepository.(*mocks.UserRepository)
+	userRepository.ExpectedCalls = nil
+	userRepository.On("GetUserLocale", userID).Return(domain.UserLocale{Language: "ru"}, nil)
+	ipLocator.On("Locate", sessionDetails.IP, "ru").Return("", nil)
+	content, err = service.Render(userID, domain.NotificationRefreshFromNewIP, sessionDetails)
+	assert.NoError(t, err)
+	assert.Equal(t, "Новый вход", content.Subject)
+	assert.Contains(t, content.HTMLBody, `<html lang="ru">`)
+}
+
+func TestNewTemplateService_NoSubject(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "de", "refresh_from_new_ip.txt"), `IP: {{.IP}}`)
+	cfg := templatesConfig
+	cfg.Dir = dir
+
+	_, err := NewTemplateService(
+		cfg, mocks.NewUserRepository(t), mocks.NewIPLocator(t))
+	assert.Error(t, err)
+}
+
+func newService(
+	t *testing.T, cfg config.NotificationTemplatesConfig, locale domain.UserLocale,
+) *TemplateService {
+
+	userRepository := mocks.NewUserRepository(t)
+	userRepository.On("GetUserLocale", userID).Return(locale, nil)
+
+	service, err := NewTemplateService(cfg, userRepository, mocks.NewIPLocator(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service
+}
+
+func mustWriteFile(t *testing.T, name, content string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
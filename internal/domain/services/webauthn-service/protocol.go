@@ -0,0 +1,270 @@
+package webauthnservice
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+)
+
+// COSE key parameters of the supported algorithms, RFC 9053.
+const (
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+// supportedAlgorithms are offered in registration options in the order of
+// preference.
+var supportedAlgorithms = []int{coseAlgES256, coseAlgEdDSA, coseAlgRS256}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type attestationObject struct {
+	Fmt      string          `cbor:"fmt"`
+	AttStmt  cbor.RawMessage `cbor:"attStmt"`
+	AuthData []byte          `cbor:"authData"`
+}
+
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// CredentialID and PublicKey are set in registrations only.
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// verifyClientData checks the ceremony type, the challenge and the origin
+// signed by the authenticator.
+func verifyClientData(
+	clientDataJSON []byte, ceremonyType string, challenge []byte, origins map[string]bool,
+) error {
+
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return errors.Wrap(err, "parse client data")
+	}
+	if data.Type != ceremonyType {
+		return fmt.Errorf("client data type is %q, not %q", data.Type, ceremonyType)
+	}
+	dataChallenge, err := base64.RawURLEncoding.DecodeString(data.Challenge)
+	if err != nil {
+		return errors.Wrap(err, "decode challenge")
+	}
+	if subtle.ConstantTimeCompare(dataChallenge, challenge) != 1 {
+		return errors.New("challenge doesn't match")
+	}
+	if !origins[data.Origin] {
+		return fmt.Errorf("origin %s is not allowed", data.Origin)
+	}
+
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	const headerSize = 32 + 1 + 4
+	if len(data) < headerSize {
+		return nil, errors.New("authenticator data is too short")
+	}
+	authData := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// AAGUID is not used, since attestation is not requested
+	rest := data[headerSize:]
+	if len(rest) < 16+2 {
+		return nil, errors.New("attested credential data is too short")
+	}
+	credentialIDLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < credentialIDLength {
+		return nil, errors.New("credential id is too short")
+	}
+	authData.CredentialID = rest[:credentialIDLength]
+	var publicKey cbor.RawMessage
+	_, err := cbor.UnmarshalFirst(rest[credentialIDLength:], &publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse credential public key")
+	}
+	authData.PublicKey = publicKey
+
+	return authData, nil
+}
+
+// verifyAuthenticatorData checks the relying party ID hash and the user
+// presence and verification flags.
+func verifyAuthenticatorData(
+	authData *authenticatorData, rpIDHash []byte, userVerificationRequired bool,
+) error {
+
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash) != 1 {
+		return errors.New("relying party id doesn't match")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return errors.New("user is not present")
+	}
+	if userVerificationRequired && authData.Flags&flagUserVerified == 0 {
+		return errors.New("user is not verified")
+	}
+
+	return nil
+}
+
+// parsePublicKey parses a COSE key of a supported algorithm.
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int, error) {
+	var params map[int]cbor.RawMessage
+	if err := cbor.Unmarshal(coseKey, &params); err != nil {
+		return nil, 0, errors.Wrap(err, "parse cose key")
+	}
+	var keyType, alg int
+	if err := unmarshalCOSEParam(params, 1, &keyType); err != nil {
+		return nil, 0, err
+	}
+	if err := unmarshalCOSEParam(params, 3, &alg); err != nil {
+		return nil, 0, err
+	}
+
+	switch {
+	case keyType == coseKeyTypeEC2 && alg == coseAlgES256:
+		var curve int
+		var x, y []byte
+		if err := unmarshalCOSEParam(params, -1, &curve); err != nil {
+			return nil, 0, err
+		}
+		if err := unmarshalCOSEParam(params, -2, &x); err != nil {
+			return nil, 0, err
+		}
+		if err := unmarshalCOSEParam(params, -3, &y); err != nil {
+			return nil, 0, err
+		}
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("ec2 key is not a p-256 key")
+		}
+		// ecdh checks the point is on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, errors.Wrap(err, "parse p-256 point")
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, alg, nil
+	case keyType == coseKeyTypeOKP && alg == coseAlgEdDSA:
+		var curve int
+		var x []byte
+		if err := unmarshalCOSEParam(params, -1, &curve); err != nil {
+			return nil, 0, err
+		}
+		if err := unmarshalCOSEParam(params, -2, &x); err != nil {
+			return nil, 0, err
+		}
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("okp key is not an ed25519 key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case keyType == coseKeyTypeRSA && alg == coseAlgRS256:
+		var n, e []byte
+		if err := unmarshalCOSEParam(params, -1, &n); err != nil {
+			return nil, 0, err
+		}
+		if err := unmarshalCOSEParam(params, -2, &e); err != nil {
+			return nil, 0, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) < 2048/8 || !exponent.IsInt64() || exponent.Int64() > 1<<31 {
+			return nil, 0, errors.New("rsa key is invalid")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exponent.Int64()),
+		}, alg, nil
+	default:
+		return nil, 0, fmt.Errorf("key type %d with algorithm %d is not supported", keyType, alg)
+	}
+}
+
+func unmarshalCOSEParam(params map[int]cbor.RawMessage, label int, value any) error {
+	raw, ok := params[label]
+	if !ok {
+		return fmt.Errorf("cose key parameter %d missing", label)
+	}
+	if err := cbor.Unmarshal(raw, value); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("parse cose key parameter %d", label))
+	}
+
+	return nil
+}
+
+// verifyAssertionSignature checks the signature over the authenticator data
+// and the client data hash.
+func verifyAssertionSignature(
+	coseKey, authenticatorData, clientDataJSON, signature []byte,
+) error {
+
+	publicKey, alg, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authenticatorData...), clientDataHash[:]...)
+
+	var valid bool
+	switch alg {
+	case coseAlgES256:
+		digest := sha256.Sum256(signed)
+		valid = ecdsa.VerifyASN1(publicKey.(*ecdsa.PublicKey), digest[:], signature)
+	case coseAlgEdDSA:
+		valid = ed25519.Verify(publicKey.(ed25519.PublicKey), signed, signature)
+	case coseAlgRS256:
+		digest := sha256.Sum256(signed)
+		valid = rsa.VerifyPKCS1v15(
+			publicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	}
+	if !valid {
+		return errors.New("signature is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package webauthnservice
+
+import (
+	"auth/internal/config"
+	"auth/internal/domain"
+	slogutils "auth/internal/utils/slog-utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"log/slog"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	challengeSize                 = 32
+	deleteExpiredCeremoniesPeriod = time.Minute * 5
+
+	userVerificationRequired = "required"
+	attestationFormatNone    = "none"
+)
+
+// WebAuthnService registers passkeys of users and logs users in with them.
+// Attestation is not requested, so any authenticator can be registered.
+type WebAuthnService struct {
+	cfg                          config.WebAuthnConfig
+	rpIDHash                     []byte
+	origins                      map[string]bool
+	webAuthnCredentialRepository WebAuthnCredentialRepository
+	webAuthnCeremonyRepository   WebAuthnCeremonyRepository
+	userRepository               UserRepository
+	authService                  AuthService
+}
+
+//go:generate mockery --name WebAuthnCredentialRepository --filename webauthn_credential_repository.go
+type WebAuthnCredentialRepository interface {
+	Create(credential *domain.WebAuthnCredential) error
+	// GetByID returns nil if the credential is not found.
+	GetByID(id []byte) (*domain.WebAuthnCredential, error)
+	GetAllByUserID(userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	// UpdateSignCount returns false if the stored counter is already not
+	// less than signCount, unless both are 0.
+	UpdateSignCount(id []byte, signCount uint32, lastUsedTime time.Time) (bool, error)
+	// Delete returns false if the user has no such credential.
+	Delete(userID uuid.UUID, id []byte) (bool, error)
+}
+
+//go:generate mockery --name WebAuthnCeremonyRepository --filename webauthn_ceremony_repository.go
+type WebAuthnCeremonyRepository interface {
+	Create(ceremony *domain.WebAuthnCeremony) error
+	// Take deletes and returns the ceremony, nil if it is not found, so a
+	// challenge can be answered only once.
+	Take(id uuid.UUID) (*domain.WebAuthnCeremony, error)
+	DeleteAllExpired() error
+}
+
+//go:generate mockery --name UserRepository --filename user_repository.go
+type UserRepository interface {
+	GetUserEmail(userID uuid.UUID) (string, error)
+}
+
+//go:generate mockery --name AuthService --filename auth_service.go
+type AuthService interface {
+	CreateSession(
+		userID uuid.UUID, clientID string, requestIP string, options domain.SessionOptions,
+	) (*domain.Session, error)
+}
+
+func NewWebAuthnService(
+	cfg config.WebAuthnConfig,
+	webAuthnCredentialRepository WebAuthnCredentialRepository,
+	webAuthnCeremonyRepository WebAuthnCeremonyRepository,
+	userRepository UserRepository,
+	authService AuthService,
+) (*WebAuthnService, error) {
+
+	if cfg.RPID == "" {
+		return nil, errors.New("relying party id is empty")
+	}
+	if len(cfg.Origins) == 0 {
+		return nil, errors.New("origins are empty")
+	}
+
+	go func() {
+		timer := time.NewTicker(deleteExpiredCeremoniesPeriod)
+		for {
+			<-timer.C
+			err := webAuthnCeremonyRepository.DeleteAllExpired()
+			if err != nil {
+				slogutils.Error("delete all expired webauthn ceremonies", err)
+			}
+		}
+	}()
+
+	rpIDHash := sha256.Sum256([]byte(cfg.RPID))
+	origins := make(map[string]bool)
+	for _, origin := range cfg.Origins {
+		origins[origin] = true
+	}
+
+	return &WebAuthnService{
+		cfg:                          cfg,
+		rpIDHash:                     rpIDHash[:],
+		origins:                      origins,
+		webAuthnCredentialRepository: webAuthnCredentialRepository,
+		webAuthnCeremonyRepository:   webAuthnCeremonyRepository,
+		userRepository:               userRepository,
+		authService:                  authService,
+	}, nil
+}
+
+// BeginRegistration starts registration of a new credential of the user.
+// Already registered credentials are excluded, so an authenticator is not
+// registered twice.
+func (s *WebAuthnService) BeginRegistration(
+	userID uuid.UUID,
+) (*domain.WebAuthnRegistrationOptions, error) {
+
+	email, err := s.userRepository.GetUserEmail(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user email")
+	}
+	credentials, err := s.webAuthnCredentialRepository.GetAllByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user credentials")
+	}
+	ceremony, err := s.newCeremony(domain.WebAuthnCeremonyRegistration, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeCredentialIDs := make([][]byte, 0, len(credentials))
+	for _, credential := range credentials {
+		excludeCredentialIDs = append(excludeCredentialIDs, credential.ID)
+	}
+	userHandle, _ := userID.MarshalBinary()
+
+	return &domain.WebAuthnRegistrationOptions{
+		CeremonyID:           ceremony.ID,
+		Challenge:            ceremony.Challenge,
+		RPID:                 s.cfg.RPID,
+		RPName:               s.cfg.RPName,
+		UserHandle:           userHandle,
+		UserName:             email,
+		ExcludeCredentialIDs: excludeCredentialIDs,
+		Algorithms:           supportedAlgorithms,
+		UserVerification:     s.cfg.UserVerification,
+		Timeout:              s.cfg.CeremonyTimeout,
+	}, nil
+}
+
+// FinishRegistration verifies the authenticator response and stores the
+// credential. Verification errors are *domain.ValidationError.
+func (s *WebAuthnService) FinishRegistration(
+	userID, ceremonyID uuid.UUID, attestation domain.WebAuthnAttestation, name string,
+) (*domain.WebAuthnCredential, error) {
+
+	ceremony, err := s.takeCeremony(ceremonyID, domain.WebAuthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony == nil || ceremony.UserID != userID {
+		return nil, &domain.ValidationError{Message: "ceremony not found"}
+	}
+
+	err = verifyClientData(
+		attestation.ClientDataJSON, clientDataTypeCreate, ceremony.Challenge, s.origins)
+	if err != nil {
+		return nil, &domain.ValidationError{Message: err.Error()}
+	}
+	var attObject attestationObject
+	if err := cbor.Unmarshal(attestation.AttestationObject, &attObject); err != nil {
+		return nil, &domain.ValidationError{Message: "parse attestation object: " + err.Error()}
+	}
+	if attObject.Fmt != attestationFormatNone {
+		return nil, &domain.ValidationError{
+			Message: "attestation format " + attObject.Fmt + " is not supported"}
+	}
+	authData, err := parseAuthenticatorData(attObject.AuthData)
+	if err != nil {
+		return nil, &domain.ValidationError{Message: err.Error()}
+	}
+	err = verifyAuthenticatorData(authData, s.rpIDHash, s.userVerificationRequired())
+	if err != nil {
+		return nil, &domain.ValidationError{Message: err.Error()}
+	}
+	if authData.CredentialID == nil {
+		return nil, &domain.ValidationError{Message: "attested credential data missing"}
+	}
+	if string(authData.CredentialID) != string(attestation.CredentialID) {
+		return nil, &domain.ValidationError{Message: "credential id doesn't match"}
+	}
+	if _, _, err := parsePublicKey(authData.PublicKey); err != nil {
+		return nil, &domain.ValidationError{Message: err.Error()}
+	}
+
+	existing, err := s.webAuthnCredentialRepository.GetByID(authData.CredentialID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get credential")
+	}
+	if existing != nil {
+		return nil, &domain.ValidationError{Message: "credential is already registered"}
+	}
+	credential := &domain.WebAuthnCredential{
+		ID:           authData.CredentialID,
+		UserID:       userID,
+		PublicKey:    authData.PublicKey,
+		SignCount:    authData.SignCount,
+		Transports:   attestation.Transports,
+		Name:         name,
+		CreationTime: time.Now(),
+	}
+	err = s.webAuthnCredentialRepository.Create(credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "create credential")
+	}
+
+	return credential, nil
+}
+
+// BeginLogin starts a login with a discoverable credential.
+func (s *WebAuthnService) BeginLogin() (*domain.WebAuthnLoginOptions, error) {
+	ceremony, err := s.newCeremony(domain.WebAuthnCeremonyLogin, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.WebAuthnLoginOptions{
+		CeremonyID:       ceremony.ID,
+		Challenge:        ceremony.Challenge,
+		RPID:             s.cfg.RPID,
+		UserVerification: s.cfg.UserVerification,
+		Timeout:          s.cfg.CeremonyTimeout,
+	}, nil
+}
+
+// FinishLogin verifies the assertion and creates a session of the owner of
+// the credential. Verification errors are *domain.UnauthorizedError. A sign
+// counter which didn't increase means the authenticator could be cloned, the
+// login is rejected.
+func (s *WebAuthnService) FinishLogin(
+	ceremonyID uuid.UUID, assertion domain.WebAuthnAssertion,
+	clientID, requestIP string,
+) (*domain.Session, error) {
+
+	ceremony, err := s.takeCeremony(ceremonyID, domain.WebAuthnCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony == nil {
+		return nil, &domain.UnauthorizedError{Message: "ceremony not found"}
+	}
+
+	credential, err := s.webAuthnCredentialRepository.GetByID(assertion.CredentialID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get credential")
+	}
+	if credential == nil {
+		return nil, &domain.UnauthorizedError{Message: "credential not found"}
+	}
+	if len(assertion.UserHandle) > 0 {
+		userHandle, _ := credential.UserID.MarshalBinary()
+		if string(assertion.UserHandle) != string(userHandle) {
+			return nil, &domain.UnauthorizedError{Message: "user handle doesn't match"}
+		}
+	}
+
+	err = verifyClientData(
+		assertion.ClientDataJSON, clientDataTypeGet, ceremony.Challenge, s.origins)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{Message: err.Error()}
+	}
+	authData, err := parseAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{Message: err.Error()}
+	}
+	err = verifyAuthenticatorData(authData, s.rpIDHash, s.userVerificationRequired())
+	if err != nil {
+		return nil, &domain.UnauthorizedError{Message: err.Error()}
+	}
+	err = verifyAssertionSignature(
+		credential.PublicKey, assertion.AuthenticatorData,
+		assertion.ClientDataJSON, assertion.Signature)
+	if err != nil {
+		return nil, &domain.UnauthorizedError{Message: err.Error()}
+	}
+
+	if (authData.SignCount != 0 || credential.SignCount != 0) &&
+		authData.SignCount <= credential.SignCount {
+		slog.Warn("webauthn sign counter didn't increase",
+			"userID", credential.UserID, "signCount", authData.SignCount,
+			"storedSignCount", credential.SignCount)
+		return nil, &domain.UnauthorizedError{Message: "sign counter didn't increase"}
+	}
+	updated, err := s.webAuthnCredentialRepository.
+		UpdateSignCount(credential.ID, authData.SignCount, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "update sign count")
+	}
+	if !updated {
+		// a concurrent login used the same or a later counter
+		return nil, &domain.UnauthorizedError{Message: "sign counter didn't increase"}
+	}
+
+	amr := []string{domain.AMRHardwareKey}
+	if authData.Flags&flagUserVerified != 0 {
+		// the authenticator verified the user with a PIN or biometrics,
+		// which is a second factor next to possession of the key
+		amr = append(amr, domain.AMRMFA)
+	}
+	session, err := s.authService.CreateSession(
+		credential.UserID, clientID, requestIP, domain.SessionOptions{AMR: amr})
+	if err != nil {
+		return nil, errors.Wrap(err, "create session")
+	}
+
+	return session, nil
+}
+
+func (s *WebAuthnService) ListCredentials(userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	credentials, err := s.webAuthnCredentialRepository.GetAllByUserID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user credentials")
+	}
+
+	return credentials, nil
+}
+
+func (s *WebAuthnService) DeleteCredential(userID uuid.UUID, credentialID []byte) error {
+	deleted, err := s.webAuthnCredentialRepository.Delete(userID, credentialID)
+	if err != nil {
+		return errors.Wrap(err, "delete credential")
+	}
+	if !deleted {
+		return &domain.NotFoundError{Message: "credential not found"}
+	}
+
+	return nil
+}
+
+func (s *WebAuthnService) newCeremony(
+	kind domain.WebAuthnCeremonyKind, userID uuid.UUID,
+) (*domain.WebAuthnCeremony, error) {
+
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, errors.Wrap(err, "generate challenge")
+	}
+	ceremony := &domain.WebAuthnCeremony{
+		ID:             uuid.New(),
+		Kind:           kind,
+		UserID:         userID,
+		Challenge:      challenge,
+		ExpirationTime: time.Now().Add(s.cfg.CeremonyTimeout),
+	}
+	err := s.webAuthnCeremonyRepository.Create(ceremony)
+	if err != nil {
+		return nil, errors.Wrap(err, "create ceremony")
+	}
+
+	return ceremony, nil
+}
+
+// takeCeremony returns nil if the ceremony is not found, expired or of
+// another kind.
+func (s *WebAuthnService) takeCeremony(
+	id uuid.UUID, kind domain.WebAuthnCeremonyKind,
+) (*domain.WebAuthnCeremony, error) {
+
+	ceremony, err := s.webAuthnCeremonyRepository.Take(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "take ceremony")
+	}
+	if ceremony == nil || ceremony.Kind != kind || time.Now().After(ceremony.ExpirationTime) {
+		return nil, nil
+	}
+
+	return ceremony, nil
+}
+
+func (s *WebAuthnService) userVerificationRequired() bool {
+	return s.cfg.UserVerification == userVerificationRequired
+}
@@ -0,0 +1,391 @@
+package webauthnservice
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"auth/internal/config"
+	"auth/internal/domain"
+	"auth/internal/domain/services/webauthn-service/mocks"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	webAuthnConfig = config.WebAuthnConfig{
+		RPID:             "company.com",
+		RPName:           "Company",
+		Origins:          []string{"https://app.company.com"},
+		UserVerification: "required",
+		CeremonyTimeout:  time.Minute * 5,
+	}
+
+	origin    = "https://app.company.com"
+	userID    = uuid.MustParse("8798e65e-dc84-4a7d-879e-2a52e67d86da")
+	userEmail = "user@example.com"
+	clientID  = "web"
+	userIP    = "127.0.0.1"
+)
+
+func TestRegistrationAndLogin(t *testing.T) {
+	for _, alg := range []int{coseAlgES256, coseAlgEdDSA} {
+		service := newService(t)
+		authenticator := newSoftAuthenticator(t, alg)
+		credential := register(t, service, authenticator)
+
+		assert.Equal(t, authenticator.credentialID, credential.ID)
+		assert.Equal(t, userID, credential.UserID)
+		assert.Equal(t, "Laptop", credential.Name)
+
+		ceremony := beginLogin(t, service)
+		assertion := authenticator.get(t, ceremony.Challenge, origin, flagUserPresent|flagUserVerified)
+		credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+		credentialRepository.
+			On("GetByID", authenticator.credentialID).
+			Return(credential, nil)
+		credentialRepository.
+			On("UpdateSignCount", authenticator.credentialID, authenticator.signCount,
+				mock.AnythingOfType("time.Time")).
+			Return(true, nil)
+		authService := service.authService.(*mocks.AuthService)
+		expectedSession := &domain.Session{AccessTokenSigned: []byte("access-token")}
+		authService.
+			On("CreateSession", userID, clientID, userIP,
+				domain.SessionOptions{
+					AMR: []string{domain.AMRHardwareKey, domain.AMRMFA}}).
+			Return(expectedSession, nil)
+
+		session, err := service.FinishLogin(ceremony.ID, assertion, clientID, userIP)
+
+		require.NoError(t, err, "algorithm %d", alg)
+		assert.Equal(t, expectedSession, session)
+	}
+}
+
+func TestFinishRegistration_WrongOrigin(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	ceremony := beginRegistration(t, service)
+
+	attestation := authenticator.create(
+		t, ceremony.Challenge, "https://evil.com", flagUserPresent|flagUserVerified)
+	var validationError *domain.ValidationError
+	_, err := service.FinishRegistration(userID, ceremony.ID, attestation, "Laptop")
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestFinishRegistration_UserNotVerified(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	ceremony := beginRegistration(t, service)
+
+	attestation := authenticator.create(t, ceremony.Challenge, origin, flagUserPresent)
+	var validationError *domain.ValidationError
+	_, err := service.FinishRegistration(userID, ceremony.ID, attestation, "Laptop")
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestFinishRegistration_OtherUsersCeremony(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	ceremony := beginRegistration(t, service)
+
+	attestation := authenticator.create(t, ceremony.Challenge, origin, flagUserPresent|flagUserVerified)
+	var validationError *domain.ValidationError
+	_, err := service.FinishRegistration(uuid.New(), ceremony.ID, attestation, "Laptop")
+	assert.ErrorAs(t, err, &validationError)
+}
+
+func TestFinishLogin_WrongChallenge(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	credential := register(t, service, authenticator)
+	ceremony := beginLogin(t, service)
+
+	assertion := authenticator.get(t, []byte("other-challenge"), origin, flagUserPresent|flagUserVerified)
+	credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+	credentialRepository.
+		On("GetByID", authenticator.credentialID).
+		Return(credential, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.FinishLogin(ceremony.ID, assertion, clientID, userIP)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestFinishLogin_InvalidSignature(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	credential := register(t, service, authenticator)
+	ceremony := beginLogin(t, service)
+
+	assertion := authenticator.get(t, ceremony.Challenge, origin, flagUserPresent|flagUserVerified)
+	assertion.AuthenticatorData[33] ^= 0xff // tamper the sign counter
+	credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+	credentialRepository.
+		On("GetByID", authenticator.credentialID).
+		Return(credential, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.FinishLogin(ceremony.ID, assertion, clientID, userIP)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestFinishLogin_ClonedAuthenticator(t *testing.T) {
+	service := newService(t)
+	authenticator := newSoftAuthenticator(t, coseAlgES256)
+	credential := register(t, service, authenticator)
+	credential.SignCount = 10
+	ceremony := beginLogin(t, service)
+
+	assertion := authenticator.get(t, ceremony.Challenge, origin, flagUserPresent|flagUserVerified)
+	credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+	credentialRepository.
+		On("GetByID", authenticator.credentialID).
+		Return(credential, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.FinishLogin(ceremony.ID, assertion, clientID, userIP)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+func TestFinishLogin_CeremonyNotFound(t *testing.T) {
+	service := newService(t)
+	ceremonyRepository := service.webAuthnCeremonyRepository.(*mocks.WebAuthnCeremonyRepository)
+	ceremonyID := uuid.New()
+	ceremonyRepository.
+		On("Take", ceremonyID).
+		Return(nil, nil)
+
+	var unauthorizedError *domain.UnauthorizedError
+	_, err := service.FinishLogin(ceremonyID, domain.WebAuthnAssertion{}, clientID, userIP)
+	assert.ErrorAs(t, err, &unauthorizedError)
+}
+
+// register runs the registration ceremony with the authenticator.
+func register(
+	t *testing.T, service *WebAuthnService, authenticator *softAuthenticator,
+) *domain.WebAuthnCredential {
+
+	ceremony := beginRegistration(t, service)
+	attestation := authenticator.create(
+		t, ceremony.Challenge, origin, flagUserPresent|flagUserVerified)
+	credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+	credentialRepository.
+		On("GetByID", authenticator.credentialID).
+		Return(nil, nil).
+		Once()
+	credentialRepository.
+		On("Create", mock.AnythingOfType("*domain.WebAuthnCredential")).
+		Return(nil).
+		Once()
+
+	credential, err := service.FinishRegistration(userID, ceremony.ID, attestation, "Laptop")
+	require.NoError(t, err)
+
+	return credential
+}
+
+// beginRegistration starts the registration and makes the ceremony
+// repository return the ceremony once.
+func beginRegistration(t *testing.T, service *WebAuthnService) *domain.WebAuthnCeremony {
+	userRepository := service.userRepository.(*mocks.UserRepository)
+	userRepository.
+		On("GetUserEmail", userID).
+		Return(userEmail, nil).
+		Once()
+	credentialRepository := service.webAuthnCredentialRepository.(*mocks.WebAuthnCredentialRepository)
+	credentialRepository.
+		On("GetAllByUserID", userID).
+		Return(nil, nil).
+		Once()
+	ceremony := expectCeremony(service)
+
+	options, err := service.BeginRegistration(userID)
+	require.NoError(t, err)
+	assert.Equal(t, webAuthnConfig.RPID, options.RPID)
+	assert.Equal(t, userEmail, options.UserName)
+	assert.Len(t, options.Challenge, challengeSize)
+
+	return ceremony
+}
+
+func beginLogin(t *testing.T, service *WebAuthnService) *domain.WebAuthnCeremony {
+	ceremony := expectCeremony(service)
+
+	options, err := service.BeginLogin()
+	require.NoError(t, err)
+	assert.Equal(t, ceremony.ID, options.CeremonyID)
+
+	return ceremony
+}
+
+// expectCeremony captures the created ceremony and returns it from Take.
+func expectCeremony(service *WebAuthnService) *domain.WebAuthnCeremony {
+	ceremonyRepository := service.webAuthnCeremonyRepository.(*mocks.WebAuthnCeremonyRepository)
+	ceremony := &domain.WebAuthnCeremony{}
+	ceremonyRepository.
+		On("Create", mock.AnythingOfType("*domain.WebAuthnCeremony")).
+		Run(func(args mock.Arguments) {
+			*ceremony = *args.Get(0).(*domain.WebAuthnCeremony)
+			ceremonyRepository.
+				On("Take", ceremony.ID).
+				Return(ceremony, nil).
+				Once()
+		}).
+		Return(nil).
+		Once()
+
+	return ceremony
+}
+
+func newService(t *testing.T) *WebAuthnService {
+	service, err := NewWebAuthnService(
+		webAuthnConfig,
+		mocks.NewWebAuthnCredentialRepository(t),
+		mocks.NewWebAuthnCeremonyRepository(t),
+		mocks.NewUserRepository(t),
+		mocks.NewAuthService(t),
+	)
+	require.NoError(t, err)
+
+	return service
+}
+
+// softAuthenticator is a platform authenticator implemented in software,
+// it answers ceremonies like navigator.credentials would.
+type softAuthenticator struct {
+	alg          int
+	privateKey   crypto.Signer
+	credentialID []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T, alg int) *softAuthenticator {
+	var privateKey crypto.Signer
+	var err error
+	switch alg {
+	case coseAlgES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case coseAlgEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softAuthenticator{alg: alg, privateKey: privateKey, credentialID: credentialID}
+}
+
+func (a *softAuthenticator) create(
+	t *testing.T, challenge []byte, origin string, flags byte,
+) domain.WebAuthnAttestation {
+
+	clientDataJSON := newClientDataJSON(t, clientDataTypeCreate, challenge, origin)
+
+	attestedCredentialData := make([]byte, 16, 16+2+len(a.credentialID))
+	attestedCredentialData = binary.BigEndian.AppendUint16(
+		attestedCredentialData, uint16(len(a.credentialID)))
+	attestedCredentialData = append(attestedCredentialData, a.credentialID...)
+	attestedCredentialData = append(attestedCredentialData, a.coseKey(t)...)
+	authData := append(a.authenticatorData(flags|flagAttestedCredentialData),
+		attestedCredentialData...)
+
+	attObject, err := cbor.Marshal(map[string]any{
+		"fmt":      attestationFormatNone,
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	return domain.WebAuthnAttestation{
+		CredentialID:      a.credentialID,
+		ClientDataJSON:    clientDataJSON,
+		AttestationObject: attObject,
+		Transports:        []string{"internal"},
+	}
+}
+
+func (a *softAuthenticator) get(
+	t *testing.T, challenge []byte, origin string, flags byte,
+) domain.WebAuthnAssertion {
+
+	a.signCount++
+	clientDataJSON := newClientDataJSON(t, clientDataTypeGet, challenge, origin)
+	authData := a.authenticatorData(flags)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+
+	var signature []byte
+	var err error
+	switch a.alg {
+	case coseAlgES256:
+		digest := sha256.Sum256(signed)
+		signature, err = a.privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case coseAlgEdDSA:
+		signature, err = a.privateKey.Sign(rand.Reader, signed, crypto.Hash(0))
+	}
+	require.NoError(t, err)
+	userHandle, _ := userID.MarshalBinary()
+
+	return domain.WebAuthnAssertion{
+		CredentialID:      a.credentialID,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authData,
+		Signature:         signature,
+		UserHandle:        userHandle,
+	}
+}
+
+func (a *softAuthenticator) authenticatorData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(webAuthnConfig.RPID))
+	authData := append(rpIDHash[:], flags)
+
+	return binary.BigEndian.AppendUint32(authData, a.signCount)
+}
+
+func (a *softAuthenticator) coseKey(t *testing.T) []byte {
+	var key map[int]any
+	switch publicKey := a.privateKey.Public().(type) {
+	case *ecdsa.PublicKey:
+		key = map[int]any{
+			1: coseKeyTypeEC2, 3: coseAlgES256, -1: coseCurveP256,
+			-2: publicKey.X.FillBytes(make([]byte, 32)),
+			-3: publicKey.Y.FillBytes(make([]byte, 32)),
+		}
+	case ed25519.PublicKey:
+		key = map[int]any{
+			1: coseKeyTypeOKP, 3: coseAlgEdDSA, -1: coseCurveEd25519,
+			-2: []byte(publicKey),
+		}
+	}
+	encoded, err := cbor.Marshal(key)
+	require.NoError(t, err)
+
+	return encoded
+}
+
+func newClientDataJSON(t *testing.T, ceremonyType string, challenge []byte, origin string) []byte {
+	clientDataJSON, err := json.Marshal(clientData{
+		Type:      ceremonyType,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    origin,
+	})
+	require.NoError(t, err)
+
+	return clientDataJSON
+}
@@ -1,6 +1,23 @@
 package domain
 
+import "github.com/google/uuid"
+
 type Session struct {
 	AccessTokenSigned []byte
 	RefreshTokenValue []byte
 }
+
+// SessionOptions describe how a new session is created.
+type SessionOptions struct {
+	// AMR lists the methods the user has just authenticated with, it is
+	// empty if they are unknown.
+	AMR []string
+	// LifetimeProfile selects durations of the session tokens. If it is
+	// empty, RememberMe selects the remember me profile and otherwise the
+	// default profile of the client is used.
+	LifetimeProfile string
+	RememberMe      bool
+	// StepUpTicket is returned by the step-up of the user verified for this
+	// login, the session is then stepped up. It is uuid.Nil if there is none.
+	StepUpTicket uuid.UUID
+}
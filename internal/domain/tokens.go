@@ -7,14 +7,43 @@ import (
 )
 
 type AccessToken struct {
+	ID             uuid.UUID
 	UserID         uuid.UUID
 	RefreshTokenID uuid.UUID
-	UserIP         string
-	ExpTime        time.Time
+	// SessionID stays the same through refreshes, unlike RefreshTokenID.
+	SessionID uuid.UUID
+	UserIP    string
+	IssuedAt  time.Time
+	ExpTime   time.Time
+	// AuthTime is when the user last authenticated, zero if it is unknown.
+	AuthTime time.Time
+	// ACR is empty unless the session was stepped up. AMR lists the methods
+	// the user authenticated with, if they are known.
+	ACR string
+	AMR []string
 }
 
 type RefreshToken struct {
-	ID             uuid.UUID
+	ID uuid.UUID
+	// UserID is uuid.Nil for tokens issued before it was stored.
+	UserID         uuid.UUID
+	ClientID       string
 	ValueHash      []byte
+	CreationTime   time.Time
 	ExpirationTime time.Time
+	// SessionID identifies the session, it is carried into rotated tokens
+	// while ID changes on every refresh.
+	SessionID uuid.UUID
+	// SessionStartTime is when the session was created and LifetimeProfile
+	// selects durations of its tokens, they are carried into rotated tokens.
+	// The empty profile is the default one.
+	SessionStartTime time.Time
+	LifetimeProfile  string
+	// AuthTime, ACR and AMR are carried into access tokens issued with the
+	// token and into rotated tokens. AuthTime is when the user logged in or
+	// last stepped up the session, it is zero for tokens issued before it was
+	// stored.
+	AuthTime time.Time
+	ACR      string
+	AMR      []string
 }
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential is a passkey or a security key of the user.
+type WebAuthnCredential struct {
+	ID     []byte
+	UserID uuid.UUID
+	// PublicKey is the COSE encoded public key of the credential.
+	PublicKey []byte
+	// SignCount is the signature counter of the authenticator. Authenticators
+	// which don't implement it always return 0.
+	SignCount    uint32
+	Transports   []string
+	Name         string
+	CreationTime time.Time
+	// LastUsedTime is zero if the credential was never used to log in.
+	LastUsedTime time.Time
+}
+
+type WebAuthnCeremonyKind string
+
+const (
+	WebAuthnCeremonyRegistration WebAuthnCeremonyKind = "registration"
+	WebAuthnCeremonyLogin        WebAuthnCeremonyKind = "login"
+)
+
+// WebAuthnCeremony is a started registration or login waiting for the
+// authenticator response signed over the challenge.
+type WebAuthnCeremony struct {
+	ID   uuid.UUID
+	Kind WebAuthnCeremonyKind
+	// UserID is uuid.Nil for logins, the user is found by the credential.
+	UserID         uuid.UUID
+	Challenge      []byte
+	ExpirationTime time.Time
+}
+
+// WebAuthnRegistrationOptions are passed to navigator.credentials.create.
+type WebAuthnRegistrationOptions struct {
+	CeremonyID           uuid.UUID
+	Challenge            []byte
+	RPID                 string
+	RPName               string
+	UserHandle           []byte
+	UserName             string
+	ExcludeCredentialIDs [][]byte
+	// Algorithms are COSE algorithm identifiers in the order of preference.
+	Algorithms       []int
+	UserVerification string
+	Timeout          time.Duration
+}
+
+// WebAuthnLoginOptions are passed to navigator.credentials.get. No
+// credentials are listed, so the authenticator offers discoverable ones.
+type WebAuthnLoginOptions struct {
+	CeremonyID       uuid.UUID
+	Challenge        []byte
+	RPID             string
+	UserVerification string
+	Timeout          time.Duration
+}
+
+// WebAuthnAttestation is the authenticator response of a registration.
+type WebAuthnAttestation struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AttestationObject []byte
+	Transports        []string
+}
+
+// WebAuthnAssertion is the authenticator response of a login.
+type WebAuthnAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	// UserHandle is empty if the authenticator doesn't return it.
+	UserHandle []byte
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type AuditEventRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewAuditEventRepository(db *sqlx.DB) *AuditEventRepository {
+	return &AuditEventRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *AuditEventRepository) Create(event *domain.AuditEvent) error {
+	query, args, err := s.builder.
+		Insert("audit_events").
+		Columns(`type, user_id, refresh_token_id, session_id, client_id, ip, created_at`).
+		Values(
+			event.Type, event.UserID, event.RefreshTokenID, event.SessionID,
+			event.ClientID, event.IP, event.Time).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ authservice.AuditEventRepository = &AuditEventRepository{}
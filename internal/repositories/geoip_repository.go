@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	templateservice "auth/internal/domain/services/template-service"
+	"net"
+	"strings"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+)
+
+// GeoIPRepository locates IP addresses with a MaxMind GeoIP2 or GeoLite2
+// City database.
+type GeoIPRepository struct {
+	reader *geoip2.Reader
+}
+
+func NewGeoIPRepository(databasePath string) (*GeoIPRepository, error) {
+	reader, err := geoip2.Open(databasePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "open geoip database")
+	}
+
+	return &GeoIPRepository{reader: reader}, nil
+}
+
+// Locate returns "city, country" with names in the language if the database
+// has them and in English otherwise.
+func (s *GeoIPRepository) Locate(ip, language string) (string, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return "", errors.Errorf("invalid ip address %q", ip)
+	}
+	city, err := s.reader.City(parsedIP)
+	if err != nil {
+		return "", errors.Wrap(err, "lookup ip")
+	}
+
+	var parts []string
+	if name := localizedName(city.City.Names, language); name != "" {
+		parts = append(parts, name)
+	}
+	if name := localizedName(city.Country.Names, language); name != "" {
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, ", "), nil
+}
+
+// localizedName falls back from "pt-br" to "pt-BR", "pt" and "en", as the
+// database uses tags like "pt-BR" and "zh-CN".
+func localizedName(names map[string]string, language string) string {
+	base, region, found := strings.Cut(language, "-")
+	candidates := []string{language, base, "en"}
+	if found {
+		candidates = append([]string{base + "-" + strings.ToUpper(region)}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if name := names[candidate]; name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+var _ templateservice.IPLocator = &GeoIPRepository{}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	notificationservice "auth/internal/domain/services/notification-service"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type InAppNotificationRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewInAppNotificationRepository(db *sqlx.DB) *InAppNotificationRepository {
+	return &InAppNotificationRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *InAppNotificationRepository) Create(notification *domain.InAppNotification) error {
+	query, args, err := s.builder.
+		Insert("in_app_notifications").
+		Columns("user_id, title, body, created_at").
+		Values(
+			notification.UserID, notification.Title,
+			notification.Body, notification.CreationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+// GetByUserID returns notifications of the user, most recent first.
+func (s *InAppNotificationRepository) GetByUserID(
+	userID uuid.UUID, limit, offset int,
+) ([]domain.InAppNotification, error) {
+
+	query, args, err := s.builder.
+		Select("id, user_id, title, body, created_at, read_at").
+		From("in_app_notifications").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var notifications []domain.InAppNotification
+	for rows.Next() {
+		var notification domain.InAppNotification
+		var readTime sql.NullTime
+		err := rows.Scan(
+			&notification.ID, &notification.UserID,
+			&notification.Title, &notification.Body,
+			&notification.CreationTime, &readTime,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		notification.ReadTime = readTime.Time
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks the notification of the user as read. It returns false if
+// the user has no notification with the ID.
+func (s *InAppNotificationRepository) MarkRead(userID, id uuid.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Update("in_app_notifications").
+		Set("read_at", sq.Expr("COALESCE(read_at, ?)", time.Now())).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get affected rows")
+	}
+
+	return affected > 0, nil
+}
+
+var _ notificationservice.InAppNotificationRepository = &InAppNotificationRepository{}
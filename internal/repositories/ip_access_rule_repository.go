@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	ipaccessservice "auth/internal/domain/services/ip-access-service"
+	iputils "auth/internal/utils/ip-utils"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type IPAccessRuleRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewIPAccessRuleRepository(db *sqlx.DB) *IPAccessRuleRepository {
+	return &IPAccessRuleRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *IPAccessRuleRepository) GetAll() ([]domain.IPAccessRule, error) {
+	query, args, err := s.builder.
+		Select("client_id, cidr, action").
+		From("ip_access_rules").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var rules []domain.IPAccessRule
+	for rows.Next() {
+		var rule domain.IPAccessRule
+		var cidr string
+		err = rows.Scan(&rule.ClientID, &cidr, &rule.Action)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		// single addresses and IPv4-mapped prefixes are accepted like in the
+		// config rules
+		prefixes, err := iputils.ParsePrefixes([]string{cidr})
+		if err != nil {
+			return nil, errors.Wrap(err, "parse cidr")
+		}
+		if len(prefixes) == 0 {
+			return nil, errors.Errorf("empty cidr of client %q rule", rule.ClientID)
+		}
+		rule.Prefix = prefixes[0]
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return rules, nil
+}
+
+var _ ipaccessservice.IPAccessRuleRepository = &IPAccessRuleRepository{}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	magiclinkservice "auth/internal/domain/services/magic-link-service"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type MagicLinkRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewMagicLinkRepository(db *sqlx.DB) *MagicLinkRepository {
+	return &MagicLinkRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *MagicLinkRepository) Create(link *domain.MagicLink) error {
+	query, args, err := s.builder.
+		Insert("magic_links").
+		Columns("token_hash, user_id, client_id, nonce_hash, expires_at").
+		Values(
+			link.TokenHash, link.UserID, link.ClientID,
+			link.NonceHash, link.ExpirationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *MagicLinkRepository) Take(tokenHash []byte) (*domain.MagicLink, error) {
+	query, args, err := s.builder.
+		Delete("magic_links").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Suffix("RETURNING token_hash, user_id, client_id, nonce_hash, expires_at").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var link domain.MagicLink
+	err = s.db.QueryRow(query, args...).Scan(
+		&link.TokenHash, &link.UserID, &link.ClientID,
+		&link.NonceHash, &link.ExpirationTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return &link, nil
+}
+
+func (s *MagicLinkRepository) CountActiveByUserID(userID uuid.UUID) (int, error) {
+	query, args, err := s.builder.
+		Select("COUNT(*)").
+		From("magic_links").
+		Where(sq.Eq{"user_id": userID}).
+		Where("expires_at > NOW()").
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "build query")
+	}
+
+	var count int
+	err = s.db.Get(&count, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "execute query")
+	}
+
+	return count, nil
+}
+
+func (s *MagicLinkRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("magic_links").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ magiclinkservice.MagicLinkRepository = &MagicLinkRepository{}
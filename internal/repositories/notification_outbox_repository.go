@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	notificationservice "auth/internal/domain/services/notification-service"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const outboxNotificationColumns = `id, user_id, channel, subject, text_body, html_body,
+	revoke_link_id, revoke_link_session_id, revoke_link_expires_at,
+	status, attempts, next_attempt_at, last_error, created_at`
+
+type NotificationOutboxRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewNotificationOutboxRepository(db *sqlx.DB) *NotificationOutboxRepository {
+	return &NotificationOutboxRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *NotificationOutboxRepository) Create(notifications []*domain.OutboxNotification) error {
+	return insertOutboxNotifications(s.db, s.builder, notifications)
+}
+
+// ClaimDue returns up to limit pending notifications due for sending and
+// postpones their next attempt by lease, so other workers and service
+// instances don't pick them up while they are being sent.
+func (s *NotificationOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]domain.OutboxNotification, error) {
+	now := time.Now()
+	// subquery placeholders are converted along with the outer query ones
+	due, args, err := sq.
+		Select("id").
+		From("notification_outbox").
+		Where(sq.And{
+			sq.Eq{"status": domain.OutboxPending},
+			sq.LtOrEq{"next_attempt_at": now},
+		}).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	query, updateArgs, err := s.builder.
+		Update("notification_outbox").
+		Set("next_attempt_at", now.Add(lease)).
+		Where("id IN ("+due+")", args...).
+		Suffix("RETURNING " + outboxNotificationColumns).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	return s.query(query, updateArgs...)
+}
+
+// MarkSent records a successful attempt.
+func (s *NotificationOutboxRepository) MarkSent(id uuid.UUID) error {
+	return s.update(id, map[string]any{
+		"status":     domain.OutboxSent,
+		"attempts":   sq.Expr("attempts + 1"),
+		"last_error": "",
+	})
+}
+
+// MarkFailed records a failed attempt. The notification is retried at
+// nextAttemptTime or moved to the dead-letter state if dead is true.
+func (s *NotificationOutboxRepository) MarkFailed(
+	id uuid.UUID, lastError string, nextAttemptTime time.Time, dead bool,
+) error {
+
+	status := domain.OutboxPending
+	if dead {
+		status = domain.OutboxDead
+	}
+	return s.update(id, map[string]any{
+		"status":          status,
+		"attempts":        sq.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptTime,
+	})
+}
+
+// GetDead returns dead notifications, most recent first.
+func (s *NotificationOutboxRepository) GetDead(limit, offset int) ([]domain.OutboxNotification, error) {
+	query, args, err := s.builder.
+		Select(outboxNotificationColumns).
+		From("notification_outbox").
+		Where(sq.Eq{"status": domain.OutboxDead}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	return s.query(query, args...)
+}
+
+// Replay moves a dead notification back to pending with attempts reset.
+// It returns false if there is no dead notification with the ID.
+func (s *NotificationOutboxRepository) Replay(id uuid.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Update("notification_outbox").
+		SetMap(map[string]any{
+			"status":          domain.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		}).
+		Where(sq.Eq{"id": id, "status": domain.OutboxDead}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get affected rows")
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteSentBefore deletes notifications sent before t.
+func (s *NotificationOutboxRepository) DeleteSentBefore(t time.Time) error {
+	query, args, err := s.builder.
+		Delete("notification_outbox").
+		Where(sq.And{
+			sq.Eq{"status": domain.OutboxSent},
+			sq.Lt{"created_at": t},
+		}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *NotificationOutboxRepository) update(id uuid.UUID, values map[string]any) error {
+	query, args, err := s.builder.
+		Update("notification_outbox").
+		SetMap(values).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *NotificationOutboxRepository) query(query string, args ...any) ([]domain.OutboxNotification, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var notifications []domain.OutboxNotification
+	for rows.Next() {
+		var notification domain.OutboxNotification
+		var revokeLinkID, revokeLinkSessionID uuid.NullUUID
+		var revokeLinkExpirationTime sql.NullTime
+		err := rows.Scan(
+			&notification.ID, &notification.UserID, &notification.Channel, &notification.Content.Subject,
+			&notification.Content.TextBody, &notification.Content.HTMLBody,
+			&revokeLinkID, &revokeLinkSessionID, &revokeLinkExpirationTime,
+			&notification.Status, &notification.Attempts, &notification.NextAttemptTime,
+			&notification.LastError, &notification.CreationTime,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		if revokeLinkID.Valid {
+			notification.RevokeLink = &domain.RevokeLink{
+				ID:             revokeLinkID.UUID,
+				UserID:         notification.UserID,
+				SessionID:      revokeLinkSessionID.UUID,
+				ExpirationTime: revokeLinkExpirationTime.Time,
+			}
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return notifications, nil
+}
+
+// insertOutboxNotifications lets other repositories write notifications in
+// their transactions.
+func insertOutboxNotifications(
+	execer sqlx.Execer, builder sq.StatementBuilderType, notifications []*domain.OutboxNotification,
+) error {
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	insert := builder.
+		Insert("notification_outbox").
+		Columns(`user_id, channel, subject, text_body, html_body,
+			revoke_link_id, revoke_link_session_id, revoke_link_expires_at,
+			status, next_attempt_at, created_at`)
+	for _, notification := range notifications {
+		var revokeLinkID, revokeLinkSessionID uuid.NullUUID
+		var revokeLinkExpirationTime sql.NullTime
+		if link := notification.RevokeLink; link != nil {
+			revokeLinkID = uuid.NullUUID{UUID: link.ID, Valid: true}
+			revokeLinkSessionID = uuid.NullUUID{UUID: link.SessionID, Valid: true}
+			revokeLinkExpirationTime = sql.NullTime{Time: link.ExpirationTime, Valid: true}
+		}
+		insert = insert.Values(
+			notification.UserID, notification.Channel, notification.Content.Subject,
+			notification.Content.TextBody, notification.Content.HTMLBody,
+			revokeLinkID, revokeLinkSessionID, revokeLinkExpirationTime,
+			notification.Status, notification.NextAttemptTime, notification.CreationTime)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = execer.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "insert outbox notifications")
+	}
+
+	return nil
+}
+
+var _ notificationservice.NotificationOutboxRepository = &NotificationOutboxRepository{}
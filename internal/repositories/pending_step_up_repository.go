@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+type PendingStepUpRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewPendingStepUpRepository(db *sqlx.DB) *PendingStepUpRepository {
+	return &PendingStepUpRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *PendingStepUpRepository) Save(stepUp *domain.PendingStepUp) error {
+	query, args, err := s.builder.
+		Insert("pending_step_ups").
+		Columns("ticket, user_id, amr, expires_at").
+		Values(stepUp.Ticket, stepUp.UserID, pq.Array(stepUp.AMR), stepUp.ExpirationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *PendingStepUpRepository) Take(
+	ticket, userID uuid.UUID,
+) (*domain.PendingStepUp, error) {
+
+	query, args, err := s.builder.
+		Delete("pending_step_ups").
+		Where(sq.And{
+			sq.Eq{"ticket": ticket, "user_id": userID},
+			sq.Expr("expires_at > NOW()"),
+		}).
+		Suffix("RETURNING ticket, user_id, amr, expires_at").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var stepUp domain.PendingStepUp
+	err = s.db.QueryRow(query, args...).Scan(
+		&stepUp.Ticket, &stepUp.UserID, pq.Array(&stepUp.AMR), &stepUp.ExpirationTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return &stepUp, nil
+}
+
+func (s *PendingStepUpRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("pending_step_ups").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ authservice.PendingStepUpRepository = &PendingStepUpRepository{}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	mfaservice "auth/internal/domain/services/mfa-service"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type RecoveryCodeRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewRecoveryCodeRepository(db *sqlx.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *RecoveryCodeRepository) Replace(userID uuid.UUID, codeHashes [][]byte) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query, args, err := s.builder.
+		Delete("recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "delete recovery codes")
+	}
+
+	if len(codeHashes) > 0 {
+		insert := s.builder.
+			Insert("recovery_codes").
+			Columns("user_id, code_hash")
+		for _, codeHash := range codeHashes {
+			insert = insert.Values(userID, codeHash)
+		}
+		query, args, err = insert.ToSql()
+		if err != nil {
+			return errors.Wrap(err, "build query")
+		}
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return errors.Wrap(err, "insert recovery codes")
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "commit transaction")
+}
+
+func (s *RecoveryCodeRepository) Use(userID uuid.UUID, codeHash []byte) (bool, error) {
+	query, args, err := s.builder.
+		Delete("recovery_codes").
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return deleted > 0, nil
+}
+
+var _ mfaservice.RecoveryCodeRepository = &RecoveryCodeRepository{}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type RefreshChallengeRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewRefreshChallengeRepository(db *sqlx.DB) *RefreshChallengeRepository {
+	return &RefreshChallengeRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *RefreshChallengeRepository) Create(challenge *domain.RefreshChallenge) error {
+	query, args, err := s.builder.
+		Insert("refresh_challenges").
+		Columns(`id, user_id, refresh_token_id, ip, code_hash, created_at, expires_at`).
+		Values(
+			challenge.ID, challenge.UserID, challenge.RefreshTokenID, challenge.IP,
+			challenge.CodeHash, challenge.CreationTime, challenge.ExpirationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+// GetByID returns nil if the challenge is not found.
+func (s *RefreshChallengeRepository) GetByID(id uuid.UUID) (*domain.RefreshChallenge, error) {
+	query, args, err := s.builder.
+		Select(`id, user_id, refresh_token_id, ip, code_hash, attempts,
+			created_at, expires_at`).
+		From("refresh_challenges").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var challenge domain.RefreshChallenge
+	err = s.db.QueryRow(query, args...).Scan(
+		&challenge.ID, &challenge.UserID, &challenge.RefreshTokenID, &challenge.IP,
+		&challenge.CodeHash, &challenge.Attempts,
+		&challenge.CreationTime, &challenge.ExpirationTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return &challenge, nil
+}
+
+// GetOpen returns the unexpired challenge of the refresh token from the IP
+// address or nil if there is none.
+func (s *RefreshChallengeRepository) GetOpen(
+	refreshTokenID uuid.UUID, ip string,
+) (*domain.RefreshChallenge, error) {
+
+	query, args, err := s.builder.
+		Select(`id, user_id, refresh_token_id, ip, code_hash, attempts,
+			created_at, expires_at`).
+		From("refresh_challenges").
+		Where(sq.Eq{"refresh_token_id": refreshTokenID, "ip": ip}).
+		Where("expires_at > NOW()").
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var challenge domain.RefreshChallenge
+	err = s.db.QueryRow(query, args...).Scan(
+		&challenge.ID, &challenge.UserID, &challenge.RefreshTokenID, &challenge.IP,
+		&challenge.CodeHash, &challenge.Attempts,
+		&challenge.CreationTime, &challenge.ExpirationTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return &challenge, nil
+}
+
+// IncrementAttempts returns 0 if the challenge is not found.
+func (s *RefreshChallengeRepository) IncrementAttempts(id uuid.UUID) (int, error) {
+	query, args, err := s.builder.
+		Update("refresh_challenges").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING attempts").
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "build query")
+	}
+
+	var attempts int
+	err = s.db.QueryRow(query, args...).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "execute query")
+	}
+
+	return attempts, nil
+}
+
+func (s *RefreshChallengeRepository) Delete(id uuid.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Delete("refresh_challenges").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return deleted > 0, nil
+}
+
+func (s *RefreshChallengeRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("refresh_challenges").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ authservice.RefreshChallengeRepository = &RefreshChallengeRepository{}
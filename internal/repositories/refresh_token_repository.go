@@ -3,13 +3,23 @@ package repositories
 import (
 	"auth/internal/domain"
 	authservice "auth/internal/domain/services/auth-service"
+	"database/sql"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
+// sessionLockClassID is the first key of the transaction-level advisory
+// locks taken on a session before its refresh tokens are deleted, the second
+// key is a hash of the session ID. Rotations and revocations of a session
+// are serialized by them, so a revocation can't miss the token inserted by
+// a concurrent rotation.
+const sessionLockClassID = 7_039
+
 type RefreshTokenRepository struct {
 	db      *sqlx.DB
 	builder sq.StatementBuilderType
@@ -25,8 +35,13 @@ func NewRefreshTokenRepository(db *sqlx.DB) *RefreshTokenRepository {
 func (s *RefreshTokenRepository) Create(token *domain.RefreshToken) (uuid.UUID, error) {
 	query, args, err := s.builder.
 		Insert("refresh_tokens").
-		Columns(`value_hash, expires_at`).
-		Values(token.ValueHash, token.ExpirationTime).
+		Columns(`user_id, client_id, value_hash, created_at, expires_at,
+			session_id, session_started_at, lifetime_profile, auth_time, acr, amr`).
+		Values(
+			token.UserID, token.ClientID, token.ValueHash,
+			token.CreationTime, token.ExpirationTime, token.SessionID,
+			token.SessionStartTime, token.LifetimeProfile, nullTime(token.AuthTime),
+			token.ACR, pq.Array(token.AMR)).
 		Suffix("RETURNING \"id\"").
 		ToSql()
 	if err != nil {
@@ -44,7 +59,7 @@ func (s *RefreshTokenRepository) Create(token *domain.RefreshToken) (uuid.UUID,
 
 func (s *RefreshTokenRepository) GetByID(id uuid.UUID) (*domain.RefreshToken, error) {
 	query, args, err := s.builder.
-		Select("id, value_hash, expires_at").
+		Select(refreshTokenColumns).
 		From("refresh_tokens").
 		Where(sq.Eq{"id": id}).
 		ToSql()
@@ -52,15 +67,76 @@ func (s *RefreshTokenRepository) GetByID(id uuid.UUID) (*domain.RefreshToken, er
 		return nil, errors.Wrap(err, "build query")
 	}
 
-	var refreshToken domain.RefreshToken
-	err = s.db.QueryRow(query, args...).Scan(
-		&refreshToken.ID, &refreshToken.ValueHash, &refreshToken.ExpirationTime,
-	)
+	refreshToken, err := scanRefreshToken(s.db.QueryRow(query, args...))
 	if err != nil {
 		return nil, errors.Wrap(err, "execute query")
 	}
 
-	return &refreshToken, nil
+	return refreshToken, nil
+}
+
+// GetBySessionID returns the current refresh token of the session, nil if
+// the session has ended.
+func (s *RefreshTokenRepository) GetBySessionID(
+	sessionID uuid.UUID,
+) (*domain.RefreshToken, error) {
+
+	query, args, err := s.builder.
+		Select(refreshTokenColumns).
+		From("refresh_tokens").
+		Where(sq.Eq{"session_id": sessionID}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	refreshToken, err := scanRefreshToken(s.db.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return refreshToken, nil
+}
+
+func (s *RefreshTokenRepository) GetAllActiveByUserID(
+	userID uuid.UUID,
+) ([]domain.RefreshToken, error) {
+
+	query, args, err := s.builder.
+		Select(refreshTokenColumns).
+		From("refresh_tokens").
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Gt{"expires_at": time.Now()},
+		}).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var refreshTokens []domain.RefreshToken
+	for rows.Next() {
+		refreshToken, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		refreshTokens = append(refreshTokens, *refreshToken)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return refreshTokens, nil
 }
 
 func (s *RefreshTokenRepository) DeleteByID(id uuid.UUID) error {
@@ -80,6 +156,171 @@ func (s *RefreshTokenRepository) DeleteByID(id uuid.UUID) error {
 	return nil
 }
 
+// Rotate replaces the refresh token with a new one and stores notifications
+// to the user in the same transaction. The new token ID is generated by the
+// caller, so the notifications can refer to it. It returns false if the
+// token has already been deleted by a concurrent rotation or revocation.
+func (s *RefreshTokenRepository) Rotate(
+	id uuid.UUID, newToken *domain.RefreshToken, notifications []*domain.OutboxNotification,
+) (bool, error) {
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = lockSession(tx, newToken.SessionID)
+	if err != nil {
+		return false, err
+	}
+
+	query, args, err := s.builder.
+		Delete("refresh_tokens").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "delete refresh token")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+	if deleted == 0 {
+		return false, nil
+	}
+
+	query, args, err = s.builder.
+		Insert("refresh_tokens").
+		Columns(`id, user_id, client_id, value_hash, created_at, expires_at,
+			session_id, session_started_at, lifetime_profile, auth_time, acr, amr`).
+		Values(
+			newToken.ID, newToken.UserID, newToken.ClientID, newToken.ValueHash,
+			newToken.CreationTime, newToken.ExpirationTime, newToken.SessionID,
+			newToken.SessionStartTime, newToken.LifetimeProfile,
+			nullTime(newToken.AuthTime), newToken.ACR, pq.Array(newToken.AMR)).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "insert refresh token")
+	}
+
+	err = insertOutboxNotifications(tx, s.builder, notifications)
+	if err != nil {
+		return false, err
+	}
+
+	return true, errors.Wrap(tx.Commit(), "commit transaction")
+}
+
+// RevokeSession deletes refresh tokens of the session and adds the session
+// to the revoked tokens denylist and the revocation feed until revokedUntil.
+// The session is denylisted even if it has already ended, since its access
+// tokens may still be valid. It returns false if no refresh token is deleted.
+func (s *RefreshTokenRepository) RevokeSession(
+	sessionID uuid.UUID, revokedUntil time.Time,
+) (bool, error) {
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = lockSession(tx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	query, args, err := s.builder.
+		Delete("refresh_tokens").
+		Where(sq.Eq{"session_id": sessionID}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "delete refresh tokens")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	err = insertRevokedToken(tx, s.builder, &domain.RevokedToken{
+		Kind:           domain.RevokedTokenKindSession,
+		ID:             sessionID,
+		RevocationTime: time.Now(),
+		ExpirationTime: revokedUntil,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted > 0, errors.Wrap(tx.Commit(), "commit transaction")
+}
+
+func (s *RefreshTokenRepository) IncrementFailedAttempts(id uuid.UUID) (int, error) {
+	query, args, err := s.builder.
+		Update("refresh_tokens").
+		Set("failed_attempts", sq.Expr("failed_attempts + 1")).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING \"failed_attempts\"").
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "build query")
+	}
+
+	var failedAttempts int
+	err = s.db.QueryRow(query, args...).Scan(&failedAttempts)
+	if err != nil {
+		return 0, errors.Wrap(err, "execute query")
+	}
+
+	return failedAttempts, nil
+}
+
+// SetAuthContext adds amr to the methods of the token keeping the order they
+// were first used in.
+func (s *RefreshTokenRepository) SetAuthContext(
+	id uuid.UUID, acr string, amr []string, authTime time.Time,
+) (bool, error) {
+
+	query, args, err := s.builder.
+		Update("refresh_tokens").
+		Set("acr", acr).
+		Set("amr", sq.Expr(`ARRAY(
+			SELECT method
+			FROM unnest(COALESCE(amr, '{}') || ?::TEXT[]) WITH ORDINALITY AS m(method, n)
+			GROUP BY method
+			ORDER BY MIN(n))`, pq.Array(amr))).
+		Set("auth_time", authTime).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return updated > 0, nil
+}
+
 func (s *RefreshTokenRepository) DeleteAllExpired() error {
 	query, args, err := s.builder.
 		Delete("refresh_tokens").
@@ -97,4 +338,42 @@ func (s *RefreshTokenRepository) DeleteAllExpired() error {
 	return nil
 }
 
+const refreshTokenColumns = `id, user_id, client_id, value_hash, created_at, expires_at,
+	session_id, session_started_at, lifetime_profile, auth_time, acr, amr`
+
+func scanRefreshToken(row interface{ Scan(dest ...any) error }) (*domain.RefreshToken, error) {
+	var refreshToken domain.RefreshToken
+	var authTime sql.NullTime
+	err := row.Scan(
+		&refreshToken.ID, &refreshToken.UserID, &refreshToken.ClientID,
+		&refreshToken.ValueHash, &refreshToken.CreationTime,
+		&refreshToken.ExpirationTime, &refreshToken.SessionID,
+		&refreshToken.SessionStartTime, &refreshToken.LifetimeProfile,
+		&authTime, &refreshToken.ACR, pq.Array(&refreshToken.AMR),
+	)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken.AuthTime = authTime.Time
+
+	return &refreshToken, nil
+}
+
+// nullTime stores zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// lockSession takes the advisory lock of the session until the end of the
+// transaction.
+func lockSession(tx *sqlx.Tx, sessionID uuid.UUID) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock($1, hashtext($2))",
+		sessionLockClassID, sessionID.String())
+	if err != nil {
+		return errors.Wrap(err, "lock session")
+	}
+
+	return nil
+}
+
 var _ authservice.RefreshTokenRepository = &RefreshTokenRepository{}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"auth/internal/db/postgres"
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// RevokedTokenCache keeps active revoked tokens in memory, so revocation
+// checks don't hit the database. Revocations made by any service instance are
+// received through the pubsub. While the pubsub listener is disconnected the
+// checks fall back to the database, after reconnect the cache is resynced.
+type RevokedTokenCache struct {
+	*RevokedTokenRepository
+
+	mu     sync.RWMutex
+	synced bool
+	tokens map[revokedTokenKey]time.Time
+}
+
+type revokedTokenKey struct {
+	kind domain.RevokedTokenKind
+	id   uuid.UUID
+}
+
+// revokedTokenNotification is revoked_tokens row sent by the table trigger.
+type revokedTokenNotification struct {
+	Kind      domain.RevokedTokenKind `json:"kind"`
+	TokenID   uuid.UUID               `json:"token_id"`
+	ExpiresAt time.Time               `json:"expires_at"`
+}
+
+func NewRevokedTokenCache(
+	repository *RevokedTokenRepository, pubSub *postgres.PubSub,
+) (*RevokedTokenCache, error) {
+
+	c := &RevokedTokenCache{
+		RevokedTokenRepository: repository,
+		tokens:                 make(map[revokedTokenKey]time.Time),
+	}
+	err := pubSub.Subscribe(postgres.ChannelRevokedTokens, postgres.Subscription{
+		OnNotification: c.handleNotification,
+		OnDisconnect:   c.markUnsynced,
+		OnResync: func() {
+			if err := c.resync(); err != nil {
+				slogutils.Error("resync revoked tokens cache", err)
+			}
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "subscribe to revoked tokens")
+	}
+
+	// snapshot is loaded after subscribing, so no revocation is missed
+	if err := c.resync(); err != nil {
+		return nil, errors.Wrap(err, "load revoked tokens")
+	}
+
+	return c, nil
+}
+
+func (c *RevokedTokenCache) IsRevoked(jti, sessionID uuid.UUID) (bool, error) {
+	c.mu.RLock()
+	synced := c.synced
+	now := time.Now()
+	revoked := false
+	for _, key := range []revokedTokenKey{
+		{kind: domain.RevokedTokenKindAccessToken, id: jti},
+		{kind: domain.RevokedTokenKindSession, id: sessionID},
+	} {
+		if expiresAt, ok := c.tokens[key]; ok && expiresAt.After(now) {
+			revoked = true
+		}
+	}
+	c.mu.RUnlock()
+
+	if revoked || synced {
+		return revoked, nil
+	}
+	return c.RevokedTokenRepository.IsRevoked(jti, sessionID)
+}
+
+func (c *RevokedTokenCache) DeleteAllExpired() error {
+	now := time.Now()
+	c.mu.Lock()
+	for key, expiresAt := range c.tokens {
+		if !expiresAt.After(now) {
+			delete(c.tokens, key)
+		}
+	}
+	c.mu.Unlock()
+
+	return c.RevokedTokenRepository.DeleteAllExpired()
+}
+
+// resync merges active revoked tokens into the cache. Tokens are never
+// removed from the cache before they expire, so merging doesn't lose
+// notifications received while the snapshot was loaded.
+func (c *RevokedTokenCache) resync() error {
+	revokedTokens, err := c.RevokedTokenRepository.GetAllActive()
+	if err != nil {
+		return errors.Wrap(err, "get active revoked tokens")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, token := range revokedTokens {
+		c.add(token.Kind, token.ID, token.ExpirationTime)
+	}
+	c.synced = true
+
+	return nil
+}
+
+func (c *RevokedTokenCache) markUnsynced() {
+	c.mu.Lock()
+	c.synced = false
+	c.mu.Unlock()
+}
+
+func (c *RevokedTokenCache) handleNotification(payload string) {
+	var notification revokedTokenNotification
+	err := json.Unmarshal([]byte(payload), &notification)
+	if err != nil {
+		slogutils.Error("parse revoked token notification", err)
+		return
+	}
+
+	c.mu.Lock()
+	c.add(notification.Kind, notification.TokenID, notification.ExpiresAt)
+	c.mu.Unlock()
+}
+
+func (c *RevokedTokenCache) add(
+	kind domain.RevokedTokenKind, id uuid.UUID, expiresAt time.Time,
+) {
+	key := revokedTokenKey{kind: kind, id: id}
+	if expiresAt.After(c.tokens[key]) {
+		c.tokens[key] = expiresAt
+	}
+}
+
+var _ authservice.RevokedTokenRepository = &RevokedTokenCache{}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// revokedTokensLockID is a key of the transaction-level advisory lock taken
+// before revoked tokens are written. It makes the order of the feed sequence
+// numbers match the order of commits, so feed readers can't skip an entry
+// which is committed after an entry with a greater sequence number.
+const revokedTokensLockID = 7_031_032
+
+type RevokedTokenRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewRevokedTokenRepository(db *sqlx.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *RevokedTokenRepository) Create(token *domain.RevokedToken) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = insertRevokedToken(tx, s.builder, token)
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit transaction")
+}
+
+// IsRevoked reports whether an access token is revoked either by its jti or
+// by its session ID.
+func (s *RevokedTokenRepository) IsRevoked(jti, sessionID uuid.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Select("1").
+		Prefix("SELECT EXISTS (").
+		From("revoked_tokens").
+		Where(sq.Or{
+			sq.Eq{"kind": domain.RevokedTokenKindAccessToken, "token_id": jti},
+			sq.Eq{"kind": domain.RevokedTokenKindSession, "token_id": sessionID},
+		}).
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	var revoked bool
+	err = s.db.QueryRow(query, args...).Scan(&revoked)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+
+	return revoked, nil
+}
+
+// GetAllActive returns not yet expired revoked tokens ordered by sequence
+// number.
+func (s *RevokedTokenRepository) GetAllActive() ([]domain.RevokedToken, error) {
+	return s.getRevokedTokens(sq.Expr("expires_at > NOW()"), 0)
+}
+
+// GetAfter returns up to limit revoked tokens with sequence number greater
+// than seq ordered by sequence number.
+func (s *RevokedTokenRepository) GetAfter(seq int64, limit int) ([]domain.RevokedToken, error) {
+	return s.getRevokedTokens(sq.Gt{"seq": seq}, limit)
+}
+
+func (s *RevokedTokenRepository) GetLastSeq() (int64, error) {
+	query, args, err := s.builder.
+		Select("COALESCE(MAX(seq), 0)").
+		From("revoked_tokens").
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "build query")
+	}
+
+	var seq int64
+	err = s.db.QueryRow(query, args...).Scan(&seq)
+	if err != nil {
+		return 0, errors.Wrap(err, "execute query")
+	}
+
+	return seq, nil
+}
+
+func (s *RevokedTokenRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("revoked_tokens").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *RevokedTokenRepository) getRevokedTokens(
+	where sq.Sqlizer, limit int,
+) ([]domain.RevokedToken, error) {
+
+	builder := s.builder.
+		Select("seq, kind, token_id, revoked_at, expires_at").
+		From("revoked_tokens").
+		Where(where).
+		OrderBy("seq")
+	if limit > 0 {
+		builder = builder.Limit(uint64(limit))
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var tokens []domain.RevokedToken
+	for rows.Next() {
+		var token domain.RevokedToken
+		err = rows.Scan(
+			&token.Seq, &token.Kind, &token.ID,
+			&token.RevocationTime, &token.ExpirationTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return tokens, nil
+}
+
+// insertRevokedToken adds the token to the denylist in the transaction.
+// Revoking an already revoked token extends its expiration time and moves it
+// to the end of the feed.
+func insertRevokedToken(
+	tx *sqlx.Tx, builder sq.StatementBuilderType, token *domain.RevokedToken,
+) error {
+
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", revokedTokensLockID)
+	if err != nil {
+		return errors.Wrap(err, "lock revoked tokens")
+	}
+
+	query, args, err := builder.
+		Insert("revoked_tokens").
+		Columns("kind, token_id, revoked_at, expires_at").
+		Values(token.Kind, token.ID, token.RevocationTime, token.ExpirationTime).
+		Suffix(`ON CONFLICT (kind, token_id) DO UPDATE SET
+			expires_at = GREATEST(revoked_tokens.expires_at, EXCLUDED.expires_at),
+			revoked_at = EXCLUDED.revoked_at,
+			seq = DEFAULT`).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "insert revoked token")
+	}
+
+	return nil
+}
+
+var _ authservice.RevokedTokenRepository = &RevokedTokenRepository{}
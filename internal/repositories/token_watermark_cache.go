@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"auth/internal/db/postgres"
+	authservice "auth/internal/domain/services/auth-service"
+	slogutils "auth/internal/utils/slog-utils"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// tokenWatermarkCacheMaxSize bounds memory used by the cache. When it is
+// reached the cache is cleared and filled again by subsequent lookups.
+const tokenWatermarkCacheMaxSize = 100_000
+
+// TokenWatermarkCache caches users watermarks looked up from the database,
+// so token validation doesn't hit the database on every request. Watermarks
+// raised by any service instance are received through the pubsub. While the
+// pubsub listener is disconnected the cache is bypassed.
+type TokenWatermarkCache struct {
+	repository authservice.TokenWatermarkRepository
+
+	mu     sync.RWMutex
+	synced bool
+	// generation is incremented when the cache is cleared
+	generation uint64
+	notBefores map[uuid.UUID]time.Time
+}
+
+// tokenWatermarkNotification is user_token_watermarks row sent by the table
+// trigger.
+type tokenWatermarkNotification struct {
+	UserID    uuid.UUID `json:"user_id"`
+	NotBefore time.Time `json:"not_before"`
+}
+
+func NewTokenWatermarkCache(
+	repository *TokenWatermarkRepository, pubSub *postgres.PubSub,
+) (*TokenWatermarkCache, error) {
+
+	c := newTokenWatermarkCache(repository)
+	err := pubSub.Subscribe(postgres.ChannelTokenWatermarks, postgres.Subscription{
+		OnNotification: c.handleNotification,
+		OnDisconnect:   c.reset,
+		OnResync: func() {
+			c.mu.Lock()
+			c.synced = true
+			c.mu.Unlock()
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "subscribe to token watermarks")
+	}
+
+	return c, nil
+}
+
+func newTokenWatermarkCache(
+	repository authservice.TokenWatermarkRepository,
+) *TokenWatermarkCache {
+
+	return &TokenWatermarkCache{
+		repository: repository,
+		synced:     true,
+		notBefores: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (c *TokenWatermarkCache) GetNotBefore(userID uuid.UUID) (time.Time, error) {
+	c.mu.RLock()
+	synced := c.synced
+	generation := c.generation
+	notBefore, ok := c.notBefores[userID]
+	c.mu.RUnlock()
+	if synced && ok {
+		return notBefore, nil
+	}
+
+	notBefore, err := c.repository.GetNotBefore(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the cache was cleared during the lookup, notifications might be lost
+	if !c.synced || c.generation != generation {
+		return notBefore, nil
+	}
+	c.raise(userID, notBefore)
+
+	return c.notBefores[userID], nil
+}
+
+func (c *TokenWatermarkCache) RaiseNotBefore(
+	userID uuid.UUID, notBefore, revokedUntil time.Time,
+) error {
+
+	err := c.repository.RaiseNotBefore(userID, notBefore, revokedUntil)
+	if err != nil {
+		return err
+	}
+
+	// don't wait for the notification to take effect on this instance
+	c.mu.Lock()
+	c.raise(userID, notBefore)
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *TokenWatermarkCache) reset() {
+	c.mu.Lock()
+	c.synced = false
+	c.clear()
+	c.mu.Unlock()
+}
+
+func (c *TokenWatermarkCache) handleNotification(payload string) {
+	var notification tokenWatermarkNotification
+	err := json.Unmarshal([]byte(payload), &notification)
+	if err != nil {
+		slogutils.Error("parse token watermark notification", err)
+		return
+	}
+
+	c.mu.Lock()
+	c.raise(notification.UserID, notification.NotBefore)
+	c.mu.Unlock()
+}
+
+// raise must be called with c.mu held for writing.
+func (c *TokenWatermarkCache) raise(userID uuid.UUID, notBefore time.Time) {
+	current, ok := c.notBefores[userID]
+	if ok && !notBefore.After(current) {
+		return
+	}
+	if !ok && len(c.notBefores) >= tokenWatermarkCacheMaxSize {
+		c.clear()
+	}
+	c.notBefores[userID] = notBefore
+}
+
+func (c *TokenWatermarkCache) clear() {
+	c.notBefores = make(map[uuid.UUID]time.Time)
+	c.generation++
+}
+
+var _ authservice.TokenWatermarkRepository = &TokenWatermarkCache{}
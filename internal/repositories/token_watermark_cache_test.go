@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"auth/internal/domain/services/auth-service/mocks"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	userID    = uuid.MustParse("1f4d7c2a-9b3e-4c5d-8e6f-7a8b9c0d1e2f")
+	notBefore = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func TestTokenWatermarkCache_CachesLookups(t *testing.T) {
+	repository := mocks.NewTokenWatermarkRepository(t)
+	repository.On("GetNotBefore", userID).Return(notBefore, nil).Once()
+	cache := newTokenWatermarkCache(repository)
+
+	for range 2 {
+		cached, err := cache.GetNotBefore(userID)
+		require.NoError(t, err)
+		assert.Equal(t, notBefore, cached)
+	}
+}
+
+func TestTokenWatermarkCache_Notification(t *testing.T) {
+	repository := mocks.NewTokenWatermarkRepository(t)
+	repository.On("GetNotBefore", userID).Return(notBefore, nil).Once()
+	cache := newTokenWatermarkCache(repository)
+	_, err := cache.GetNotBefore(userID)
+	require.NoError(t, err)
+
+	raised := notBefore.Add(time.Hour)
+	cache.handleNotification(fmt.Sprintf(
+		`{"user_id": %q, "not_before": %q}`, userID, raised.Format(time.RFC3339Nano)))
+	// the watermark is never moved back
+	cache.handleNotification(fmt.Sprintf(
+		`{"user_id": %q, "not_before": %q}`, userID, notBefore.Format(time.RFC3339Nano)))
+	cache.handleNotification("garbage")
+
+	cached, err := cache.GetNotBefore(userID)
+	require.NoError(t, err)
+	assert.True(t, raised.Equal(cached))
+}
+
+func TestTokenWatermarkCache_RaiseNotBefore(t *testing.T) {
+	repository := mocks.NewTokenWatermarkRepository(t)
+	revokedUntil := notBefore.Add(time.Hour)
+	repository.On("RaiseNotBefore", userID, notBefore, revokedUntil).Return(nil)
+	cache := newTokenWatermarkCache(repository)
+
+	require.NoError(t, cache.RaiseNotBefore(userID, notBefore, revokedUntil))
+
+	// taken from the cache without waiting for the notification
+	cached, err := cache.GetNotBefore(userID)
+	require.NoError(t, err)
+	assert.Equal(t, notBefore, cached)
+}
+
+func TestTokenWatermarkCache_BypassedWhileDisconnected(t *testing.T) {
+	repository := mocks.NewTokenWatermarkRepository(t)
+	repository.On("GetNotBefore", userID).Return(notBefore, nil).Times(3)
+	cache := newTokenWatermarkCache(repository)
+	_, err := cache.GetNotBefore(userID)
+	require.NoError(t, err)
+
+	// notifications may be lost until the listener resyncs
+	cache.reset()
+	for range 2 {
+		cached, err := cache.GetNotBefore(userID)
+		require.NoError(t, err)
+		assert.Equal(t, notBefore, cached)
+	}
+}
+
+func TestTokenWatermarkCache_MaxSize(t *testing.T) {
+	cache := newTokenWatermarkCache(mocks.NewTokenWatermarkRepository(t))
+	for range tokenWatermarkCacheMaxSize {
+		cache.raise(uuid.New(), notBefore)
+	}
+
+	cache.raise(userID, notBefore)
+
+	assert.Len(t, cache.notBefores, 1)
+	assert.Equal(t, uint64(1), cache.generation)
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	authservice "auth/internal/domain/services/auth-service"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type TokenWatermarkRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewTokenWatermarkRepository(db *sqlx.DB) *TokenWatermarkRepository {
+	return &TokenWatermarkRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// GetNotBefore returns zero time if the user has no watermark.
+func (s *TokenWatermarkRepository) GetNotBefore(userID uuid.UUID) (time.Time, error) {
+	query, args, err := s.builder.
+		Select("not_before").
+		From("user_token_watermarks").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "build query")
+	}
+
+	var notBefore time.Time
+	err = s.db.QueryRow(query, args...).Scan(&notBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "execute query")
+	}
+
+	return notBefore, nil
+}
+
+// RaiseNotBefore sets the watermark unless the current one is later and
+// publishes it in the revocation feed until revokedUntil.
+func (s *TokenWatermarkRepository) RaiseNotBefore(
+	userID uuid.UUID, notBefore, revokedUntil time.Time,
+) error {
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	query, args, err := s.builder.
+		Insert("user_token_watermarks").
+		Columns("user_id, not_before").
+		Values(userID, notBefore).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			not_before = GREATEST(user_token_watermarks.not_before, EXCLUDED.not_before)
+			RETURNING not_before`).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	var watermark time.Time
+	err = tx.QueryRow(query, args...).Scan(&watermark)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	err = insertRevokedToken(tx, s.builder, &domain.RevokedToken{
+		Kind:           domain.RevokedTokenKindUser,
+		ID:             userID,
+		RevocationTime: watermark,
+		ExpirationTime: revokedUntil,
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "commit transaction")
+}
+
+var _ authservice.TokenWatermarkRepository = &TokenWatermarkRepository{}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	mfaservice "auth/internal/domain/services/mfa-service"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type TOTPCredentialRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewTOTPCredentialRepository(db *sqlx.DB) *TOTPCredentialRepository {
+	return &TOTPCredentialRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *TOTPCredentialRepository) Save(credential *domain.TOTPCredential) error {
+	query, args, err := s.builder.
+		Insert("totp_credentials").
+		Columns("user_id, secret_encrypted, created_at").
+		Values(credential.UserID, credential.SecretEncrypted, credential.CreationTime).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE
+			SET secret_encrypted = EXCLUDED.secret_encrypted,
+				created_at = EXCLUDED.created_at
+			WHERE NOT totp_credentials.confirmed`).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *TOTPCredentialRepository) GetByUserID(userID uuid.UUID) (*domain.TOTPCredential, error) {
+	query, args, err := s.builder.
+		Select(`user_id, secret_encrypted, confirmed, last_used_step,
+			failed_attempts, locked_until, created_at`).
+		From("totp_credentials").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var credential domain.TOTPCredential
+	var lockedUntil sql.NullTime
+	err = s.db.QueryRow(query, args...).Scan(
+		&credential.UserID, &credential.SecretEncrypted, &credential.Confirmed,
+		&credential.LastUsedStep, &credential.FailedAttempts, &lockedUntil,
+		&credential.CreationTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	credential.LockedUntil = lockedUntil.Time
+
+	return &credential, nil
+}
+
+func (s *TOTPCredentialRepository) Confirm(userID uuid.UUID, step int64) error {
+	query, args, err := s.builder.
+		Update("totp_credentials").
+		Set("confirmed", true).
+		Set("last_used_step", step).
+		Set("failed_attempts", 0).
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *TOTPCredentialRepository) UseStep(userID uuid.UUID, step int64) (bool, error) {
+	query, args, err := s.builder.
+		Update("totp_credentials").
+		Set("last_used_step", step).
+		Set("failed_attempts", 0).
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Lt{"last_used_step": step},
+		}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return updated > 0, nil
+}
+
+func (s *TOTPCredentialRepository) IncrementFailedAttempts(userID uuid.UUID) (int, error) {
+	query, args, err := s.builder.
+		Update("totp_credentials").
+		Set("failed_attempts", sq.Expr("failed_attempts + 1")).
+		Where(sq.Eq{"user_id": userID}).
+		Suffix("RETURNING failed_attempts").
+		ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "build query")
+	}
+
+	var failedAttempts int
+	err = s.db.QueryRow(query, args...).Scan(&failedAttempts)
+	if err != nil {
+		return 0, errors.Wrap(err, "execute query")
+	}
+
+	return failedAttempts, nil
+}
+
+func (s *TOTPCredentialRepository) Lock(userID uuid.UUID, lockedUntil time.Time) error {
+	query, args, err := s.builder.
+		Update("totp_credentials").
+		Set("locked_until", lockedUntil).
+		Set("failed_attempts", 0).
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ mfaservice.TOTPCredentialRepository = &TOTPCredentialRepository{}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	authservice "auth/internal/domain/services/auth-service"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type UsedRevokeLinkRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewUsedRevokeLinkRepository(db *sqlx.DB) *UsedRevokeLinkRepository {
+	return &UsedRevokeLinkRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *UsedRevokeLinkRepository) Use(id uuid.UUID, expirationTime time.Time) (bool, error) {
+	query, args, err := s.builder.
+		Insert("used_revoke_links").
+		Columns("id, expires_at").
+		Values(id, expirationTime).
+		Suffix("ON CONFLICT (id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return inserted > 0, nil
+}
+
+func (s *UsedRevokeLinkRepository) IsUsed(id uuid.UUID) (bool, error) {
+	query, args, err := s.builder.
+		Select("1").
+		Prefix("SELECT EXISTS (").
+		From("used_revoke_links").
+		Where(sq.Eq{"id": id}).
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	var used bool
+	err = s.db.QueryRow(query, args...).Scan(&used)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+
+	return used, nil
+}
+
+// DeleteAllExpired deletes links which can't be used anyway since their
+// signature is expired.
+func (s *UsedRevokeLinkRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("used_revoke_links").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ authservice.UsedRevokeLinkRepository = &UsedRevokeLinkRepository{}
@@ -1,6 +1,10 @@
 package repositories
 
-import "github.com/google/uuid"
+import (
+	"auth/internal/domain"
+
+	"github.com/google/uuid"
+)
 
 type UserEmailsRepositoryMock struct{}
 
@@ -8,6 +12,22 @@ func (s *UserEmailsRepositoryMock) GetUserEmail(userID uuid.UUID) (string, error
 	return "user@gmail.com", nil
 }
 
+// GetUserIDByEmail returns an ID derived from the email, so every email
+// belongs to a user.
+func (s *UserEmailsRepositoryMock) GetUserIDByEmail(email string) (uuid.UUID, error) {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("mailto:"+email)), nil
+}
+
+// GetUserLocale returns the default locale.
+func (s *UserEmailsRepositoryMock) GetUserLocale(userID uuid.UUID) (domain.UserLocale, error) {
+	return domain.UserLocale{}, nil
+}
+
+// GetUserPhone returns no phone, so SMS notifications are skipped.
+func (s *UserEmailsRepositoryMock) GetUserPhone(userID uuid.UUID) (string, error) {
+	return "", nil
+}
+
 func NewUserRepositoryMock() *UserEmailsRepositoryMock {
 	return &UserEmailsRepositoryMock{}
 }
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	webauthnservice "auth/internal/domain/services/webauthn-service"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type WebAuthnCeremonyRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewWebAuthnCeremonyRepository(db *sqlx.DB) *WebAuthnCeremonyRepository {
+	return &WebAuthnCeremonyRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *WebAuthnCeremonyRepository) Create(ceremony *domain.WebAuthnCeremony) error {
+	query, args, err := s.builder.
+		Insert("webauthn_ceremonies").
+		Columns("id, kind, user_id, challenge, expires_at").
+		Values(
+			ceremony.ID, ceremony.Kind, ceremony.UserID,
+			ceremony.Challenge, ceremony.ExpirationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *WebAuthnCeremonyRepository) Take(id uuid.UUID) (*domain.WebAuthnCeremony, error) {
+	query, args, err := s.builder.
+		Delete("webauthn_ceremonies").
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING id, kind, user_id, challenge, expires_at").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	var ceremony domain.WebAuthnCeremony
+	err = s.db.QueryRow(query, args...).Scan(
+		&ceremony.ID, &ceremony.Kind, &ceremony.UserID,
+		&ceremony.Challenge, &ceremony.ExpirationTime,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return &ceremony, nil
+}
+
+func (s *WebAuthnCeremonyRepository) DeleteAllExpired() error {
+	query, args, err := s.builder.
+		Delete("webauthn_ceremonies").
+		Where("NOW() > expires_at").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+var _ webauthnservice.WebAuthnCeremonyRepository = &WebAuthnCeremonyRepository{}
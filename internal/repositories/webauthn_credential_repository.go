@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"auth/internal/domain"
+	webauthnservice "auth/internal/domain/services/webauthn-service"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const webAuthnCredentialColumns = `id, user_id, public_key, sign_count, transports,
+	name, created_at, last_used_at`
+
+type WebAuthnCredentialRepository struct {
+	db      *sqlx.DB
+	builder sq.StatementBuilderType
+}
+
+func NewWebAuthnCredentialRepository(db *sqlx.DB) *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *WebAuthnCredentialRepository) Create(credential *domain.WebAuthnCredential) error {
+	query, args, err := s.builder.
+		Insert("webauthn_credentials").
+		Columns("id, user_id, public_key, sign_count, transports, name, created_at").
+		Values(
+			credential.ID, credential.UserID, credential.PublicKey,
+			credential.SignCount, pq.Array(credential.Transports),
+			credential.Name, credential.CreationTime).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "build query")
+	}
+
+	_, err = s.db.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "execute query")
+	}
+
+	return nil
+}
+
+func (s *WebAuthnCredentialRepository) GetByID(id []byte) (*domain.WebAuthnCredential, error) {
+	query, args, err := s.builder.
+		Select(webAuthnCredentialColumns).
+		From("webauthn_credentials").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	credential, err := scanWebAuthnCredential(s.db.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+
+	return credential, nil
+}
+
+func (s *WebAuthnCredentialRepository) GetAllByUserID(
+	userID uuid.UUID,
+) ([]domain.WebAuthnCredential, error) {
+
+	query, args, err := s.builder.
+		Select(webAuthnCredentialColumns).
+		From("webauthn_credentials").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "build query")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "execute query")
+	}
+	defer rows.Close()
+
+	var credentials []domain.WebAuthnCredential
+	for rows.Next() {
+		credential, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan row")
+		}
+		credentials = append(credentials, *credential)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "iterate rows")
+	}
+
+	return credentials, nil
+}
+
+func (s *WebAuthnCredentialRepository) UpdateSignCount(
+	id []byte, signCount uint32, lastUsedTime time.Time,
+) (bool, error) {
+
+	query, args, err := s.builder.
+		Update("webauthn_credentials").
+		Set("sign_count", signCount).
+		Set("last_used_at", lastUsedTime).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Or{
+				sq.Lt{"sign_count": signCount},
+				sq.Expr("sign_count = 0 AND ? = 0", signCount),
+			},
+		}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return updated > 0, nil
+}
+
+func (s *WebAuthnCredentialRepository) Delete(userID uuid.UUID, id []byte) (bool, error) {
+	query, args, err := s.builder.
+		Delete("webauthn_credentials").
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return false, errors.Wrap(err, "build query")
+	}
+
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "execute query")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "get rows affected")
+	}
+
+	return deleted > 0, nil
+}
+
+func scanWebAuthnCredential(row sq.RowScanner) (*domain.WebAuthnCredential, error) {
+	var credential domain.WebAuthnCredential
+	var lastUsedTime sql.NullTime
+	err := row.Scan(
+		&credential.ID, &credential.UserID, &credential.PublicKey,
+		&credential.SignCount, pq.Array(&credential.Transports),
+		&credential.Name, &credential.CreationTime, &lastUsedTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	credential.LastUsedTime = lastUsedTime.Time
+
+	return &credential, nil
+}
+
+var _ webauthnservice.WebAuthnCredentialRepository = &WebAuthnCredentialRepository{}
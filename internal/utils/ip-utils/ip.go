@@ -0,0 +1,67 @@
+package iputils
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Normalize returns canonical text form of an IP address: IPv4-mapped IPv6
+// addresses are converted to IPv4, IPv6 zones are dropped and IPv6 addresses
+// are compressed as described in RFC 5952. Unparsable input is returned
+// trimmed but otherwise unchanged.
+func Normalize(ip string) string {
+	addr, err := ParseAddr(ip)
+	if err != nil {
+		return strings.TrimSpace(ip)
+	}
+	return addr.String()
+}
+
+// ParseAddr parses an IP address in the same canonical form Normalize uses.
+func ParseAddr(ip string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap().WithZone(""), nil
+}
+
+// ParsePrefixes parses a list of CIDRs. Plain IP addresses are accepted too
+// and are treated as single address prefixes.
+func ParsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			addr, err := ParseAddr(cidr)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// ContainsAddr reports whether any of prefixes contains addr.
+func ContainsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package jwtutils
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -31,9 +32,11 @@ func GetTimeJWTClaim(claimsMap jwt.MapClaims, claimName string) (time.Time, erro
 	if !ok {
 		return time.Time{}, fmt.Errorf("claim is not of type float64")
 	}
-	sec := int64(claim)
+	// fractional part is kept with microsecond precision, which float64
+	// still holds for current times
+	sec, frac := math.Modf(claim)
 
-	return time.Unix(sec, 0), nil
+	return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), nil
 }
 
 func GetStringJWTClaim(claimsMap jwt.MapClaims, claimName string) (string, error) {
@@ -48,6 +51,27 @@ func GetStringJWTClaim(claimsMap jwt.MapClaims, claimName string) (string, error
 	return claim, nil
 }
 
+// GetStringsJWTClaim parses a claim which is an array of strings.
+func GetStringsJWTClaim(claimsMap jwt.MapClaims, claimName string) ([]string, error) {
+	claimAny, err := getJWTClaim(claimsMap, claimName)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := claimAny.([]any)
+	if !ok {
+		return nil, fmt.Errorf("claim %s is not of type array", claimName)
+	}
+	claim := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("claim %s is not an array of strings", claimName)
+		}
+		claim = append(claim, str)
+	}
+	return claim, nil
+}
+
 func getJWTClaim(claimsMap jwt.MapClaims, claimName string) (any, error) {
 	claim, ok := claimsMap[claimName]
 	if !ok {
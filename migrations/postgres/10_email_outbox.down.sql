@@ -0,0 +1 @@
+DROP TABLE email_outbox;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE email_outbox ADD COLUMN content_type TEXT NOT NULL DEFAULT 'text/plain';
+ALTER TABLE email_outbox DROP COLUMN html_body;
+ALTER TABLE email_outbox RENAME COLUMN text_body TO body;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE email_outbox RENAME COLUMN body TO text_body;
+ALTER TABLE email_outbox ADD COLUMN html_body TEXT NOT NULL DEFAULT '';
+ALTER TABLE email_outbox DROP COLUMN content_type;
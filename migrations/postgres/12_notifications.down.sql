@@ -0,0 +1,7 @@
+DROP TABLE in_app_notifications;
+
+DELETE FROM notification_outbox WHERE channel <> 'email';
+ALTER TABLE notification_outbox DROP COLUMN channel;
+ALTER INDEX notification_outbox_status_idx RENAME TO email_outbox_status_idx;
+ALTER INDEX notification_outbox_pending_idx RENAME TO email_outbox_pending_idx;
+ALTER TABLE notification_outbox RENAME TO email_outbox;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE email_outbox RENAME TO notification_outbox;
+ALTER INDEX email_outbox_pending_idx RENAME TO notification_outbox_pending_idx;
+ALTER INDEX email_outbox_status_idx RENAME TO notification_outbox_status_idx;
+ALTER TABLE notification_outbox ADD COLUMN channel TEXT NOT NULL DEFAULT 'email';
+ALTER TABLE notification_outbox ALTER COLUMN channel DROP DEFAULT;
+
+CREATE TABLE in_app_notifications (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id uuid NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX in_app_notifications_user_id_idx ON in_app_notifications (user_id, created_at);
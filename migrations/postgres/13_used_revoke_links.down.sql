@@ -0,0 +1 @@
+DROP TABLE used_revoke_links;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE used_revoke_links (
+    id uuid PRIMARY KEY,
+    used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE refresh_challenges;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE refresh_challenges (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    refresh_token_id uuid NOT NULL,
+    ip TEXT NOT NULL,
+    code_hash BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
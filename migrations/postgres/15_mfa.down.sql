@@ -0,0 +1,6 @@
+DROP TABLE recovery_codes;
+DROP TABLE totp_credentials;
+DROP TABLE pending_step_ups;
+
+ALTER TABLE refresh_tokens DROP COLUMN amr;
+ALTER TABLE refresh_tokens DROP COLUMN acr;
\ No newline at end of file
@@ -0,0 +1,24 @@
+ALTER TABLE refresh_tokens ADD COLUMN acr TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN amr TEXT[];
+
+CREATE TABLE pending_step_ups (
+    user_id uuid PRIMARY KEY,
+    amr TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE totp_credentials (
+    user_id uuid PRIMARY KEY,
+    secret_encrypted BYTEA NOT NULL,
+    confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE recovery_codes (
+    user_id uuid NOT NULL,
+    code_hash BYTEA NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
@@ -0,0 +1 @@
+DROP TABLE magic_links;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE magic_links (
+    token_hash BYTEA PRIMARY KEY,
+    user_id uuid NOT NULL,
+    client_id TEXT NOT NULL,
+    nonce_hash BYTEA NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX magic_links_user_id_idx ON magic_links (user_id);
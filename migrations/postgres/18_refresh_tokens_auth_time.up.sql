@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ADD COLUMN auth_time TIMESTAMP WITH TIME ZONE;
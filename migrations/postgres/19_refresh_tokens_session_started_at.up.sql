@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMP WITH TIME ZONE;
+UPDATE refresh_tokens SET session_started_at = created_at;
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
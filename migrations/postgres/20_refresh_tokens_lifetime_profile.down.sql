@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens DROP COLUMN lifetime_profile;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ADD COLUMN lifetime_profile TEXT NOT NULL DEFAULT '';
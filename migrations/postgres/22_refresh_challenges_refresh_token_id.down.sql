@@ -0,0 +1 @@
+DROP INDEX refresh_challenges_refresh_token_id_idx;
\ No newline at end of file
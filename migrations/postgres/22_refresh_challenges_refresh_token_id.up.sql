@@ -0,0 +1 @@
+CREATE INDEX refresh_challenges_refresh_token_id_idx ON refresh_challenges (refresh_token_id, ip);
@@ -0,0 +1,7 @@
+DROP TABLE pending_step_ups;
+
+CREATE TABLE pending_step_ups (
+    user_id uuid PRIMARY KEY,
+    amr TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
\ No newline at end of file
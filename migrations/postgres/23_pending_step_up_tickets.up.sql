@@ -0,0 +1,8 @@
+DROP TABLE pending_step_ups;
+
+CREATE TABLE pending_step_ups (
+    ticket uuid PRIMARY KEY,
+    user_id uuid NOT NULL,
+    amr TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE revoked_tokens DROP CONSTRAINT revoked_tokens_kind_check;
+ALTER TABLE revoked_tokens ADD CONSTRAINT revoked_tokens_kind_check
+    CHECK (kind IN ('jti', 'refresh_token_id', 'sid'));
@@ -0,0 +1 @@
+ALTER TABLE audit_events DROP COLUMN session_id;
\ No newline at end of file
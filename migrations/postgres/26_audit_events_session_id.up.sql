@@ -0,0 +1 @@
+ALTER TABLE audit_events ADD COLUMN session_id uuid;
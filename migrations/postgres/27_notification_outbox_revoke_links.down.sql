@@ -0,0 +1,4 @@
+ALTER TABLE notification_outbox
+    DROP COLUMN revoke_link_id,
+    DROP COLUMN revoke_link_session_id,
+    DROP COLUMN revoke_link_expires_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE notification_outbox
+    ADD COLUMN revoke_link_id uuid,
+    ADD COLUMN revoke_link_session_id uuid,
+    ADD COLUMN revoke_link_expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+DROP TABLE audit_events;
+
+ALTER TABLE refresh_tokens DROP COLUMN failed_attempts;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE refresh_tokens ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE audit_events (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    type TEXT NOT NULL,
+    user_id uuid,
+    refresh_token_id uuid,
+    ip TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
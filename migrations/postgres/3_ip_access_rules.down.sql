@@ -0,0 +1,5 @@
+DROP TABLE ip_access_rules;
+
+ALTER TABLE audit_events DROP COLUMN client_id;
+
+ALTER TABLE refresh_tokens DROP COLUMN client_id;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE refresh_tokens ADD COLUMN client_id TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE audit_events ADD COLUMN client_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE ip_access_rules (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    client_id TEXT NOT NULL DEFAULT '',
+    cidr CIDR NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('allow', 'deny'))
+);
@@ -0,0 +1,3 @@
+DROP TABLE user_token_watermarks;
+
+ALTER TABLE refresh_tokens DROP COLUMN created_at;
\ No newline at end of file
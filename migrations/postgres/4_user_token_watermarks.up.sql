@@ -0,0 +1,6 @@
+ALTER TABLE refresh_tokens ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+CREATE TABLE user_token_watermarks (
+    user_id uuid PRIMARY KEY,
+    not_before TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE revoked_tokens (
+    kind TEXT NOT NULL CHECK (kind IN ('jti', 'refresh_token_id')),
+    token_id uuid NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (kind, token_id)
+);
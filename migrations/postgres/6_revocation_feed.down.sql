@@ -0,0 +1 @@
+ALTER TABLE revoked_tokens DROP COLUMN seq;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE revoked_tokens ADD COLUMN seq BIGSERIAL NOT NULL;
+
+CREATE UNIQUE INDEX revoked_tokens_seq_idx ON revoked_tokens (seq);
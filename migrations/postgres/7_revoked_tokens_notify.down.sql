@@ -0,0 +1,3 @@
+DROP TRIGGER revoked_tokens_notify ON revoked_tokens;
+
+DROP FUNCTION notify_revoked_token();
\ No newline at end of file
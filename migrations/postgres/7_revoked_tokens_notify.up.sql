@@ -0,0 +1,10 @@
+CREATE FUNCTION notify_revoked_token() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('auth_revoked_tokens', row_to_json(NEW)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER revoked_tokens_notify
+    AFTER INSERT OR UPDATE ON revoked_tokens
+    FOR EACH ROW EXECUTE FUNCTION notify_revoked_token();
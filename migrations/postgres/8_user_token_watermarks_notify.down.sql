@@ -0,0 +1,3 @@
+DROP TRIGGER user_token_watermarks_notify ON user_token_watermarks;
+
+DROP FUNCTION notify_user_token_watermark();
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE FUNCTION notify_user_token_watermark() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('auth_token_watermarks', row_to_json(NEW)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_token_watermarks_notify
+    AFTER INSERT OR UPDATE ON user_token_watermarks
+    FOR EACH ROW EXECUTE FUNCTION notify_user_token_watermark();
@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens DROP COLUMN user_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens ADD COLUMN user_id uuid;
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
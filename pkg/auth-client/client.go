@@ -0,0 +1,266 @@
+// Package authclient is a client of the auth service HTTP API.
+package authclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrUnauthorized matches APIError with 401 status, e.g. when the refresh
+// token is expired, revoked or was already used.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrChallengeRequired matches APIError of a refresh from a new IP address
+// which must be confirmed with the code emailed to the user. The refresh is
+// completed by CompleteRefreshChallenge with APIError.ChallengeID.
+var ErrChallengeRequired = errors.New("challenge required")
+
+// APIError is a non-successful auth service response.
+type APIError struct {
+	StatusCode int
+	Message    string `json:"error"`
+	Code       string `json:"code"`
+	// ChallengeID is set if the error matches ErrChallengeRequired.
+	ChallengeID string `json:"challengeId"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("auth service: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("auth service: %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrChallengeRequired:
+		return e.Code == "challenge_required"
+	default:
+		return false
+	}
+}
+
+// Session is an access and refresh tokens pair.
+type Session struct {
+	AccessToken string
+	// RefreshToken is the raw refresh token value, the client takes care of
+	// its base64 encoding on the wire.
+	RefreshToken []byte
+	// Expiry is the access token "exp" claim. It is read without signature
+	// verification and must only be used to schedule refreshes.
+	Expiry time.Time
+}
+
+type sessionDTO struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (dto sessionDTO) toSession() (*Session, error) {
+	refreshToken, err := base64.StdEncoding.DecodeString(dto.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("decode refresh token: %w", err)
+	}
+	expiry, err := accessTokenExpiry(dto.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AccessToken:  dto.AccessToken,
+		RefreshToken: refreshToken,
+		Expiry:       expiry,
+	}, nil
+}
+
+func accessTokenExpiry(accessToken string) (time.Time, error) {
+	var claims jwt.RegisteredClaims
+	_, _, err := jwt.NewParser().ParseUnverified(accessToken, &claims)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse access token: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return time.Time{}, errors.New("access token has no exp claim")
+	}
+	return claims.ExpiresAt.Time, nil
+}
+
+// Client calls the auth service HTTP API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates client for the auth service at baseURL. If httpClient is
+// nil, http.DefaultClient is used.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// SessionOptions are optional parameters of a new session.
+type SessionOptions struct {
+	// AMR are the methods the user authenticated with, RFC 8176 values like
+	// "pwd".
+	AMR []string
+	// RememberMe selects the remember me lifetime profile.
+	RememberMe bool
+	// LifetimeProfile is the lifetime profile of the tokens, the client
+	// default if empty.
+	LifetimeProfile string
+	// StepUpTicket is the ticket returned by the step-up of the user for
+	// this login.
+	StepUpTicket uuid.UUID
+}
+
+// CreateSession calls POST /sessions.
+func (c *Client) CreateSession(
+	ctx context.Context, userID uuid.UUID, clientID string, opts SessionOptions,
+) (*Session, error) {
+
+	query := url.Values{"userID": {userID.String()}}
+	if clientID != "" {
+		query.Set("clientID", clientID)
+	}
+	for _, amr := range opts.AMR {
+		query.Add("amr", amr)
+	}
+	if opts.RememberMe {
+		query.Set("rememberMe", "true")
+	}
+	if opts.LifetimeProfile != "" {
+		query.Set("lifetimeProfile", opts.LifetimeProfile)
+	}
+	if opts.StepUpTicket != uuid.Nil {
+		query.Set("stepUpTicket", opts.StepUpTicket.String())
+	}
+
+	var resp sessionDTO
+	err := c.do(ctx, http.MethodPost, "/sessions?"+query.Encode(), "", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+	return resp.toSession()
+}
+
+// RefreshSession calls POST /sessions/refresh. The given session must not
+// be used after the call, even if it fails with ErrUnauthorized. If it fails
+// with ErrChallengeRequired, the session is still valid for
+// CompleteRefreshChallenge.
+func (c *Client) RefreshSession(ctx context.Context, session *Session) (*Session, error) {
+	body, err := json.Marshal(sessionDTO{
+		AccessToken:  session.AccessToken,
+		RefreshToken: base64.StdEncoding.EncodeToString(session.RefreshToken),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal session: %w", err)
+	}
+
+	var resp sessionDTO
+	err = c.do(ctx, http.MethodPost, "/sessions/refresh", "application/json", body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("refresh session: %w", err)
+	}
+	return resp.toSession()
+}
+
+// CompleteRefreshChallenge calls POST /sessions/refresh/challenge with the
+// session the refresh failed with ErrChallengeRequired for.
+func (c *Client) CompleteRefreshChallenge(
+	ctx context.Context, session *Session, challengeID, code string,
+) (*Session, error) {
+
+	body, err := json.Marshal(map[string]string{
+		"accessToken":  session.AccessToken,
+		"refreshToken": base64.StdEncoding.EncodeToString(session.RefreshToken),
+		"challengeId":  challengeID,
+		"code":         code,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal challenge: %w", err)
+	}
+
+	var resp sessionDTO
+	err = c.do(ctx, http.MethodPost, "/sessions/refresh/challenge", "application/json", body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("complete refresh challenge: %w", err)
+	}
+	return resp.toSession()
+}
+
+// RevokeSession calls POST /sessions/revoke.
+func (c *Client) RevokeSession(ctx context.Context, accessToken string) error {
+	body, err := json.Marshal(map[string]string{"accessToken": accessToken})
+	if err != nil {
+		return fmt.Errorf("marshal access token: %w", err)
+	}
+	err = c.do(ctx, http.MethodPost, "/sessions/revoke", "application/json", body, nil)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAccessToken calls POST /tokens/revoke.
+func (c *Client) RevokeAccessToken(ctx context.Context, accessToken string) error {
+	body := []byte(url.Values{"token": {accessToken}}.Encode())
+	err := c.do(ctx, http.MethodPost, "/tokens/revoke",
+		"application/x-www-form-urlencoded", body, nil)
+	if err != nil {
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(
+	ctx context.Context, method, path, contentType string, body []byte, out any,
+) error {
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
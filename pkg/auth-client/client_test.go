@@ -0,0 +1,48 @@
+package authclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSessionOptions(t *testing.T) {
+	userID := uuid.New()
+	stepUpTicket := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sessions", r.URL.Path)
+		query := r.URL.Query()
+		assert.Equal(t, userID.String(), query.Get("userID"))
+		assert.Equal(t, "cli", query.Get("clientID"))
+		assert.Equal(t, []string{"pwd", "otp"}, query["amr"])
+		assert.Equal(t, "true", query.Get("rememberMe"))
+		assert.Equal(t, "long", query.Get("lifetimeProfile"))
+		assert.Equal(t, stepUpTicket.String(), query.Get("stepUpTicket"))
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sessionDTO{
+			AccessToken:  newAccessToken(t, time.Now().Add(time.Hour)),
+			RefreshToken: base64.StdEncoding.EncodeToString([]byte("refresh-0")),
+		})
+	}))
+	defer server.Close()
+
+	session, err := NewClient(server.URL, nil).CreateSession(context.Background(), userID, "cli",
+		SessionOptions{
+			AMR:             []string{"pwd", "otp"},
+			RememberMe:      true,
+			LifetimeProfile: "long",
+			StepUpTicket:    stepUpTicket,
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("refresh-0"), session.RefreshToken)
+}
@@ -0,0 +1,191 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultRefreshBefore = time.Minute
+	// refreshTimeout bounds a refresh, since it doesn't end when the caller
+	// which started it gives up.
+	refreshTimeout = time.Minute
+)
+
+// ErrNoSession is returned by Store.Load when there is no saved session.
+var ErrNoSession = errors.New("no session")
+
+// Store persists the latest session. Because refresh tokens are single-use,
+// a session must be saved before it is handed out, otherwise a restart
+// loses it.
+type Store interface {
+	Load(ctx context.Context) (*Session, error)
+	Save(ctx context.Context, session *Session) error
+}
+
+// MemoryStore keeps the session in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	session *Session
+}
+
+func NewMemoryStore(session *Session) *MemoryStore {
+	return &MemoryStore{session: session}
+}
+
+func (s *MemoryStore) Load(context.Context) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == nil {
+		return nil, ErrNoSession
+	}
+	return s.session, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = session
+	return nil
+}
+
+// TokenSource is an oauth2.TokenSource returning access tokens of a
+// session and refreshing the session refreshBefore its access token
+// expires. Concurrent refreshes are deduplicated.
+type TokenSource struct {
+	ctx           context.Context
+	client        *Client
+	store         Store
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	session *Session
+}
+
+var _ oauth2.TokenSource = &TokenSource{}
+
+type TokenSourceOption func(*TokenSource)
+
+// WithRefreshBefore sets how long before access token expiration the
+// session is refreshed. Defaults to one minute.
+func WithRefreshBefore(refreshBefore time.Duration) TokenSourceOption {
+	return func(ts *TokenSource) {
+		ts.refreshBefore = refreshBefore
+	}
+}
+
+// NewTokenSource creates token source of the session saved in store. ctx is
+// used for refresh and store calls made by Token.
+func (c *Client) NewTokenSource(ctx context.Context, store Store, opts ...TokenSourceOption) *TokenSource {
+	ts := &TokenSource{
+		ctx:           ctx,
+		client:        c,
+		store:         store,
+		refreshBefore: defaultRefreshBefore,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	return ts
+}
+
+// Token returns a valid access token, refreshing the session if needed.
+// If the refresh fails but the current access token has not expired yet,
+// the current token is returned. Errors matching ErrUnauthorized mean
+// the session has ended and the user must sign in again.
+func (ts *TokenSource) Token() (*oauth2.Token, error) {
+	return ts.TokenContext(ts.ctx)
+}
+
+// TokenContext is Token with explicit context.
+func (ts *TokenSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	session, err := ts.currentSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ts.now().Before(session.Expiry.Add(-ts.refreshBefore)) {
+		return newOAuth2Token(session), nil
+	}
+
+	// The refresh is shared by concurrent callers, so it must not be
+	// canceled with the context of the one which started it. A canceled
+	// refresh could also lose the single-use refresh token issued for it.
+	results := ts.group.DoChan("refresh", func() (any, error) {
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+		return ts.refresh(refreshCtx)
+	})
+	var result any
+	select {
+	case res := <-results:
+		result, err = res.Val, res.Err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		if !errors.Is(err, ErrUnauthorized) && ts.now().Before(session.Expiry) {
+			return newOAuth2Token(session), nil
+		}
+		return nil, err
+	}
+	return newOAuth2Token(result.(*Session)), nil
+}
+
+func (ts *TokenSource) currentSession(ctx context.Context) (*Session, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.session == nil {
+		session, err := ts.store.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load session: %w", err)
+		}
+		ts.session = session
+	}
+	return ts.session, nil
+}
+
+func (ts *TokenSource) refresh(ctx context.Context) (*Session, error) {
+	// Another process sharing the store might have refreshed the session.
+	session, err := ts.store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+	if ts.now().Before(session.Expiry.Add(-ts.refreshBefore)) {
+		ts.setSession(session)
+		return session, nil
+	}
+
+	newSession, err := ts.client.RefreshSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.store.Save(ctx, newSession); err != nil {
+		return nil, fmt.Errorf("save session: %w", err)
+	}
+	ts.setSession(newSession)
+	return newSession, nil
+}
+
+func (ts *TokenSource) setSession(session *Session) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.session = session
+}
+
+func newOAuth2Token(session *Session) *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken: session.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      session.Expiry,
+	}
+}
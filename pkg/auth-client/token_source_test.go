@@ -0,0 +1,183 @@
+package authclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessToken(t *testing.T, exp time.Time) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+		"exp": exp.Unix(),
+	}).SignedString([]byte("key"))
+	require.NoError(t, err)
+	return signed
+}
+
+// newServer emulates POST /sessions/refresh issuing access tokens valid for
+// accessTokenDuration and accepting each refresh token once.
+func newServer(t *testing.T, accessTokenDuration time.Duration) (*httptest.Server, *atomic.Int32) {
+	var refreshes atomic.Int32
+	var mu sync.Mutex
+	validRefreshToken := "refresh-0"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/sessions/refresh", r.URL.Path)
+		var body sessionDTO
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		refreshToken, err := base64.StdEncoding.DecodeString(body.RefreshToken)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if string(refreshToken) != validRefreshToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid refresh token"}`))
+			return
+		}
+		n := refreshes.Add(1)
+		validRefreshToken = fmt.Sprintf("refresh-%d", n)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sessionDTO{
+			AccessToken:  newAccessToken(t, time.Now().Add(accessTokenDuration)),
+			RefreshToken: base64.StdEncoding.EncodeToString([]byte(validRefreshToken)),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &refreshes
+}
+
+func TestTokenSourceReturnsValidToken(t *testing.T) {
+	server, refreshes := newServer(t, time.Hour)
+	accessToken := newAccessToken(t, time.Now().Add(time.Hour))
+	store := NewMemoryStore(&Session{
+		AccessToken:  accessToken,
+		RefreshToken: []byte("refresh-0"),
+		Expiry:       time.Now().Add(time.Hour),
+	})
+
+	token, err := NewClient(server.URL, nil).NewTokenSource(context.Background(), store).Token()
+
+	require.NoError(t, err)
+	assert.Equal(t, accessToken, token.AccessToken)
+	assert.Equal(t, int32(0), refreshes.Load())
+}
+
+func TestTokenSourceRefreshesBeforeExpiry(t *testing.T) {
+	server, refreshes := newServer(t, time.Hour)
+	accessToken := newAccessToken(t, time.Now().Add(time.Second*30))
+	store := NewMemoryStore(&Session{
+		AccessToken:  accessToken,
+		RefreshToken: []byte("refresh-0"),
+		Expiry:       time.Now().Add(time.Second * 30),
+	})
+	ts := NewClient(server.URL, nil).NewTokenSource(context.Background(), store)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ts.Token()
+			assert.NoError(t, err)
+			tokens[i] = token.AccessToken
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), refreshes.Load())
+	saved, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("refresh-1"), saved.RefreshToken)
+	assert.True(t, saved.Expiry.After(time.Now().Add(time.Minute*59)))
+	for _, token := range tokens {
+		assert.Equal(t, saved.AccessToken, token)
+	}
+}
+
+func TestTokenSourceRefreshOutlivesCanceledCaller(t *testing.T) {
+	server, refreshes := newServer(t, time.Hour)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		handler.ServeHTTP(w, r)
+	})
+	store := NewMemoryStore(&Session{
+		AccessToken:  newAccessToken(t, time.Now().Add(-time.Second)),
+		RefreshToken: []byte("refresh-0"),
+		Expiry:       time.Now().Add(-time.Second),
+	})
+	ts := NewClient(server.URL, nil).NewTokenSource(context.Background(), store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := ts.TokenContext(ctx)
+		canceled <- err
+	}()
+	<-started
+	waiting := make(chan error)
+	go func() {
+		_, err := ts.Token()
+		waiting <- err
+	}()
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+	close(release)
+
+	assert.NoError(t, <-waiting)
+	assert.Equal(t, int32(1), refreshes.Load())
+	saved, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("refresh-1"), saved.RefreshToken)
+}
+
+func TestTokenSourceUnauthorized(t *testing.T) {
+	server, _ := newServer(t, time.Hour)
+	store := NewMemoryStore(&Session{
+		AccessToken:  newAccessToken(t, time.Now().Add(time.Second*30)),
+		RefreshToken: []byte("used"),
+		Expiry:       time.Now().Add(time.Second * 30),
+	})
+
+	_, err := NewClient(server.URL, nil).NewTokenSource(context.Background(), store).Token()
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "invalid refresh token", apiErr.Message)
+}
+
+func TestTokenSourceFallsBackToCurrentToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	accessToken := newAccessToken(t, time.Now().Add(time.Second*30))
+	store := NewMemoryStore(&Session{
+		AccessToken:  accessToken,
+		RefreshToken: []byte("refresh-0"),
+		Expiry:       time.Now().Add(time.Second * 30),
+	})
+
+	token, err := NewClient(server.URL, nil).NewTokenSource(context.Background(), store).Token()
+
+	require.NoError(t, err)
+	assert.Equal(t, accessToken, token.AccessToken)
+}
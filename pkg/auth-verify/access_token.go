@@ -0,0 +1,194 @@
+// Package authverify verifies access tokens issued by the auth service in
+// downstream services.
+package authverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	UserIDJWTClaimName         = "sub"
+	UserIPJWTClaimName         = "sub_ip"
+	RefreshTokenIDJWTClaimName = "refresh_token_id"
+	ExpirationTimeJWTClaimName = "exp"
+	IssuedAtJWTClaimName       = "iat"
+	TokenIDJWTClaimName        = "jti"
+	SessionIDJWTClaimName      = "sid"
+	AuthTimeJWTClaimName       = "auth_time"
+	ACRJWTClaimName            = "acr"
+	AMRJWTClaimName            = "amr"
+)
+
+// ErrReauthenticationRequired is returned by RequireRecentAuthentication.
+// Services respond with 401 and the RFC 9470 "insufficient_user_authentication"
+// error, so the client makes the user authenticate again.
+var ErrReauthenticationRequired = errors.New("reauthentication required")
+
+// AccessToken is a verified access token.
+type AccessToken struct {
+	// ID is uuid.Nil for tokens issued before "jti" claim was introduced.
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	RefreshTokenID uuid.UUID
+	// SessionID stays the same through refreshes, unlike RefreshTokenID. It
+	// is RefreshTokenID for tokens issued before "sid" claim was introduced.
+	SessionID uuid.UUID
+	UserIP    string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// AuthTime is when the user authenticated, zero if it is unknown.
+	AuthTime time.Time
+	// ACR is "aal2" for sessions stepped up with a second factor.
+	ACR string
+	// AMR lists RFC 8176 methods the user authenticated with.
+	AMR []string
+	// Raw is the signed token as it was received.
+	Raw string
+}
+
+// RequireRecentAuthentication returns ErrReauthenticationRequired if the
+// user authenticated more than maxAge ago or the authentication time of the
+// token is unknown.
+func (t *AccessToken) RequireRecentAuthentication(maxAge time.Duration) error {
+	if t.AuthTime.IsZero() || time.Since(t.AuthTime) > maxAge {
+		return fmt.Errorf("%w: authenticated at %s, max age %s",
+			ErrReauthenticationRequired, t.AuthTime.Format(time.RFC3339), maxAge)
+	}
+	return nil
+}
+
+type accessTokenContextKey struct{}
+
+// ContextWithAccessToken returns a copy of ctx carrying the access token.
+func ContextWithAccessToken(ctx context.Context, accessToken *AccessToken) context.Context {
+	return context.WithValue(ctx, accessTokenContextKey{}, accessToken)
+}
+
+// AccessTokenFromContext returns the access token put into the context by
+// the middlewares.
+func AccessTokenFromContext(ctx context.Context) (*AccessToken, bool) {
+	accessToken, ok := ctx.Value(accessTokenContextKey{}).(*AccessToken)
+	return accessToken, ok
+}
+
+func parseAccessTokenClaims(claims jwt.MapClaims) (*AccessToken, error) {
+	var accessToken AccessToken
+	var err error
+
+	accessToken.UserID, err = getUUIDClaim(claims, UserIDJWTClaimName)
+	if err != nil {
+		return nil, err
+	}
+	accessToken.RefreshTokenID, err = getUUIDClaim(claims, RefreshTokenIDJWTClaimName)
+	if err != nil {
+		return nil, err
+	}
+	accessToken.SessionID = accessToken.RefreshTokenID
+	if _, ok := claims[SessionIDJWTClaimName]; ok {
+		accessToken.SessionID, err = getUUIDClaim(claims, SessionIDJWTClaimName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	accessToken.UserIP, err = getStringClaim(claims, UserIPJWTClaimName)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, fmt.Errorf("claim %s missing", ExpirationTimeJWTClaimName)
+	}
+	accessToken.ExpiresAt = expiresAt.Time
+
+	if _, ok := claims[TokenIDJWTClaimName]; ok {
+		accessToken.ID, err = getUUIDClaim(claims, TokenIDJWTClaimName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil {
+		return nil, fmt.Errorf("parse %s claim: %w", IssuedAtJWTClaimName, err)
+	}
+	if issuedAt != nil {
+		accessToken.IssuedAt = issuedAt.Time
+	}
+
+	if _, ok := claims[AuthTimeJWTClaimName]; ok {
+		accessToken.AuthTime, err = getTimeClaim(claims, AuthTimeJWTClaimName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := claims[ACRJWTClaimName]; ok {
+		accessToken.ACR, err = getStringClaim(claims, ACRJWTClaimName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := claims[AMRJWTClaimName]; ok {
+		accessToken.AMR, err = getStringsClaim(claims, AMRJWTClaimName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &accessToken, nil
+}
+
+func getStringClaim(claims jwt.MapClaims, claimName string) (string, error) {
+	claimAny, ok := claims[claimName]
+	if !ok {
+		return "", fmt.Errorf("claim %s missing", claimName)
+	}
+	claim, ok := claimAny.(string)
+	if !ok {
+		return "", fmt.Errorf("claim %s is not of type string", claimName)
+	}
+	return claim, nil
+}
+
+func getTimeClaim(claims jwt.MapClaims, claimName string) (time.Time, error) {
+	claim, ok := claims[claimName].(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("claim %s is not of type number", claimName)
+	}
+	// "iat" has a fractional part with microsecond precision
+	sec, frac := math.Modf(claim)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), nil
+}
+
+func getStringsClaim(claims jwt.MapClaims, claimName string) ([]string, error) {
+	claimAny, ok := claims[claimName].([]any)
+	if !ok {
+		return nil, fmt.Errorf("claim %s is not an array", claimName)
+	}
+	values := make([]string, 0, len(claimAny))
+	for _, valueAny := range claimAny {
+		value, ok := valueAny.(string)
+		if !ok {
+			return nil, fmt.Errorf("claim %s has non string element", claimName)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func getUUIDClaim(claims jwt.MapClaims, claimName string) (uuid.UUID, error) {
+	claim, err := getStringClaim(claims, claimName)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	id, err := uuid.Parse(claim)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("parse %s claim: %w", claimName, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,41 @@
+// Package ginverify adapts authverify to gin.
+package ginverify
+
+import (
+	authverify "auth/pkg/auth-verify"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const accessTokenKey = "authverify.accessToken"
+
+// Middleware aborts requests without a valid access token and stores the
+// verified token both in gin context and in the request context.
+func Middleware(verifier *authverify.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken, err := verifier.VerifyRequest(c.Request)
+		if err != nil {
+			status := authverify.StatusForError(err)
+			if status == http.StatusUnauthorized {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": http.StatusText(status)})
+			return
+		}
+		c.Set(accessTokenKey, accessToken)
+		c.Request = c.Request.WithContext(
+			authverify.ContextWithAccessToken(c.Request.Context(), accessToken))
+		c.Next()
+	}
+}
+
+// AccessToken returns the token stored by Middleware.
+func AccessToken(c *gin.Context) (*authverify.AccessToken, bool) {
+	accessToken, ok := c.Get(accessTokenKey)
+	if !ok {
+		return nil, false
+	}
+	typed, ok := accessToken.(*authverify.AccessToken)
+	return typed, ok
+}
@@ -0,0 +1,270 @@
+package authverify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSRefreshInterval    = 10 * time.Minute
+	defaultJWKSMinRefreshInterval = time.Minute
+	jwksFetchTimeout              = 30 * time.Second
+)
+
+// JWKS fetches a JSON Web Key Set from URL and caches it. The set is
+// refetched every refresh interval and when a token references unknown key
+// ID, but not more often than min refresh interval. Concurrent refreshes are
+// deduplicated and cached keys are served while the set is being refetched.
+type JWKS struct {
+	url                string
+	httpClient         *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	group       singleflight.Group
+	mu          sync.Mutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	refreshing  bool
+}
+
+type JWKSOption func(*JWKS)
+
+func WithJWKSHTTPClient(httpClient *http.Client) JWKSOption {
+	return func(j *JWKS) {
+		j.httpClient = httpClient
+	}
+}
+
+func WithJWKSRefreshInterval(refreshInterval time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.refreshInterval = refreshInterval
+	}
+}
+
+func WithJWKSMinRefreshInterval(minRefreshInterval time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.minRefreshInterval = minRefreshInterval
+	}
+}
+
+func NewJWKS(url string, opts ...JWKSOption) *JWKS {
+	j := &JWKS{
+		url:                url,
+		httpClient:         http.DefaultClient,
+		refreshInterval:    defaultJWKSRefreshInterval,
+		minRefreshInterval: defaultJWKSMinRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Key implements KeySource.
+func (j *JWKS) Key(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	j.mu.Lock()
+	now := time.Now()
+	stale := j.keys == nil || now.Sub(j.fetchedAt) >= j.refreshInterval
+	key, found := j.lookup(kid)
+	// Lookups of unknown keys join the refresh in progress.
+	refresh := (stale || !found) &&
+		(j.refreshing || now.Sub(j.lastAttempt) >= j.minRefreshInterval)
+	if refresh && !j.refreshing {
+		j.refreshing = true
+		j.lastAttempt = now
+	}
+	j.mu.Unlock()
+
+	if !refresh {
+		if !found {
+			return nil, fmt.Errorf("no key with id %q in key set", kid)
+		}
+		return key, nil
+	}
+
+	// The fetch is shared by concurrent lookups, so it doesn't depend on
+	// cancellation of the lookup which started it.
+	result := j.group.DoChan("refresh", func() (any, error) {
+		return nil, j.refresh(context.WithoutCancel(ctx))
+	})
+	if found {
+		return key, nil
+	}
+	var err error
+	select {
+	case res := <-result:
+		err = res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil && j.keys == nil {
+		return nil, err
+	}
+	key, found = j.lookup(kid)
+	if !found {
+		return nil, fmt.Errorf("no key with id %q in key set", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set and replaces the cached keys. Cached keys are
+// kept if the endpoint is unavailable.
+func (j *JWKS) refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.refreshing = false
+	if err != nil {
+		return err
+	}
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	return nil
+}
+
+func (j *JWKS) lookup(kid string) (any, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create jwks request: %w", err)
+	}
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, raw := range set.Keys {
+		var jwk jsonWebKey
+		if err := json.Unmarshal(raw, &jwk); err != nil {
+			return nil, fmt.Errorf("decode jwk: %w", err)
+		}
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if errors.Is(err, errUnsupportedKeyType) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse jwk %q: %w", jwk.KeyID, err)
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+var errUnsupportedKeyType = errors.New("unsupported key type")
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errUnsupportedKeyType
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, errUnsupportedKeyType
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errUnsupportedKeyType
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
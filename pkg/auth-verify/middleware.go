@@ -0,0 +1,57 @@
+package authverify
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrMissingToken is returned when the request has no bearer token.
+var ErrMissingToken = errors.New("missing bearer token")
+
+// TokenFromRequest extracts bearer token from Authorization header.
+func TokenFromRequest(r *http.Request) (string, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", ErrMissingToken
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// VerifyRequest verifies the bearer token of the request.
+func (v *Verifier) VerifyRequest(r *http.Request) (*AccessToken, error) {
+	signedToken, err := TokenFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(r.Context(), signedToken)
+}
+
+// Middleware rejects requests without a valid access token with 401 and
+// puts the verified token into the request context. Revocation check
+// failures are reported with 503.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessToken, err := v.VerifyRequest(r)
+		if err != nil {
+			status := StatusForError(err)
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithAccessToken(r.Context(), accessToken)))
+	})
+}
+
+// StatusForError maps verification error to HTTP status.
+func StatusForError(err error) int {
+	if errors.Is(err, ErrMissingToken) ||
+		errors.Is(err, ErrInvalidToken) ||
+		errors.Is(err, ErrRevokedToken) ||
+		errors.Is(err, ErrReauthenticationRequired) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusServiceUnavailable
+}
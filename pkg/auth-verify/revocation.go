@@ -0,0 +1,323 @@
+package authverify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	revocationKindAccessToken = "jti"
+	revocationKindSession     = "sid"
+	revocationKindUser        = "user"
+
+	defaultFeedReconnectDelay = time.Second * 5
+)
+
+// IntrospectionChecker checks revocation by calling the auth service token
+// introspection endpoint for every token. It always sees the latest state but
+// costs a round trip per request.
+type IntrospectionChecker struct {
+	introspectionURL string
+	httpClient       *http.Client
+}
+
+// NewIntrospectionChecker creates checker calling POST {baseURL}/tokens/introspect.
+func NewIntrospectionChecker(baseURL string, httpClient *http.Client) *IntrospectionChecker {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &IntrospectionChecker{
+		introspectionURL: strings.TrimSuffix(baseURL, "/") + "/tokens/introspect",
+		httpClient:       httpClient,
+	}
+}
+
+func (c *IntrospectionChecker) IsRevoked(ctx context.Context, accessToken *AccessToken) (bool, error) {
+	form := url.Values{"token": {accessToken.Raw}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.introspectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("introspect token: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Active bool `json:"active"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("decode introspection response: %w", err)
+	}
+	return !body.Active, nil
+}
+
+type revocation struct {
+	Seq       int64     `json:"seq"`
+	Kind      string    `json:"kind"`
+	ID        string    `json:"id"`
+	RevokedAt time.Time `json:"revokedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// userWatermark revokes access tokens of the user issued before notBefore.
+type userWatermark struct {
+	notBefore time.Time
+	expiresAt time.Time
+}
+
+// RevocationFeed keeps an in-memory copy of revoked sessions and access
+// tokens and of users' tokens watermarks, which revoke all access tokens of
+// a user issued before them, e.g. on password change. It loads GET {baseURL}/revocations snapshot and then follows
+// GET {baseURL}/revocations/stream, reconnecting from the last received
+// revocation. Until the snapshot is loaded IsRevoked returns an error.
+type RevocationFeed struct {
+	baseURL        string
+	httpClient     *http.Client
+	reconnectDelay time.Duration
+	onError        func(error)
+
+	mu          sync.RWMutex
+	synced      bool
+	cursor      int64
+	sessions    map[uuid.UUID]time.Time
+	tokens      map[uuid.UUID]time.Time
+	users       map[uuid.UUID]userWatermark
+	lastCleanup time.Time
+}
+
+type RevocationFeedOption func(*RevocationFeed)
+
+// WithFeedHTTPClient sets the client used for the feed requests. It must not
+// have a timeout because the stream is a long-lived response.
+func WithFeedHTTPClient(httpClient *http.Client) RevocationFeedOption {
+	return func(f *RevocationFeed) {
+		f.httpClient = httpClient
+	}
+}
+
+func WithFeedReconnectDelay(reconnectDelay time.Duration) RevocationFeedOption {
+	return func(f *RevocationFeed) {
+		f.reconnectDelay = reconnectDelay
+	}
+}
+
+// WithFeedErrorHandler sets the callback receiving snapshot and stream
+// errors, e.g. for logging. The feed retries on its own.
+func WithFeedErrorHandler(onError func(error)) RevocationFeedOption {
+	return func(f *RevocationFeed) {
+		f.onError = onError
+	}
+}
+
+func NewRevocationFeed(baseURL string, opts ...RevocationFeedOption) *RevocationFeed {
+	f := &RevocationFeed{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		httpClient:     http.DefaultClient,
+		reconnectDelay: defaultFeedReconnectDelay,
+		onError:        func(error) {},
+		sessions:       make(map[uuid.UUID]time.Time),
+		tokens:         make(map[uuid.UUID]time.Time),
+		users:          make(map[uuid.UUID]userWatermark),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Run follows the feed until ctx is done.
+func (f *RevocationFeed) Run(ctx context.Context) {
+	for {
+		err := f.sync(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			f.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(f.reconnectDelay):
+		}
+	}
+}
+
+// Synced reports whether the snapshot has been loaded.
+func (f *RevocationFeed) Synced() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.synced
+}
+
+func (f *RevocationFeed) IsRevoked(_ context.Context, accessToken *AccessToken) (bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if !f.synced {
+		return false, fmt.Errorf("revocation feed is not synced")
+	}
+	if _, ok := f.sessions[accessToken.SessionID]; ok {
+		return true, nil
+	}
+	if watermark, ok := f.users[accessToken.UserID]; ok &&
+		accessToken.IssuedAt.Before(watermark.notBefore) {
+		return true, nil
+	}
+	if accessToken.ID != uuid.Nil {
+		if _, ok := f.tokens[accessToken.ID]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *RevocationFeed) sync(ctx context.Context) error {
+	if !f.Synced() {
+		if err := f.loadSnapshot(ctx); err != nil {
+			return err
+		}
+	}
+	return f.stream(ctx)
+}
+
+func (f *RevocationFeed) loadSnapshot(ctx context.Context) error {
+	resp, err := f.get(ctx, f.baseURL+"/revocations")
+	if err != nil {
+		return fmt.Errorf("get revocations snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var snapshot struct {
+		Cursor      int64        `json:"cursor"`
+		Revocations []revocation `json:"revocations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return fmt.Errorf("decode revocations snapshot: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range snapshot.Revocations {
+		f.add(r)
+	}
+	f.cursor = snapshot.Cursor
+	f.synced = true
+	return nil
+}
+
+func (f *RevocationFeed) stream(ctx context.Context) error {
+	f.mu.RLock()
+	cursor := f.cursor
+	f.mu.RUnlock()
+
+	resp, err := f.get(ctx, f.baseURL+"/revocations/stream?cursor="+strconv.FormatInt(cursor, 10))
+	if err != nil {
+		return fmt.Errorf("open revocations stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var event, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == "revocation" && data != "" {
+				var r revocation
+				if err := json.Unmarshal([]byte(data), &r); err != nil {
+					return fmt.Errorf("decode revocation: %w", err)
+				}
+				f.mu.Lock()
+				f.add(r)
+				f.cursor = r.Seq
+				f.mu.Unlock()
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read revocations stream: %w", err)
+	}
+	return fmt.Errorf("revocations stream closed")
+}
+
+func (f *RevocationFeed) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// add must be called with f.mu held for writing.
+func (f *RevocationFeed) add(r revocation) {
+	id, err := uuid.Parse(r.ID)
+	if err != nil {
+		f.onError(fmt.Errorf("parse revocation %d id: %w", r.Seq, err))
+		return
+	}
+	switch r.Kind {
+	case revocationKindSession:
+		f.sessions[id] = r.ExpiresAt
+	case revocationKindAccessToken:
+		f.tokens[id] = r.ExpiresAt
+	case revocationKindUser:
+		// watermarks are only raised, a stale snapshot entry must not
+		// lower the one received from the stream
+		if watermark, ok := f.users[id]; !ok || r.RevokedAt.After(watermark.notBefore) {
+			f.users[id] = userWatermark{notBefore: r.RevokedAt, expiresAt: r.ExpiresAt}
+		}
+	}
+
+	now := time.Now()
+	if now.Sub(f.lastCleanup) >= time.Minute {
+		f.lastCleanup = now
+		for id, expiresAt := range f.sessions {
+			if expiresAt.Before(now) {
+				delete(f.sessions, id)
+			}
+		}
+		for id, expiresAt := range f.tokens {
+			if expiresAt.Before(now) {
+				delete(f.tokens, id)
+			}
+		}
+		for id, watermark := range f.users {
+			if watermark.expiresAt.Before(now) {
+				delete(f.users, id)
+			}
+		}
+	}
+}
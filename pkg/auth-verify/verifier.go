@@ -0,0 +1,138 @@
+package authverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is malformed, has an invalid
+	// signature or is expired.
+	ErrInvalidToken = errors.New("invalid access token")
+	// ErrRevokedToken is returned when the token or its session was revoked.
+	ErrRevokedToken = errors.New("access token revoked")
+)
+
+// KeySource resolves the key that verifies token signature.
+type KeySource interface {
+	Key(ctx context.Context, token *jwt.Token) (any, error)
+}
+
+// RevocationChecker reports whether an already verified access token was
+// revoked.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, accessToken *AccessToken) (bool, error)
+}
+
+// Verifier verifies signed access tokens.
+type Verifier struct {
+	keySource         KeySource
+	validMethods      []string
+	revocationChecker RevocationChecker
+	leeway            time.Duration
+}
+
+type Option func(*Verifier)
+
+// WithRevocationChecker makes verifier reject revoked tokens.
+func WithRevocationChecker(revocationChecker RevocationChecker) Option {
+	return func(v *Verifier) {
+		v.revocationChecker = revocationChecker
+	}
+}
+
+// WithLeeway allows clock skew when validating time based claims.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// WithValidMethods restricts accepted signing algorithms. By default only
+// HS512 is accepted for static keys and RS256, RS512, ES256, ES512, EdDSA
+// for JWKS.
+func WithValidMethods(methods ...string) Option {
+	return func(v *Verifier) {
+		v.validMethods = methods
+	}
+}
+
+// NewStaticKeyVerifier creates verifier for tokens signed with a single key,
+// e.g. the HMAC secret shared with the auth service.
+func NewStaticKeyVerifier(key any, opts ...Option) *Verifier {
+	return newVerifier(staticKey{key: key}, []string{jwt.SigningMethodHS512.Alg()}, opts)
+}
+
+// NewJWKSVerifier creates verifier for tokens signed with keys published as
+// a JSON Web Key Set.
+func NewJWKSVerifier(jwks *JWKS, opts ...Option) *Verifier {
+	return newVerifier(jwks, []string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodRS512.Alg(),
+		jwt.SigningMethodES256.Alg(),
+		jwt.SigningMethodES512.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}, opts)
+}
+
+func newVerifier(keySource KeySource, validMethods []string, opts []Option) *Verifier {
+	v := &Verifier{
+		keySource:    keySource,
+		validMethods: validMethods,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify checks token signature, expiration and, if configured, revocation.
+// Errors wrap ErrInvalidToken or ErrRevokedToken when the token is rejected.
+func (v *Verifier) Verify(ctx context.Context, signedToken string) (*AccessToken, error) {
+	token, err := jwt.Parse(
+		signedToken,
+		func(token *jwt.Token) (any, error) {
+			return v.keySource.Key(ctx, token)
+		},
+		jwt.WithValidMethods(v.validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected claims type", ErrInvalidToken)
+	}
+	accessToken, err := parseAccessTokenClaims(claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	accessToken.Raw = signedToken
+
+	if v.revocationChecker != nil {
+		revoked, err := v.revocationChecker.IsRevoked(ctx, accessToken)
+		if err != nil {
+			return nil, fmt.Errorf("check revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	return accessToken, nil
+}
+
+type staticKey struct {
+	key any
+}
+
+func (k staticKey) Key(context.Context, *jwt.Token) (any, error) {
+	return k.key, nil
+}
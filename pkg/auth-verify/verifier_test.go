@@ -0,0 +1,293 @@
+package authverify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	hmacKey        = []byte("key")
+	userID         = uuid.MustParse("6c1b0e8e-7d9f-4f0a-9a39-3a0f3f1c6a11")
+	refreshTokenID = uuid.MustParse("0e3b8f61-2f6c-4f67-8e2e-8b7d4f1d3a22")
+	accessTokenID  = uuid.MustParse("a4d0c9b3-5e1a-4f2b-9c8d-7e6f5a4b3c33")
+)
+
+func newClaims(exp time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		TokenIDJWTClaimName:        accessTokenID.String(),
+		UserIDJWTClaimName:         userID.String(),
+		UserIPJWTClaimName:         "1.1.1.1",
+		RefreshTokenIDJWTClaimName: refreshTokenID.String(),
+		IssuedAtJWTClaimName:       time.Now().Unix(),
+		ExpirationTimeJWTClaimName: exp.Unix(),
+	}
+}
+
+func signHMAC(t *testing.T, claims jwt.MapClaims) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(hmacKey)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestVerifyStaticKey(t *testing.T) {
+	verifier := NewStaticKeyVerifier(hmacKey)
+	signed := signHMAC(t, newClaims(time.Now().Add(time.Minute)))
+
+	accessToken, err := verifier.Verify(context.Background(), signed)
+
+	require.NoError(t, err)
+	assert.Equal(t, accessTokenID, accessToken.ID)
+	assert.Equal(t, userID, accessToken.UserID)
+	assert.Equal(t, refreshTokenID, accessToken.RefreshTokenID)
+	assert.Equal(t, "1.1.1.1", accessToken.UserIP)
+	assert.Equal(t, signed, accessToken.Raw)
+	// tokens without "sid" claim
+	assert.Equal(t, refreshTokenID, accessToken.SessionID)
+	assert.True(t, accessToken.AuthTime.IsZero())
+}
+
+func TestVerifyAuthContext(t *testing.T) {
+	verifier := NewStaticKeyVerifier(hmacKey)
+	sessionID := uuid.New()
+	authTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	claims := newClaims(time.Now().Add(time.Minute))
+	claims[SessionIDJWTClaimName] = sessionID.String()
+	claims[AuthTimeJWTClaimName] = authTime.Unix()
+	claims[ACRJWTClaimName] = "aal2"
+	claims[AMRJWTClaimName] = []string{"pwd", "otp", "mfa"}
+
+	accessToken, err := verifier.Verify(context.Background(), signHMAC(t, claims))
+
+	require.NoError(t, err)
+	assert.Equal(t, sessionID, accessToken.SessionID)
+	assert.Equal(t, authTime, accessToken.AuthTime)
+	assert.Equal(t, "aal2", accessToken.ACR)
+	assert.Equal(t, []string{"pwd", "otp", "mfa"}, accessToken.AMR)
+	assert.NoError(t, accessToken.RequireRecentAuthentication(2*time.Hour))
+	assert.ErrorIs(t, accessToken.RequireRecentAuthentication(time.Minute),
+		ErrReauthenticationRequired)
+	assert.ErrorIs(t, (&AccessToken{}).RequireRecentAuthentication(time.Hour),
+		ErrReauthenticationRequired, "unknown authentication time")
+}
+
+func TestVerifyStaticKeyInvalid(t *testing.T) {
+	verifier := NewStaticKeyVerifier(hmacKey)
+
+	tests := map[string]string{
+		"expired":   signHMAC(t, newClaims(time.Now().Add(-time.Minute))),
+		"other key": must(jwt.NewWithClaims(jwt.SigningMethodHS512, newClaims(time.Now().Add(time.Minute))).SignedString([]byte("other"))),
+		"other alg": must(jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(time.Now().Add(time.Minute))).SignedString(hmacKey)),
+		"malformed": "token",
+	}
+	for name, signed := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), signed)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	verifier := NewJWKSVerifier(NewJWKS(server.URL))
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, newClaims(time.Now().Add(time.Minute)))
+		token.Header["kid"] = kid
+		return must(token.SignedString(privateKey))
+	}
+
+	accessToken, err := verifier.Verify(context.Background(), sign("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, userID, accessToken.UserID)
+
+	_, err = verifier.Verify(context.Background(), sign("key-1"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "key set must be cached")
+
+	_, err = verifier.Verify(context.Background(), sign("key-2"))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, int32(1), fetches.Load(), "refetch on unknown key must be rate limited")
+}
+
+func TestVerifyJWKSServesCachedKeysDuringRefresh(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-unblock
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	verifier := NewJWKSVerifier(NewJWKS(server.URL,
+		WithJWKSRefreshInterval(time.Nanosecond), WithJWKSMinRefreshInterval(0)))
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, newClaims(time.Now().Add(time.Minute)))
+	token.Header["kid"] = "key-1"
+	signed := must(token.SignedString(privateKey))
+
+	_, err = verifier.Verify(context.Background(), signed)
+	require.NoError(t, err)
+
+	// the key set is stale, its refresh blocks but the cached key is served
+	_, err = verifier.Verify(context.Background(), signed)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return fetches.Load() == 2 },
+		time.Second, time.Millisecond)
+	for range 5 {
+		_, err = verifier.Verify(context.Background(), signed)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), fetches.Load(), "concurrent refreshes must be deduplicated")
+}
+
+func TestVerifyIntrospection(t *testing.T) {
+	active := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tokens/introspect", r.URL.Path)
+		assert.NotEmpty(t, r.PostFormValue("token"))
+		json.NewEncoder(w).Encode(map[string]bool{"active": active})
+	}))
+	defer server.Close()
+
+	verifier := NewStaticKeyVerifier(hmacKey,
+		WithRevocationChecker(NewIntrospectionChecker(server.URL, nil)))
+	signed := signHMAC(t, newClaims(time.Now().Add(time.Minute)))
+
+	_, err := verifier.Verify(context.Background(), signed)
+	require.NoError(t, err)
+
+	active = false
+	_, err = verifier.Verify(context.Background(), signed)
+	assert.ErrorIs(t, err, ErrRevokedToken)
+}
+
+func TestVerifyRevocationFeed(t *testing.T) {
+	otherSessionID := uuid.New()
+	otherUserID := uuid.New()
+	notBefore := time.Now().Add(-time.Minute)
+	expiresAt := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/revocations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"cursor":1,"revocations":[`+
+			`{"seq":0,"kind":"user","id":%q,"revokedAt":%q,"expiresAt":%q},`+
+			`{"seq":1,"kind":"sid","id":%q,"expiresAt":%q}]}`,
+			otherUserID, notBefore.UTC().Format(time.RFC3339Nano), expiresAt,
+			otherSessionID, expiresAt)
+	})
+	mux.HandleFunc("/revocations/stream", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("cursor"))
+		fmt.Fprintf(w, "id: 2\nevent: revocation\ndata: {\"seq\":2,\"kind\":\"jti\",\"id\":%q,\"expiresAt\":%q}\n\n",
+			accessTokenID, expiresAt)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	feed := NewRevocationFeed(server.URL)
+	verifier := NewStaticKeyVerifier(hmacKey, WithRevocationChecker(feed))
+	signed := signHMAC(t, newClaims(time.Now().Add(time.Minute)))
+
+	_, err := verifier.Verify(context.Background(), signed)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRevokedToken)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(context.Background(), signed)
+		return err == ErrRevokedToken
+	}, time.Second*5, time.Millisecond*10)
+
+	// access tokens issued before the last refresh of the session carry
+	// another refresh token ID
+	revoked, err := feed.IsRevoked(context.Background(),
+		&AccessToken{RefreshTokenID: uuid.New(), SessionID: otherSessionID})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// the user's tokens watermark revokes only tokens issued before it
+	revoked, err = feed.IsRevoked(context.Background(),
+		&AccessToken{UserID: otherUserID, IssuedAt: notBefore.Add(-time.Millisecond)})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = feed.IsRevoked(context.Background(),
+		&AccessToken{UserID: otherUserID, IssuedAt: notBefore.Add(time.Millisecond)})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestMiddleware(t *testing.T) {
+	verifier := NewStaticKeyVerifier(hmacKey)
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessToken, ok := AccessTokenFromContext(r.Context())
+		require.True(t, ok)
+		assert.Equal(t, userID, accessToken.UserID)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signHMAC(t, newClaims(time.Now().Add(time.Minute))))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}